@@ -45,6 +45,10 @@ type FlannelCniTestConfig struct {
   CniConf  FlannelConf     `json:"cniconf"`
 }
 
+type GenericCniTestConfig struct {
+  CniConf  map[string]interface{} `json:"cniconf"`
+}
+
 type FlannelConf struct {
   Name  string             `json:"name"`
   Type  string             `json:"type"`
@@ -76,6 +80,8 @@ func testSetup(args *skel.CmdArgs) error {
     err = validateMacvlanConfig(args.StdinData, expectedCniConf, tcConf)
   } else if tcConf.CniExpectations.CniType == "flannel" {
     err = validateFlannelConfig(args.StdinData, expectedCniConf)
+  } else {
+    err = validateGenericConfig(args.StdinData, expectedCniConf)
   }
   if err != nil {
     return err
@@ -169,6 +175,25 @@ func validateFlannelConfig(receivedCniConfig, expectedCniConfig []byte) error {
   return nil
 }
 
+func validateGenericConfig(receivedCniConfig, expectedCniConfig []byte) error {
+  var recConf map[string]interface{}
+  err := json.Unmarshal(receivedCniConfig, &recConf)
+  if err != nil {
+    return errors.New("Received CNI config could not be unmarshalled, because:" + err.Error())
+  }
+  log.Printf("Received CNI config:%v",recConf)
+  var expConf GenericCniTestConfig
+  err = json.Unmarshal(expectedCniConfig, &expConf)
+  if err != nil {
+    return errors.New("Expected CNI config could not be unmarshalled, because:" + err.Error())
+  }
+  log.Printf("Expected CNI config:%v",expConf.CniConf)
+  if !reflect.DeepEqual(recConf, expConf.CniConf) {
+    return errors.New("Received delegate configuration does not match with expected!")
+  }
+  return nil
+}
+
 func createCurrentCniResult(tcConf TestConfig) *current.Result {
   cniRes := current.Result {CNIVersion: "0.3.1"}
   if tcConf.CniExpectations.Ip != "" ||  tcConf.CniExpectations.Ip6 != "" {
@@ -3,9 +3,9 @@ package main
 import (
   "flag"
   "log"
-  "os"
   "github.com/containernetworking/cni/pkg/skel"
   "github.com/nokia/danm/pkg/datastructs"
+  "github.com/nokia/danm/pkg/logger"
   "github.com/nokia/danm/pkg/metacni"
 )
 
@@ -21,13 +21,10 @@ func main() {
     log.Println("DANM binary was built from commit: " + commitHash)
     return
   }
-  var err error
-  f, err := os.OpenFile("/var/log/danm.log", os.O_RDWR | os.O_CREATE | os.O_APPEND, 0640)
+  //The log file is reconfigured according to the logging section of the CNI config as soon as it is loaded
+  err := logger.Configure(logger.Config{File: metacni.DefaultLogFile})
   if err != nil {
     log.Println("ERROR: cannot create log file, because:" + err.Error())
   }
-  defer f.Close()
-  log.SetOutput(f)
-  log.SetFlags(log.LstdFlags | log.Lmicroseconds)
   skel.PluginMain(metacni.CreateInterfaces, metacni.GetInterfaces, metacni.DeleteInterfaces, datastructs.SupportedCniVersions, "")
 }
@@ -0,0 +1,679 @@
+package main
+
+import (
+  "encoding/json"
+  "errors"
+  "flag"
+  "fmt"
+  "io/ioutil"
+  "net"
+  "os"
+  "path/filepath"
+  "sort"
+  "strconv"
+  "strings"
+  "text/tabwriter"
+  "time"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  "k8s.io/apimachinery/pkg/util/yaml"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
+  danmfake "github.com/nokia/danm/crd/client/clientset/versioned/fake"
+  "github.com/nokia/danm/pkg/admit"
+  "github.com/nokia/danm/pkg/confman"
+  "github.com/nokia/danm/pkg/danmep"
+  "github.com/nokia/danm/pkg/datastructs"
+  "github.com/nokia/danm/pkg/ipam"
+  "github.com/nokia/danm/pkg/metacni"
+  "github.com/nokia/danm/pkg/netcontrol"
+)
+
+var(
+  version, commitHash string
+  kubeConfig string
+)
+
+type command struct {
+  description string
+  run func(args []string) error
+}
+
+var commands map[string]command
+
+//Populated in init, as the commands themselves look up their descriptions
+func init() {
+  commands = map[string]command {
+    "networks": {"lists all the networks with the usage of their IPv4, and IPv6 allocation pools", listNetworks},
+    "ip-owner": {"shows which DanmEp, and Pod an IP address is allocated to", showIpOwner},
+    "pod": {"shows the Pod, and interface belonging to a CNI EndpointID", showEndpoint},
+    "free-ips": {"lists the unallocated IP addresses of a network", listFreeIps},
+    "vnis": {"shows the VNI usage of the interfaces of the TenantConfigs", listVnis},
+    "validate": {"validates a network, or TenantConfig manifest offline, exactly like the webhook would", validateManifest},
+    "lint": {"validates all the DANM manifests of files, or directories offline, including the rules spanning multiple objects", lintManifests},
+    "repair": {"rebuilds the IP allocation bitmaps of networks from their live DanmEps", repairAllocations},
+    "version": {"prints Git version information of the binary", printVersion},
+  }
+}
+
+func main() {
+  flag.StringVar(&kubeConfig, "kubeconfig", getDefaultKubeConfig(), "path to the kubeconfig used to connect to the cluster")
+  flag.Usage = usage
+  flag.Parse()
+  if flag.NArg() < 1 {
+    usage()
+    os.Exit(2)
+  }
+  cmd, ok := commands[flag.Arg(0)]
+  if !ok {
+    fmt.Fprintln(os.Stderr, "unknown command:" + flag.Arg(0))
+    usage()
+    os.Exit(2)
+  }
+  err := cmd.run(flag.Args()[1:])
+  if err != nil {
+    fmt.Fprintln(os.Stderr, "ERROR: " + err.Error())
+    os.Exit(1)
+  }
+}
+
+func usage() {
+  fmt.Fprintln(os.Stderr, "Usage: danmctl [-kubeconfig path] <command> [flags]\n\nCommands:")
+  names := make([]string, 0, len(commands))
+  for name := range commands {
+    names = append(names, name)
+  }
+  sort.Strings(names)
+  for _, name := range names {
+    fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].description)
+  }
+  fmt.Fprintln(os.Stderr, "\nRun danmctl <command> -h for the flags of a command\n\nGlobal flags:")
+  flag.PrintDefaults()
+}
+
+func getDefaultKubeConfig() string {
+  if kubeConfig := os.Getenv("KUBECONFIG"); kubeConfig != "" {
+    return kubeConfig
+  }
+  home, err := os.UserHomeDir()
+  if err != nil {
+    return ""
+  }
+  return filepath.Join(home, ".kube", "config")
+}
+
+func getClient() (danmclientset.Interface, error) {
+  return metacni.CreateDanmClient(kubeConfig)
+}
+
+func newFlagSet(name, argsUsage string) *flag.FlagSet {
+  flags := flag.NewFlagSet(name, flag.ExitOnError)
+  flags.Usage = func() {
+    fmt.Fprintln(os.Stderr, "Usage: danmctl " + name + " [flags] " + argsUsage + "\n\n" + commands[name].description + "\n\nFlags:")
+    flags.PrintDefaults()
+  }
+  return flags
+}
+
+func newTable() *tabwriter.Writer {
+  return tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+}
+
+func printVersion(args []string) error {
+  fmt.Println("DANM binary was built from release: " + version)
+  fmt.Println("DANM binary was built from commit: " + commitHash)
+  return nil
+}
+
+//All the network APIs are returned as DanmNets, their Kind set to the API they belong to
+func getAllNetworks(client danmclientset.Interface) ([]danmtypes.DanmNet, error) {
+  networks := make([]danmtypes.DanmNet, 0)
+  dnets, err := client.DanmV1().DanmNets("").List(meta_v1.ListOptions{})
+  if err != nil {
+    return nil, errors.New("cannot list DanmNets because:" + err.Error())
+  }
+  for _, dnet := range dnets.Items {
+    dnet.TypeMeta.Kind = netcontrol.DanmNetKind
+    networks = append(networks, dnet)
+  }
+  tnets, err := client.DanmV1().TenantNetworks("").List(meta_v1.ListOptions{})
+  if err != nil {
+    return nil, errors.New("cannot list TenantNetworks because:" + err.Error())
+  }
+  for index := range tnets.Items {
+    networks = append(networks, *netcontrol.ConvertTnetToDnet(&tnets.Items[index]))
+  }
+  cnets, err := client.DanmV1().ClusterNetworks().List(meta_v1.ListOptions{})
+  if err != nil {
+    return nil, errors.New("cannot list ClusterNetworks because:" + err.Error())
+  }
+  for index := range cnets.Items {
+    networks = append(networks, *netcontrol.ConvertCnetToDnet(&cnets.Items[index]))
+  }
+  return networks, nil
+}
+
+func addNetworkFlags(flags *flag.FlagSet) (*string, *string, *string) {
+  kind := flags.String("kind", netcontrol.DanmNetKind, "API of the network, one of DanmNet, TenantNetwork, or ClusterNetwork")
+  namespace := flags.String("namespace", "default", "namespace of the network, ignored for ClusterNetworks")
+  name := flags.String("network", "", "name of the network")
+  return kind, namespace, name
+}
+
+func getNetwork(client danmclientset.Interface, kind, namespace, name string) (*danmtypes.DanmNet, error) {
+  iface := datastructs.Interface{}
+  switch kind {
+  case netcontrol.DanmNetKind:
+    iface.Network = name
+  case netcontrol.TenantNetworkKind:
+    iface.TenantNetwork = name
+  case netcontrol.ClusterNetworkKind:
+    iface.ClusterNetwork = name
+  default:
+    return nil, errors.New("network kind:" + kind + " is not one of DanmNet, TenantNetwork, or ClusterNetwork")
+  }
+  dnet, err := netcontrol.GetNetworkFromInterface(client, iface, namespace)
+  if err != nil {
+    return nil, err
+  }
+  dnet.TypeMeta.Kind = kind
+  return dnet, nil
+}
+
+func listNetworks(args []string) error {
+  flags := newFlagSet("networks", "")
+  flags.Parse(args)
+  client, err := getClient()
+  if err != nil {
+    return err
+  }
+  networks, err := getAllNetworks(client)
+  if err != nil {
+    return err
+  }
+  table := newTable()
+  fmt.Fprintln(table, "KIND\tNAMESPACE\tNAME\tTYPE\tCIDR\tIPV4 USED\tNET6\tIPV6 USED")
+  for _, dnet := range networks {
+    usage4, usage6 := ipam.GetPoolUsage(&dnet)
+    fmt.Fprintln(table, strings.Join([]string{dnet.TypeMeta.Kind, getNamespace(&dnet), dnet.ObjectMeta.Name, getNetworkType(&dnet),
+      orNone(dnet.Spec.Options.Cidr), formatUsage(usage4), orNone(dnet.Spec.Options.Pool6.Cidr), formatUsage(usage6)}, "\t"))
+  }
+  return table.Flush()
+}
+
+func showIpOwner(args []string) error {
+  flags := newFlagSet("ip-owner", "<IP>")
+  flags.Parse(args)
+  if flags.NArg() != 1 {
+    flags.Usage()
+    return errors.New("exactly one IP address shall be given")
+  }
+  ip := net.ParseIP(flags.Arg(0))
+  if ip == nil {
+    return errors.New(flags.Arg(0) + " is not a valid IP address")
+  }
+  client, err := getClient()
+  if err != nil {
+    return err
+  }
+  eps, err := getAllEps(client)
+  if err != nil {
+    return err
+  }
+  owners := make([]danmtypes.DanmEp, 0)
+  for _, ep := range eps {
+    if isSameIp(ip, ep.Spec.Iface.Address) || isSameIp(ip, ep.Spec.Iface.AddressIPv6) {
+      owners = append(owners, ep)
+    }
+  }
+  if len(owners) == 0 {
+    return errors.New("IP:" + ip.String() + " is not used by any DanmEp")
+  }
+  //The same IP can be legitimately used in multiple networks, e.g. in the same CIDR of different tenants
+  return printEps(owners)
+}
+
+func showEndpoint(args []string) error {
+  flags := newFlagSet("pod", "<EndpointID>")
+  flags.Parse(args)
+  if flags.NArg() != 1 {
+    flags.Usage()
+    return errors.New("exactly one EndpointID shall be given")
+  }
+  client, err := getClient()
+  if err != nil {
+    return err
+  }
+  eps, err := getAllEps(client)
+  if err != nil {
+    return err
+  }
+  for _, ep := range eps {
+    if ep.Spec.EndpointID == flags.Arg(0) {
+      return printEps([]danmtypes.DanmEp{ep})
+    }
+  }
+  return errors.New("EndpointID:" + flags.Arg(0) + " does not belong to any DanmEp")
+}
+
+func listFreeIps(args []string) error {
+  flags := newFlagSet("free-ips", "")
+  kind, namespace, name := addNetworkFlags(flags)
+  limit := flags.Int("limit", 100, "maximum number of listed addresses")
+  flags.Parse(args)
+  if *name == "" {
+    flags.Usage()
+    return errors.New("-network is mandatory")
+  }
+  client, err := getClient()
+  if err != nil {
+    return err
+  }
+  dnet, err := getNetwork(client, *kind, *namespace, *name)
+  if err != nil {
+    return err
+  }
+  for _, ip := range ipam.GetFreeIps(dnet, *limit) {
+    fmt.Println(ip)
+  }
+  return nil
+}
+
+func listVnis(args []string) error {
+  flags := newFlagSet("vnis", "")
+  details := flags.Bool("details", false, "lists every reserved VNI, and the networks using it")
+  flags.Parse(args)
+  client, err := getClient()
+  if err != nil {
+    return err
+  }
+  tconfs, err := client.DanmV1().TenantConfigs().List(meta_v1.ListOptions{})
+  if err != nil {
+    return errors.New("cannot list TenantConfigs because:" + err.Error())
+  }
+  networks, err := getAllNetworks(client)
+  if err != nil {
+    return err
+  }
+  table := newTable()
+  if *details {
+    fmt.Fprintln(table, "TENANTCONFIG\tDEVICE\tVNI TYPE\tVNI\tNETWORKS")
+  } else {
+    fmt.Fprintln(table, "TENANTCONFIG\tDEVICE\tVNI TYPE\tVNI RANGE\tUSED")
+  }
+  for _, tconf := range tconfs.Items {
+    for _, iface := range tconf.HostDevices {
+      if iface.VniType == "" {
+        continue
+      }
+      reservedVnis, total, err := confman.GetReservedVnis(iface)
+      if err != nil {
+        return err
+      }
+      if !*details {
+        fmt.Fprintln(table, strings.Join([]string{tconf.ObjectMeta.Name, iface.Name, iface.VniType, iface.VniRange,
+          strconv.Itoa(len(reservedVnis)) + "/" + strconv.Itoa(total)}, "\t"))
+        continue
+      }
+      for _, vni := range reservedVnis {
+        fmt.Fprintln(table, strings.Join([]string{tconf.ObjectMeta.Name, iface.Name, iface.VniType, strconv.Itoa(vni),
+          strings.Join(getNetworksUsingVni(networks, iface, vni), ",")}, "\t"))
+      }
+    }
+  }
+  return table.Flush()
+}
+
+//VNIs reserved in the TenantConfig without any network using them are leaked, e.g. because their network was deleted while the webhook was unavailable
+func getNetworksUsingVni(networks []danmtypes.DanmNet, iface danmtypes.IfaceProfile, vni int) []string {
+  users := make([]string, 0)
+  for _, dnet := range networks {
+    if dnet.Spec.Options.Device != iface.Name && dnet.Spec.Options.DevicePool != iface.Name {
+      continue
+    }
+    if (iface.VniType == "vlan" && dnet.Spec.Options.Vlan == vni) || (iface.VniType == "vxlan" && dnet.Spec.Options.Vxlan == vni) {
+      users = append(users, dnet.TypeMeta.Kind + ":" + getNamespace(&dnet) + "/" + dnet.ObjectMeta.Name)
+    }
+  }
+  if len(users) == 0 {
+    users = append(users, "<none>")
+  }
+  return users
+}
+
+func validateManifest(args []string) error {
+  flags := newFlagSet("validate", "")
+  manifestPath := flags.String("f", "", "path of the YAML, or JSON manifest of a DanmNet, TenantNetwork, ClusterNetwork, or TenantConfig")
+  tconfPath := flags.String("tenantconfig", "", "path of the TenantConfig manifest network manifests are validated against. When not set, the TenantConfig of the cluster is used if -online is set")
+  online := flags.Bool("online", false, "validates against a copy of the TenantConfig, and networks of the cluster. The cluster itself is never changed")
+  flags.Parse(args)
+  if *manifestPath == "" {
+    flags.Usage()
+    return errors.New("-f is mandatory")
+  }
+  manifest, err := readManifest(*manifestPath)
+  if err != nil {
+    return err
+  }
+  var typeMeta meta_v1.TypeMeta
+  err = json.Unmarshal(manifest, &typeMeta)
+  if err != nil {
+    return errors.New("manifest:" + *manifestPath + " cannot be decoded because:" + err.Error())
+  }
+  var result interface{}
+  switch typeMeta.Kind {
+  case "TenantConfig":
+    result, err = admit.DryRunTenantConfig(manifest)
+  case netcontrol.DanmNetKind, netcontrol.TenantNetworkKind, netcontrol.ClusterNetworkKind:
+    var sandbox danmclientset.Interface
+    sandbox, err = createSandbox(*tconfPath, *online)
+    if err != nil {
+      return err
+    }
+    result, err = admit.DryRunNetwork(manifest, sandbox)
+  default:
+    return errors.New("kind:" + typeMeta.Kind + " of manifest:" + *manifestPath + " is not a DANM API")
+  }
+  if err != nil {
+    return errors.New(typeMeta.Kind + " is invalid:" + err.Error())
+  }
+  mutated, err := json.MarshalIndent(result, "", "  ")
+  if err != nil {
+    return err
+  }
+  fmt.Fprintln(os.Stderr, typeMeta.Kind + " is valid, it is admitted as:")
+  fmt.Println(string(mutated))
+  return nil
+}
+
+func lintManifests(args []string) error {
+  flags := newFlagSet("lint", "<file or directory>...")
+  quiet := flags.Bool("q", false, "only prints the invalid manifests")
+  flags.Parse(args)
+  if flags.NArg() == 0 {
+    flags.Usage()
+    return errors.New("at least one file, or directory shall be given")
+  }
+  manifests := make([]admit.Manifest, 0)
+  for _, path := range flags.Args() {
+    pathManifests, err := admit.ReadManifests(path)
+    if err != nil {
+      return err
+    }
+    manifests = append(manifests, pathManifests...)
+  }
+  var numOfInvalid int
+  table := newTable()
+  for _, result := range admit.LintManifests(manifests) {
+    status := "OK"
+    if result.Err != nil {
+      numOfInvalid++
+      status = "ERROR: " + result.Err.Error()
+    } else if *quiet {
+      continue
+    }
+    manifest := result.Manifest
+    fmt.Fprintln(table, strings.Join([]string{manifest.Source, manifest.Kind, orNone(manifest.Namespace) + "/" + manifest.Name, status}, "\t"))
+  }
+  table.Flush()
+  if numOfInvalid > 0 {
+    return errors.New(strconv.Itoa(numOfInvalid) + " of the " + strconv.Itoa(len(manifests)) + " DANM manifests are invalid")
+  }
+  fmt.Fprintln(os.Stderr, "all the " + strconv.Itoa(len(manifests)) + " DANM manifests are valid")
+  return nil
+}
+
+func readManifest(path string) ([]byte, error) {
+  content, err := ioutil.ReadFile(path)
+  if err != nil {
+    return nil, errors.New("manifest:" + path + " cannot be read because:" + err.Error())
+  }
+  manifest, err := yaml.ToJSON(content)
+  if err != nil {
+    return nil, errors.New("manifest:" + path + " cannot be converted to JSON because:" + err.Error())
+  }
+  return manifest, nil
+}
+
+//Validation rules depending on the TenantConfig, and other networks are evaluated against a fake client
+//seeded with copies of these objects, so VNI, or routing table reservations made by the dry run never reach the cluster
+func createSandbox(tconfPath string, online bool) (danmclientset.Interface, error) {
+  sandbox := danmfake.NewSimpleClientset()
+  if online {
+    client, err := getClient()
+    if err != nil {
+      return nil, err
+    }
+    networks, err := getAllNetworks(client)
+    if err != nil {
+      return nil, err
+    }
+    for index := range networks {
+      err = copyNetwork(sandbox, &networks[index])
+      if err != nil {
+        return nil, err
+      }
+    }
+    if tconfPath == "" {
+      tconfs, err := client.DanmV1().TenantConfigs().List(meta_v1.ListOptions{})
+      if err != nil {
+        return nil, errors.New("cannot list TenantConfigs because:" + err.Error())
+      }
+      for index := range tconfs.Items {
+        _, err = sandbox.DanmV1().TenantConfigs().Create(&tconfs.Items[index])
+        if err != nil {
+          return nil, err
+        }
+      }
+    }
+  }
+  if tconfPath != "" {
+    manifest, err := readManifest(tconfPath)
+    if err != nil {
+      return nil, err
+    }
+    tconf := &danmtypes.TenantConfig{}
+    err = json.Unmarshal(manifest, tconf)
+    if err != nil {
+      return nil, errors.New("TenantConfig:" + tconfPath + " cannot be decoded because:" + err.Error())
+    }
+    _, err = sandbox.DanmV1().TenantConfigs().Create(tconf)
+    if err != nil {
+      return nil, err
+    }
+  }
+  return sandbox, nil
+}
+
+func copyNetwork(sandbox danmclientset.Interface, dnet *danmtypes.DanmNet) error {
+  var err error
+  switch dnet.TypeMeta.Kind {
+  case netcontrol.TenantNetworkKind:
+    _, err = sandbox.DanmV1().TenantNetworks(dnet.ObjectMeta.Namespace).Create(netcontrol.ConvertDnetToTnet(dnet))
+  case netcontrol.ClusterNetworkKind:
+    _, err = sandbox.DanmV1().ClusterNetworks().Create(netcontrol.ConvertDnetToCnet(dnet))
+  default:
+    _, err = sandbox.DanmV1().DanmNets(dnet.ObjectMeta.Namespace).Create(dnet)
+  }
+  return err
+}
+
+func repairAllocations(args []string) error {
+  flags := newFlagSet("repair", "")
+  kind, namespace, name := addNetworkFlags(flags)
+  dryRun := flags.Bool("dryRun", false, "only prints the differences, the allocations are not changed")
+  gracePeriod := flags.Duration("gracePeriod", 30*time.Second, "IPs allocated without a DanmEp are only freed if they are still without one after this period, so the IPs of Pods being created are kept")
+  flags.Parse(args)
+  client, err := getClient()
+  if err != nil {
+    return err
+  }
+  var networks []danmtypes.DanmNet
+  if *name == "" {
+    networks, err = getAllNetworks(client)
+  } else {
+    var dnet *danmtypes.DanmNet
+    dnet, err = getNetwork(client, *kind, *namespace, *name)
+    if dnet != nil {
+      networks = []danmtypes.DanmNet{*dnet}
+    }
+  }
+  if err != nil {
+    return err
+  }
+  var failedNetworks []string
+  //IPs allocated without a DanmEp, per inconsistent network
+  leakedIps := make(map[string][]string)
+  for _, dnet := range networks {
+    leaked, isConsistent, err := inspectNetwork(client, dnet)
+    if err != nil {
+      fmt.Fprintln(os.Stderr, "ERROR: " + err.Error())
+      failedNetworks = append(failedNetworks, dnet.ObjectMeta.Name)
+    } else if !isConsistent {
+      leakedIps[getNetId(&dnet)] = leaked
+    }
+  }
+  if *dryRun || len(leakedIps) == 0 {
+    return getRepairResult(failedNetworks)
+  }
+  for _, leaked := range leakedIps {
+    if len(leaked) > 0 {
+      fmt.Println("waiting " + gracePeriod.String() + " for the DanmEps of the Pods being created before freeing any IPs")
+      time.Sleep(*gracePeriod)
+      break
+    }
+  }
+  for _, dnet := range networks {
+    leakedBefore, isInconsistent := leakedIps[getNetId(&dnet)]
+    if !isInconsistent {
+      continue
+    }
+    err = repairNetwork(client, dnet, leakedBefore)
+    if err != nil {
+      fmt.Fprintln(os.Stderr, "ERROR: " + err.Error())
+      failedNetworks = append(failedNetworks, dnet.ObjectMeta.Name)
+    }
+  }
+  return getRepairResult(failedNetworks)
+}
+
+func getRepairResult(failedNetworks []string) error {
+  if len(failedNetworks) > 0 {
+    return errors.New("allocations of networks:" + strings.Join(failedNetworks, ",") + " could not be repaired")
+  }
+  return nil
+}
+
+func inspectNetwork(client danmclientset.Interface, dnet danmtypes.DanmNet) ([]string, bool, error) {
+  eps, err := danmep.FindByNetwork(client, &dnet)
+  if err != nil {
+    return nil, false, err
+  }
+  leaked, missing := diffNetworkAllocations(dnet, eps)
+  if len(leaked) == 0 && len(missing) == 0 {
+    fmt.Println(getNetId(&dnet) + ": allocations are consistent")
+    return nil, true, nil
+  }
+  fmt.Println(getNetId(&dnet) + ": allocated without a DanmEp:" + orNone(strings.Join(leaked, ",")) +
+    " used by a DanmEp but free:" + orNone(strings.Join(missing, ",")))
+  return leaked, false, nil
+}
+
+//The network, and its DanmEps are read again, so only the IPs which were leaked before, and are still leaked after the grace period are freed
+func repairNetwork(client danmclientset.Interface, origNet danmtypes.DanmNet, leakedBefore []string) error {
+  dnet, err := getNetwork(client, origNet.TypeMeta.Kind, origNet.ObjectMeta.Namespace, origNet.ObjectMeta.Name)
+  if err != nil {
+    return err
+  }
+  eps, err := danmep.FindByNetwork(client, dnet)
+  if err != nil {
+    return err
+  }
+  leaked, _ := diffNetworkAllocations(*dnet, eps)
+  var confirmedLeaks []string
+  for _, ip := range leaked {
+    if isListed(ip, leakedBefore) {
+      confirmedLeaks = append(confirmedLeaks, ip)
+    }
+  }
+  err = ipam.RepairAllocations(client, *dnet, eps, confirmedLeaks)
+  if err != nil {
+    return err
+  }
+  fmt.Println(getNetId(dnet) + ": allocations are repaired, freed:" + orNone(strings.Join(confirmedLeaks, ",")))
+  return nil
+}
+
+func diffNetworkAllocations(dnet danmtypes.DanmNet, eps []danmtypes.DanmEp) ([]string, []string) {
+  rebuiltAlloc, rebuiltAlloc6 := ipam.RebuildAllocations(&dnet, eps)
+  leaked, missing := ipam.DiffAllocations(dnet.Spec.Options.Alloc, rebuiltAlloc, dnet.Spec.Options.Cidr)
+  leaked6, missing6 := ipam.DiffAllocations(dnet.Spec.Options.Alloc6, rebuiltAlloc6, dnet.Spec.Options.Pool6.Cidr)
+  return append(leaked, leaked6...), append(missing, missing6...)
+}
+
+func isListed(item string, list []string) bool {
+  for _, listedItem := range list {
+    if listedItem == item {
+      return true
+    }
+  }
+  return false
+}
+
+func getNetId(dnet *danmtypes.DanmNet) string {
+  return dnet.TypeMeta.Kind + ":" + getNamespace(dnet) + "/" + dnet.ObjectMeta.Name
+}
+
+func getAllEps(client danmclientset.Interface) ([]danmtypes.DanmEp, error) {
+  eps, err := client.DanmV1().DanmEps("").List(meta_v1.ListOptions{})
+  if err != nil {
+    return nil, errors.New("cannot list DanmEps because:" + err.Error())
+  }
+  return eps.Items, nil
+}
+
+func printEps(eps []danmtypes.DanmEp) error {
+  table := newTable()
+  fmt.Fprintln(table, "NAMESPACE\tPOD\tINTERFACE\tNETWORK\tADDRESS\tADDRESS6\tHOST\tENDPOINTID\tDANMEP")
+  for _, ep := range eps {
+    apiType := ep.Spec.ApiType
+    if apiType == "" {
+      apiType = netcontrol.DanmNetKind
+    }
+    fmt.Fprintln(table, strings.Join([]string{ep.ObjectMeta.Namespace, ep.Spec.Pod, ep.Spec.Iface.Name, apiType + ":" + ep.Spec.NetworkName,
+      orNone(ep.Spec.Iface.Address), orNone(ep.Spec.Iface.AddressIPv6), orNone(ep.Spec.Host), ep.Spec.EndpointID, ep.ObjectMeta.Name}, "\t"))
+  }
+  return table.Flush()
+}
+
+func isSameIp(ip net.IP, address string) bool {
+  epIp := net.ParseIP(strings.Split(address, "/")[0])
+  return epIp != nil && epIp.Equal(ip)
+}
+
+func getNamespace(dnet *danmtypes.DanmNet) string {
+  if dnet.TypeMeta.Kind == netcontrol.ClusterNetworkKind {
+    return "-"
+  }
+  return dnet.ObjectMeta.Namespace
+}
+
+func getNetworkType(dnet *danmtypes.DanmNet) string {
+  if dnet.Spec.NetworkType == "" {
+    return "ipvlan"
+  }
+  return dnet.Spec.NetworkType
+}
+
+func formatUsage(usage ipam.PoolUsage) string {
+  if usage.Total == 0 {
+    return "-"
+  }
+  return strconv.FormatUint(uint64(usage.Used), 10) + "/" + strconv.FormatUint(uint64(usage.Total), 10)
+}
+
+func orNone(value string) string {
+  if value == "" {
+    return "-"
+  }
+  return value
+}
@@ -6,6 +6,8 @@ import (
   "log"
   "k8s.io/client-go/rest"
   "k8s.io/client-go/tools/clientcmd"
+  "github.com/nokia/danm/pkg/hotplug"
+  "github.com/nokia/danm/pkg/logger"
   "github.com/nokia/danm/pkg/netcontrol"
 )
 
@@ -22,27 +24,42 @@ func getClientConfig(kubeConfig *string) (*rest.Config, error) {
 
 func main() {
   printVersion := flag.Bool("version", false, "prints Git version information of the binary to standard out")
+  hotplugConfig := flag.String("hotplugConfig", "", "path to the DANM CNI config file of the Node. When set, DANM interfaces are hot-plugged to, and removed from running Pods whenever their danm.k8s.io/interfaces annotation changes")
+  nodeName := flag.String("nodeName", os.Getenv("NODE_NAME"), "name of the Node netwatcher runs on, only the Pods of this Node are hot-plugged. Defaults to the NODE_NAME environment variable")
+  routeExportTable := flag.Int("routeExportTable", netcontrol.DefaultRouteExportTable, "host routing table into which the routes of the local Pods connected to routed networks are published. The feature is disabled by default, i.e. when it is set to 0")
+  logConfig := logger.Config{}
+  logger.AddFlags(&logConfig)
   flag.Parse()
   if *printVersion {
     log.Println("DANM binary was built from release: " + version)
     log.Println("DANM binary was built from commit: " + commitHash)
     return
   }
-  log.SetOutput(os.Stdout)
-  log.Println("Starting DANM Watcher...")
+  err := logger.Configure(logConfig)
+  if err != nil {
+    log.Println("ERROR: Logging cannot be configured because:" + err.Error() + " , exiting")
+    os.Exit(-1)
+  }
+  logger.Info("Starting DANM Watcher...")
   kubeConfig := flag.String("kubeconf", "", "Path to a kube config. Only required if out-of-cluster.")
   flag.Parse()
   config, err := getClientConfig(kubeConfig)
   if err != nil {
-    log.Println("ERROR: Parsing kubeconfig failed with error:" + err.Error() + " , exiting")
-    os.Exit(-1)
+    logger.Fatal("Parsing kubeconfig failed with error:" + err.Error() + " , exiting")
   }
+  netcontrol.RouteExportTable = *routeExportTable
   netWatcher, err := netcontrol.NewWatcher(config)
   if err != nil {
-    log.Println("ERROR: Creation of NetWatcher failed with error:" + err.Error() + " , exiting")
-    os.Exit(-1)
+    logger.Fatal("Creation of NetWatcher failed with error:" + err.Error() + " , exiting")
   }
   stopCh := make(chan struct{})
   netWatcher.Run(&stopCh)
+  if *hotplugConfig != "" {
+    podWatcher, err := hotplug.NewPodWatcher(config, *nodeName, *hotplugConfig)
+    if err != nil {
+      logger.Fatal("Creation of PodWatcher failed with error:" + err.Error() + " , exiting")
+    }
+    podWatcher.Run(&stopCh)
+  }
   select {}
 }
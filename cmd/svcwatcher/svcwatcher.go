@@ -7,7 +7,6 @@ import (
   "time"
   "os"
   "fmt"
-  "github.com/golang/glog"
   kubeinformers "k8s.io/client-go/informers"
   "k8s.io/client-go/tools/clientcmd"
   "k8s.io/client-go/kubernetes"
@@ -21,6 +20,7 @@ import (
   corev1 "k8s.io/api/core/v1"
   danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
   danminformers "github.com/nokia/danm/crd/client/informers/externalversions"
+  "github.com/nokia/danm/pkg/logger"
   "github.com/nokia/danm/pkg/svccontrol"
 )
 
@@ -31,15 +31,21 @@ var (
 func main() {
   flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
   printVersion := flag.Bool("version", false, "prints Git version information of the binary to standard out")
+  logConfig := logger.Config{}
+  logger.AddFlags(&logConfig)
   flag.Parse()
   if *printVersion {
     log.Println("DANM binary was built from release: " + version)
     log.Println("DANM binary was built from commit: " + commitHash)
     return
   }
+  err := logger.Configure(logConfig)
+  if err != nil {
+    log.Fatal("ERROR: Logging cannot be configured because:" + err.Error())
+  }
   cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
   if err != nil {
-    glog.Fatalf("Error building kubeconfig: %s", err.Error())
+    logger.Fatal(fmt.Sprintf("Error building kubeconfig: %s", err.Error()))
     return
   }
   // use a Go context so we can tell the leaderelection code when we
@@ -50,11 +56,11 @@ func main() {
   cfg.Wrap(transport.ContextCanceller(ctx, fmt.Errorf("the leader is shutting down")))
   kubeClient, err := kubernetes.NewForConfig(cfg)
   if err != nil {
-    glog.Fatalf("Error building kubernetes clientset: %s", err.Error())
+    logger.Fatal(fmt.Sprintf("Error building kubernetes clientset: %s", err.Error()))
   }
   danmClient, err := danmclientset.NewForConfig(cfg)
   if err != nil {
-    glog.Fatalf("Error building example clientset: %s", err.Error())
+    logger.Fatal(fmt.Sprintf("Error building example clientset: %s", err.Error()))
   }
   kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Second*30)
   danmInformerFactory := danminformers.NewSharedInformerFactory(danmClient, time.Second*30)
@@ -67,7 +73,7 @@ func main() {
     go kubeInformerFactory.Start(ctx.Done())
     go danmInformerFactory.Start(ctx.Done())
     if err = controller.Run(10, ctx.Done()); err != nil {
-      glog.Fatalf("Error running controller: %s", err.Error())
+      logger.Fatal(fmt.Sprintf("Error running controller: %s", err.Error()))
     }
   }
   rl, err := resourcelock.New(resourcelock.EndpointsResourceLock,
@@ -80,7 +86,7 @@ func main() {
       EventRecorder: createRecorder(kubeClient, "danm-svc-controller"),
     })
   if err != nil {
-    glog.Fatalf("Error creating lock: %v", err)
+    logger.Fatal(fmt.Sprintf("Error creating lock: %v", err))
   }
   leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
     Lock:          rl,
@@ -94,20 +100,22 @@ func main() {
       },
     },
   })
-  glog.Fatalln("Lost lease")
+  logger.Fatal("Lost lease")
 }
 
 func GetHostname() (string) {
   ret, err := os.Hostname()
   if err != nil {
-    glog.Fatalf("hostname %v", err)
+    logger.Fatal(fmt.Sprintf("hostname %v", err))
   }
   return ret
 }
 
 func createRecorder(kubeClient *kubernetes.Clientset, comp string) record.EventRecorder {
   eventBroadcaster := record.NewBroadcaster()
-  eventBroadcaster.StartLogging(glog.Infof)
+  eventBroadcaster.StartLogging(func(format string, args ...interface{}) {
+    logger.Info(fmt.Sprintf(format, args...))
+  })
   eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("kube-system")})
   return eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: comp})
 }
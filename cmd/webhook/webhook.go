@@ -8,6 +8,7 @@ import (
   "crypto/tls"
   "net/http"
   "github.com/nokia/danm/pkg/admit"
+  "github.com/nokia/danm/pkg/logger"
 )
 
 var(
@@ -20,24 +21,31 @@ func main() {
   port := flag.Int("bind-port", 8443, "the port on which to serve. Default is 8443.")
   address := flag.String("bind-address", "", "the IP address on which to listen. Default is all interfaces.")
   printVersion := flag.Bool("version", false, "prints Git version information of the binary to standard out")
+  logConfig := logger.Config{}
+  logger.AddFlags(&logConfig)
   flag.Parse()
   if *printVersion {
     log.Println("DANM binary was built from release: " + version)
     log.Println("DANM binary was built from commit: " + commitHash)
     return
   }
+  err := logger.Configure(logConfig)
+  if err != nil {
+    log.Println("ERROR: Logging cannot be configured because:" + err.Error())
+    return
+  }
   if cert == nil || key == nil {
-    log.Println("ERROR: Configuring TLS is mandatory, --tls-cert-bundle and --tls-private-key-file cannot be empty!")
+    logger.Error("Configuring TLS is mandatory, --tls-cert-bundle and --tls-private-key-file cannot be empty!")
     return
   }
   tlsConf, err := tls.LoadX509KeyPair(*cert, *key)
   if err != nil {
-    log.Println("ERROR: TLS configuration could not be initialized, because:" + err.Error())
+    logger.Error("TLS configuration could not be initialized, because:" + err.Error())
     return
   }
   validator, err := admit.CreateNewValidator()
   if err != nil {
-    log.Println("ERROR: Cannot create DANM REST client, because:" + err.Error())
+    logger.Error("Cannot create DANM REST client, because:" + err.Error())
     return
   }
   http.HandleFunc("/netvalidation", validator.ValidateNetwork)
@@ -49,7 +57,7 @@ func main() {
     ReadTimeout:  5 * time.Second,
     WriteTimeout: 5 * time.Second,
   }
-  log.Println("INFO:DANM webhook is about to start listening on " + *address + ":" + strconv.Itoa(*port))
+  logger.Info("DANM webhook is about to start listening on " + *address + ":" + strconv.Itoa(*port))
   err = server.ListenAndServeTLS("", "")
-  log.Fatal(err)
+  logger.Fatal("DANM webhook stopped serving because:" + err.Error())
 }
\ No newline at end of file
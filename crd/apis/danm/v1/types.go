@@ -51,6 +51,66 @@ type DanmNetOption struct {
   RTables int `json:"rt_tables,omitempty"`
   // the VLAN id of the VLAN interface created on top of the host device
   Vlan  int  `json:"vlan,omitempty"`
+  // Fixed MAC address of the host bridge of a bridge type network
+  BridgeMac string `json:"bridge_mac,omitempty"`
+  // Enables hairpin mode on the host side veth interfaces of a bridge type network
+  Hairpin bool `json:"hairpin,omitempty"`
+  // 802.1p priority of the VLAN tag set on the VFs of an SR-IOV network
+  VlanQos int `json:"vlan_qos,omitempty"`
+  // Spoof checking setting of the VFs of an SR-IOV network ("on" or "off")
+  SpoofChk string `json:"spoofchk,omitempty"`
+  // Trusted mode setting of the VFs of an SR-IOV network ("on" or "off")
+  Trust string `json:"trust,omitempty"`
+  // Minimum TX rate of the VFs of an SR-IOV network in Mbps
+  MinTxRate int `json:"min_tx_rate,omitempty"`
+  // Maximum TX rate of the VFs of an SR-IOV network in Mbps
+  MaxTxRate int `json:"max_tx_rate,omitempty"`
+  // Link state of the VFs of an SR-IOV network ("auto", "enable" or "disable")
+  LinkState string `json:"link_state,omitempty"`
+  // ConfigMap storing the CNI configuration of a static backend under the "<NetworkID>.conf" key, in "<namespace>/<name>", or "<name>" format
+  CniConfigMap string `json:"cni_config_map,omitempty"`
+  // Derives the MAC address of the container interfaces from their IPs, unless the Pod explicitly asks for one
+  MacFromIp bool `json:"mac_from_ip,omitempty"`
+  // Interface-scoped kernel parameters set for the container interfaces, in "<family>.<parameter>": "<value>" format (e.g. "ipv4.rp_filter": "2")
+  Sysctls map[string]string `json:"sysctls,omitempty"`
+  // Traffic shaping limits of the container interfaces
+  Bandwidth BandwidthLimits `json:"bandwidth,omitempty"`
+  // IPv4, and IPv6 routes for this network, supporting everything the routes, and routes6 maps cannot express
+  IpRoutes []IpRoute `json:"ip_routes,omitempty"`
+  // IP routes failing to be installed only generate a warning Event on the Pod, instead of failing the creation of the interface
+  LenientRoutes bool `json:"lenient_routes,omitempty"`
+}
+
+// IpRoute is an IP route installed into the default routing table of the Pods connected to a network
+type IpRoute struct {
+  // Destination subnet in CIDR notation, its IP version decides whether the route is an IPv4, or IPv6 route
+  Destination string `json:"destination"`
+  // Next hops of the route. Multiple next hops make an ECMP route. A route without next hops is a directly connected route
+  Gateways []RouteNextHop `json:"gateways,omitempty"`
+  // Priority of the route, lower is preferred
+  Metric int `json:"metric,omitempty"`
+  // Scope of the route ("universe", "link", or "host")
+  Scope string `json:"scope,omitempty"`
+  // Makes the kernel treat the gateways as directly reachable, even if they are outside the subnet of the network
+  Onlink bool `json:"onlink,omitempty"`
+  // Preferred source address of the packets sent via the route
+  Source string `json:"source,omitempty"`
+  // Path MTU of the route
+  Mtu int `json:"mtu,omitempty"`
+}
+
+type RouteNextHop struct {
+  Gateway string `json:"gateway"`
+  // Relative weight of the next hop in an ECMP route (1-256), 1 when omitted
+  Weight int `json:"weight,omitempty"`
+}
+
+// BandwidthLimits describes the traffic shaping of an interface from the Pod's point of view. Rates are in bits per second, bursts in bits
+type BandwidthLimits struct {
+  IngressRate  uint64 `json:"ingressRate,omitempty"`
+  IngressBurst uint64 `json:"ingressBurst,omitempty"`
+  EgressRate   uint64 `json:"egressRate,omitempty"`
+  EgressBurst  uint64 `json:"egressBurst,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -76,7 +136,8 @@ type IpPoolV6 struct {
 type DanmEp struct {
   meta_v1.TypeMeta   `json:",inline"`
   meta_v1.ObjectMeta `json:"metadata"`
-  Spec               DanmEpSpec `json:"spec"`
+  Spec               DanmEpSpec   `json:"spec"`
+  Status             DanmEpStatus `json:"status,omitempty"`
 }
 
 type DanmEpSpec struct {
@@ -96,11 +157,48 @@ type DanmEpIface struct {
   Name        string            `json:"Name"`
   Address     string            `json:"Address"`
   AddressIPv6 string            `json:"AddressIPv6"`
-  //DEPRECATED, WILL BE REMOVED
+  // MAC address of the container interface, either requested by the Pod, or derived from its IP
   MacAddress  string            `json:"MacAddress"`
   Proutes     map[string]string `json:"proutes"`
   Proutes6    map[string]string `json:"proutes6"`
   DeviceID    string            `json:"DeviceID,omitempty"`
+  Sysctls     map[string]string `json:"sysctls,omitempty"`
+  Bandwidth   BandwidthLimits   `json:"bandwidth,omitempty"`
+  // Policy rules, and IP routes DANM installed into the network namespace of the Pod for this interface
+  // They are removed when the interface is deleted
+  Rules       []IfaceRule       `json:"rules,omitempty"`
+  Routes      []IfaceRoute      `json:"routes,omitempty"`
+}
+
+// DanmEpStatus records the actual state of the interface, as observed by DANM at the end of its creation
+type DanmEpStatus struct {
+  MacAddress  string       `json:"macAddress,omitempty"`
+  OperState   string       `json:"operState,omitempty"`
+  Mtu         int          `json:"mtu,omitempty"`
+  // All the global IP addresses of the interface, including the ones assigned by a delegated CNI plugin
+  Addresses   []string     `json:"addresses,omitempty"`
+  InstallTime meta_v1.Time `json:"installTime,omitempty"`
+  // The step of CNI ADD which failed, or only partially succeeded (IPAM, LinkCreation, PostProcessing), and its error
+  FailedStep  string       `json:"failedStep,omitempty"`
+  Error       string       `json:"error,omitempty"`
+  // The CNI result returned by the delegated CNI plugin, in JSON
+  CniResult   string       `json:"cniResult,omitempty"`
+}
+
+type IfaceRule struct {
+  Table   int    `json:"table"`
+  Family  int    `json:"family"`
+  Src     string `json:"src,omitempty"`
+  OifName string `json:"oif,omitempty"`
+  IifName string `json:"iif,omitempty"`
+}
+
+type IfaceRoute struct {
+  Destination string `json:"destination"`
+  Gateway     string `json:"gateway,omitempty"`
+  OifName     string `json:"oif,omitempty"`
+  Table       int    `json:"table,omitempty"`
+  Metric      int    `json:"metric,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -121,6 +219,10 @@ type TenantConfig struct {
   meta_v1.ObjectMeta            `json:"metadata"`
   HostDevices []IfaceProfile    `json:"hostDevices,omitempty"`
   NetworkIds  map[string]string `json:"networkIds,omitempty"`
+  // Range of routing table IDs automatically allocated to the networks created without rt_tables, e.g. "100-200,300"
+  RtTableRange string           `json:"rtTableRange,omitempty"`
+  // bit array tracking the allocated routing table IDs
+  RtTableAlloc string           `json:"rtTableAlloc,omitempty"`
 }
 
 type IfaceProfile struct {
@@ -171,4 +273,36 @@ type ClusterNetworkList struct {
   meta_v1.TypeMeta `json:",inline"`
   meta_v1.ListMeta `json:"metadata"`
   Items            []ClusterNetwork `json:"items"`
-}
\ No newline at end of file
+}
+
+// VERY IMPORTANT NOT TO CHANGE THIS, INCLUDING THE EMPTY LINE BETWEEN THE ANNOTATIONS!!!
+// https://github.com/kubernetes/code-generator/issues/59
+// +genclient:nonNamespaced
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+type CniTemplate struct {
+  meta_v1.TypeMeta   `json:",inline"`
+  meta_v1.ObjectMeta `json:"metadata"`
+  Spec               CniTemplateSpec `json:"spec"`
+}
+
+// The name of a CniTemplate is the NetworkType it generates CNI configuration for, which is also the name of the invoked CNI binary
+type CniTemplateSpec struct {
+  // CNI version of the rendered configuration
+  CniVersion   string `json:"cniVersion,omitempty"`
+  // Instructs DANM to allocate IPs for the interface, and to pass them to the backend via the IPAM placeholder
+  IpamNeeded   bool   `json:"ipamNeeded,omitempty"`
+  // Instructs DANM to pop a Device from the network's device_pool for every interface
+  DeviceNeeded bool   `json:"deviceNeeded,omitempty"`
+  // Go text/template of the CNI configuration
+  Template     string `json:"template"`
+}
+
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CniTemplateList struct {
+  meta_v1.TypeMeta `json:",inline"`
+  meta_v1.ListMeta `json:"metadata"`
+  Items            []CniTemplate `json:"items"`
+}
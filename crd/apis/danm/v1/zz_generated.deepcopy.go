@@ -24,6 +24,22 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthLimits) DeepCopyInto(out *BandwidthLimits) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthLimits.
+func (in *BandwidthLimits) DeepCopy() *BandwidthLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterNetwork) DeepCopyInto(out *ClusterNetwork) {
 	*out = *in
@@ -84,12 +100,89 @@ func (in *ClusterNetworkList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CniTemplate) DeepCopyInto(out *CniTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CniTemplate.
+func (in *CniTemplate) DeepCopy() *CniTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(CniTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CniTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CniTemplateList) DeepCopyInto(out *CniTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CniTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CniTemplateList.
+func (in *CniTemplateList) DeepCopy() *CniTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(CniTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CniTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CniTemplateSpec) DeepCopyInto(out *CniTemplateSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CniTemplateSpec.
+func (in *CniTemplateSpec) DeepCopy() *CniTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CniTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DanmEp) DeepCopyInto(out *DanmEp) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -128,6 +221,24 @@ func (in *DanmEpIface) DeepCopyInto(out *DanmEpIface) {
 			(*out)[key] = val
 		}
 	}
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	out.Bandwidth = in.Bandwidth
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]IfaceRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]IfaceRoute, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -191,6 +302,28 @@ func (in *DanmEpSpec) DeepCopy() *DanmEpSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DanmEpStatus) DeepCopyInto(out *DanmEpStatus) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.InstallTime.DeepCopyInto(&out.InstallTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DanmEpStatus.
+func (in *DanmEpStatus) DeepCopy() *DanmEpStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DanmEpStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DanmNet) DeepCopyInto(out *DanmNet) {
 	*out = *in
@@ -270,6 +403,21 @@ func (in *DanmNetOption) DeepCopyInto(out *DanmNetOption) {
 		}
 	}
 	out.Pool6 = in.Pool6
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	out.Bandwidth = in.Bandwidth
+	if in.IpRoutes != nil {
+		in, out := &in.IpRoutes, &out.IpRoutes
+		*out = make([]IpRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -321,6 +469,38 @@ func (in *IfaceProfile) DeepCopy() *IfaceProfile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IfaceRoute) DeepCopyInto(out *IfaceRoute) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IfaceRoute.
+func (in *IfaceRoute) DeepCopy() *IfaceRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(IfaceRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IfaceRule) DeepCopyInto(out *IfaceRule) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IfaceRule.
+func (in *IfaceRule) DeepCopy() *IfaceRule {
+	if in == nil {
+		return nil
+	}
+	out := new(IfaceRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IpPool) DeepCopyInto(out *IpPool) {
 	*out = *in
@@ -354,6 +534,43 @@ func (in *IpPoolV6) DeepCopy() *IpPoolV6 {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IpRoute) DeepCopyInto(out *IpRoute) {
+	*out = *in
+	if in.Gateways != nil {
+		in, out := &in.Gateways, &out.Gateways
+		*out = make([]RouteNextHop, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IpRoute.
+func (in *IpRoute) DeepCopy() *IpRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(IpRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteNextHop) DeepCopyInto(out *RouteNextHop) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteNextHop.
+func (in *RouteNextHop) DeepCopy() *RouteNextHop {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteNextHop)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TenantConfig) DeepCopyInto(out *TenantConfig) {
 	*out = *in
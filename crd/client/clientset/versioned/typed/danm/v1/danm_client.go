@@ -27,6 +27,7 @@ import (
 type DanmV1Interface interface {
 	RESTClient() rest.Interface
 	ClusterNetworksGetter
+	CniTemplatesGetter
 	DanmEpsGetter
 	DanmNetsGetter
 	TenantConfigsGetter
@@ -42,6 +43,10 @@ func (c *DanmV1Client) ClusterNetworks() ClusterNetworkInterface {
 	return newClusterNetworks(c)
 }
 
+func (c *DanmV1Client) CniTemplates() CniTemplateInterface {
+	return newCniTemplates(c)
+}
+
 func (c *DanmV1Client) DanmEps(namespace string) DanmEpInterface {
 	return newDanmEps(c, namespace)
 }
@@ -39,6 +39,7 @@ type DanmEpsGetter interface {
 type DanmEpInterface interface {
 	Create(*v1.DanmEp) (*v1.DanmEp, error)
 	Update(*v1.DanmEp) (*v1.DanmEp, error)
+	UpdateStatus(*v1.DanmEp) (*v1.DanmEp, error)
 	Delete(name string, options *metav1.DeleteOptions) error
 	DeleteCollection(options *metav1.DeleteOptions, listOptions metav1.ListOptions) error
 	Get(name string, options metav1.GetOptions) (*v1.DanmEp, error)
@@ -132,6 +133,22 @@ func (c *danmEps) Update(danmEp *v1.DanmEp) (result *v1.DanmEp, err error) {
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *danmEps) UpdateStatus(danmEp *v1.DanmEp) (result *v1.DanmEp, err error) {
+	result = &v1.DanmEp{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("danmeps").
+		Name(danmEp.Name).
+		SubResource("status").
+		Body(danmEp).
+		Do().
+		Into(result)
+	return
+}
+
 // Delete takes name of the danmEp and deletes it. Returns an error if one occurs.
 func (c *danmEps) Delete(name string, options *metav1.DeleteOptions) error {
 	return c.client.Delete().
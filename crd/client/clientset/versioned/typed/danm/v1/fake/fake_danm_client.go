@@ -32,6 +32,10 @@ func (c *FakeDanmV1) ClusterNetworks() v1.ClusterNetworkInterface {
 	return &FakeClusterNetworks{c}
 }
 
+func (c *FakeDanmV1) CniTemplates() v1.CniTemplateInterface {
+	return &FakeCniTemplates{c}
+}
+
 func (c *FakeDanmV1) DanmEps(namespace string) v1.DanmEpInterface {
 	return &FakeDanmEps{c, namespace}
 }
@@ -100,6 +100,18 @@ func (c *FakeDanmEps) Update(danmEp *danmv1.DanmEp) (result *danmv1.DanmEp, err
 	return obj.(*danmv1.DanmEp), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeDanmEps) UpdateStatus(danmEp *danmv1.DanmEp) (*danmv1.DanmEp, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(danmepsResource, "status", c.ns, danmEp), &danmv1.DanmEp{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*danmv1.DanmEp), err
+}
+
 // Delete takes name of the danmEp and deletes it. Returns an error if one occurs.
 func (c *FakeDanmEps) Delete(name string, options *v1.DeleteOptions) error {
 	_, err := c.Fake.
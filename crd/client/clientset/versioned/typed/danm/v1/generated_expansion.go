@@ -20,6 +20,8 @@ package v1
 
 type ClusterNetworkExpansion interface{}
 
+type CniTemplateExpansion interface{}
+
 type DanmEpExpansion interface{}
 
 type DanmNetExpansion interface{}
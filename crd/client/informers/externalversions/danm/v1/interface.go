@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// ClusterNetworks returns a ClusterNetworkInformer.
 	ClusterNetworks() ClusterNetworkInformer
+	// CniTemplates returns a CniTemplateInformer.
+	CniTemplates() CniTemplateInformer
 	// DanmEps returns a DanmEpInformer.
 	DanmEps() DanmEpInformer
 	// DanmNets returns a DanmNetInformer.
@@ -52,6 +54,11 @@ func (v *version) ClusterNetworks() ClusterNetworkInformer {
 	return &clusterNetworkInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// CniTemplates returns a CniTemplateInformer.
+func (v *version) CniTemplates() CniTemplateInformer {
+	return &cniTemplateInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // DanmEps returns a DanmEpInformer.
 func (v *version) DanmEps() DanmEpInformer {
 	return &danmEpInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -55,6 +55,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=danm.k8s.io, Version=v1
 	case v1.SchemeGroupVersion.WithResource("clusternetworks"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Danm().V1().ClusterNetworks().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("cnitemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Danm().V1().CniTemplates().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("danmeps"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Danm().V1().DanmEps().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("danmnets"):
@@ -22,6 +22,10 @@ package v1
 // ClusterNetworkLister.
 type ClusterNetworkListerExpansion interface{}
 
+// CniTemplateListerExpansion allows custom methods to be added to
+// CniTemplateLister.
+type CniTemplateListerExpansion interface{}
+
 // DanmEpListerExpansion allows custom methods to be added to
 // DanmEpLister.
 type DanmEpListerExpansion interface{}
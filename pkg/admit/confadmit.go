@@ -8,13 +8,25 @@ import (
   "net/http"
   "k8s.io/api/admission/v1beta1"
   danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
   "github.com/nokia/danm/pkg/bitarray"
 )
 
 const (
   //This is just a dimensioning decision to avoid reserving unnecessarily big bitarrays in TenantConfig
   MaxAllowedVni = 5000
+  MaxAllowedRtTable = 5000
   HostDevicePath = "/hostDevices"
+  RtTableAllocPath = "/rtTableAlloc"
+  reservedRtTableNames = "253 (default), 254 (main), 255 (local)"
+)
+
+var (
+  reservedRtTables = map[int]string {
+    253: "default",
+    254: "main",
+    255: "local",
+  }
 )
 
 func (validator *Validator) ValidateTenantConfig(responseWriter http.ResponseWriter, request *http.Request) {
@@ -38,7 +50,7 @@ func (validator *Validator) ValidateTenantConfig(responseWriter http.ResponseWri
   origDevices := make([]danmtypes.IfaceProfile, len(newManifest.HostDevices))
   copy(origDevices, newManifest.HostDevices)
   origNewManifest.HostDevices = origDevices
-  isManifestValid, err := validateConfig(oldManifest, newManifest, admissionReview.Request.Operation)
+  isManifestValid, err := validateConfig(oldManifest, newManifest, admissionReview.Request.Operation, validator.Client)
   if !isManifestValid {
     SendErroneousAdmissionResponse(responseWriter, admissionReview.Request, err)
     return
@@ -70,11 +82,11 @@ func decodeTenantConfig(objectToReview []byte) (*danmtypes.TenantConfig,error) {
 
 //TODO: as above. Until reflection is figured out, this is somewhat of a duplication
 //Maybe a struct wrapping the exact object type could also work (that would push reflection responsibility on the validators though)
-func validateConfig(oldManifest, newManifest *danmtypes.TenantConfig, opType v1beta1.Operation) (bool,error) {
+func validateConfig(oldManifest, newManifest *danmtypes.TenantConfig, opType v1beta1.Operation, client danmclientset.Interface) (bool,error) {
   if newManifest.TypeMeta.Kind != "TenantConfig" {
     return false, errors.New("K8s API type:" + newManifest.TypeMeta.Kind + " is not handled by DANM webhook")
   }
-  err := validateTenantconfig(oldManifest,newManifest,opType)
+  err := validateTenantconfig(oldManifest,newManifest,opType,client)
   if err != nil {
       return false, err
   }
@@ -98,6 +110,9 @@ func createPatchListFromConfigChanges(origConfig danmtypes.TenantConfig, changed
     hostDevicesPatch += `]`
     patchList = append(patchList, CreateGenericPatchFromChange(HostDevicePath, json.RawMessage(hostDevicesPatch)))
   }
+  if origConfig.RtTableAlloc != changedConfig.RtTableAlloc {
+    patchList = append(patchList, CreateGenericPatchFromChange(RtTableAllocPath, changedConfig.RtTableAlloc))
+  }
   return patchList
 }
 
@@ -110,5 +125,9 @@ func mutateConfigManifest(tconf *danmtypes.TenantConfig) {
     bitArray, _ := bitarray.NewBitArray(MaxAllowedVni+1)
     tconf.HostDevices[ifaceIndex].Alloc = bitArray.Encode()
   }
+  if tconf.RtTableRange != "" && tconf.RtTableAlloc == "" {
+    bitArray, _ := bitarray.NewBitArray(MaxAllowedRtTable+1)
+    tconf.RtTableAlloc = bitArray.Encode()
+  }
   return
 }
\ No newline at end of file
@@ -0,0 +1,56 @@
+package admit
+
+import (
+  "errors"
+  "k8s.io/api/admission/v1beta1"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
+)
+
+// DryRunNetwork validates, and mutates a JSON network manifest exactly the same way as the webhook does on its creation, and returns the mutated network
+// Rules depending on other objects (e.g. the TenantConfig, or the routing tables used by other networks) are evaluated against the objects the client knows about,
+// and all the changes, like VNI reservations are made through it: use a fake client seeded with a copy of the objects to validate offline, without changing the cluster
+func DryRunNetwork(manifest []byte, client danmclientset.Interface) (*danmtypes.DanmNet,error) {
+  newManifest, err := getNetworkManifest(manifest)
+  if err != nil {
+    return nil, err
+  }
+  err = dryRunNetwork(newManifest, client)
+  if err != nil {
+    return nil, err
+  }
+  return newManifest, nil
+}
+
+func dryRunNetwork(dnet *danmtypes.DanmNet, client danmclientset.Interface) error {
+  oldManifest, _ := getNetworkManifest(nil)
+  isManifestValid, err := validateNetworkByType(oldManifest, dnet, v1beta1.Create, client)
+  if !isManifestValid {
+    return err
+  }
+  origManifest := *dnet
+  err = mutateNetManifest(client, dnet, v1beta1.Create)
+  if err != nil {
+    return errors.New("network cannot be mutated:" + err.Error())
+  }
+  err = postValidateManifest(client, oldManifest, dnet, v1beta1.Create)
+  if err != nil {
+    return releaseMutatedDetails(client, &origManifest, dnet, err)
+  }
+  return nil
+}
+
+// DryRunTenantConfig validates, and mutates a JSON TenantConfig manifest exactly the same way as the webhook does on its creation, and returns the mutated TenantConfig
+func DryRunTenantConfig(manifest []byte) (*danmtypes.TenantConfig,error) {
+  oldManifest, _ := decodeTenantConfig(nil)
+  newManifest, err := decodeTenantConfig(manifest)
+  if err != nil {
+    return nil, err
+  }
+  isManifestValid, err := validateConfig(oldManifest, newManifest, v1beta1.Create, nil)
+  if !isManifestValid {
+    return nil, err
+  }
+  mutateConfigManifest(newManifest)
+  return newManifest, nil
+}
@@ -0,0 +1,229 @@
+package admit
+
+import (
+  "bufio"
+  "errors"
+  "io"
+  "os"
+  "path/filepath"
+  "strconv"
+  "strings"
+  "encoding/json"
+  "k8s.io/api/admission/v1beta1"
+  "k8s.io/apimachinery/pkg/util/yaml"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
+  danmfake "github.com/nokia/danm/crd/client/clientset/versioned/fake"
+  "github.com/nokia/danm/pkg/netcontrol"
+)
+
+const (
+  DanmApiGroup = "danm.k8s.io"
+  tenantConfigKind = "TenantConfig"
+  defaultNamespace = "default"
+)
+
+// Manifest is one JSON encoded object read from a manifest file, Source identifies the file, and the document within the file
+type Manifest struct {
+  Source string
+  Kind string
+  Namespace string
+  Name string
+  Content []byte
+}
+
+// LintResult is the outcome of validating one Manifest, Err is nil if the webhook would admit the object
+type LintResult struct {
+  Manifest Manifest
+  Err error
+}
+
+// ValidateNetworkManifest runs the validation rules of the API of a network manifest exactly like the webhook does, without changing the manifest
+func ValidateNetworkManifest(oldManifest, newManifest *danmtypes.DanmNet, opType v1beta1.Operation, client danmclientset.Interface) error {
+  if oldManifest == nil {
+    oldManifest = &danmtypes.DanmNet{}
+  }
+  _, err := validateNetworkByType(oldManifest, newManifest.DeepCopy(), opType, client)
+  return err
+}
+
+// ValidateTenantConfigManifest runs the validation rules of TenantConfigs exactly like the webhook does, without changing the manifest
+func ValidateTenantConfigManifest(oldManifest, newManifest *danmtypes.TenantConfig, opType v1beta1.Operation) error {
+  if oldManifest == nil {
+    oldManifest = &danmtypes.TenantConfig{}
+  }
+  _, err := validateConfig(oldManifest, newManifest.DeepCopy(), opType, nil)
+  return err
+}
+
+// ReadManifests reads the DANM objects from a YAML, or JSON manifest file, or from all such files of a directory tree
+// Files can contain multiple YAML documents, objects of other API groups are skipped
+func ReadManifests(path string) ([]Manifest, error) {
+  manifests := make([]Manifest, 0)
+  err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+    if err != nil {
+      return err
+    }
+    if info.IsDir() {
+      return nil
+    }
+    extension := strings.ToLower(filepath.Ext(filePath))
+    if filePath != path && extension != ".yaml" && extension != ".yml" && extension != ".json" {
+      return nil
+    }
+    fileManifests, err := readManifestFile(filePath)
+    if err != nil {
+      return err
+    }
+    manifests = append(manifests, fileManifests...)
+    return nil
+  })
+  if err != nil {
+    return nil, errors.New("manifests cannot be read from:" + path + " because:" + err.Error())
+  }
+  return manifests, nil
+}
+
+func readManifestFile(path string) ([]Manifest, error) {
+  file, err := os.Open(path)
+  if err != nil {
+    return nil, err
+  }
+  defer file.Close()
+  manifests := make([]Manifest, 0)
+  reader := yaml.NewYAMLReader(bufio.NewReader(file))
+  for docIndex := 1; ; docIndex++ {
+    doc, err := reader.Read()
+    if err == io.EOF {
+      return manifests, nil
+    }
+    if err != nil {
+      return nil, errors.New("document:" + strconv.Itoa(docIndex) + " of file:" + path + " cannot be read:" + err.Error())
+    }
+    source := path + "#" + strconv.Itoa(docIndex)
+    content, err := yaml.ToJSON(doc)
+    if err != nil {
+      return nil, errors.New("document:" + source + " is not valid YAML:" + err.Error())
+    }
+    //Empty documents, e.g. the one after a trailing document separator are decoded to null
+    if len(strings.TrimSpace(string(content))) == 0 || string(content) == "null" {
+      continue
+    }
+    var header struct {
+      ApiVersion string `json:"apiVersion"`
+      Kind string `json:"kind"`
+      Metadata struct {
+        Name string `json:"name"`
+        Namespace string `json:"namespace"`
+      } `json:"metadata"`
+    }
+    err = json.Unmarshal(content, &header)
+    if err != nil {
+      return nil, errors.New("document:" + source + " cannot be decoded:" + err.Error())
+    }
+    if !strings.HasPrefix(header.ApiVersion, DanmApiGroup + "/") {
+      continue
+    }
+    manifest := Manifest{Source: source, Kind: header.Kind, Namespace: header.Metadata.Namespace, Name: header.Metadata.Name, Content: content}
+    if manifest.Namespace == "" && manifest.Kind != tenantConfigKind && manifest.Kind != netcontrol.ClusterNetworkKind {
+      manifest.Namespace = defaultNamespace
+    }
+    manifests = append(manifests, manifest)
+  }
+}
+
+// LintManifests validates, and mutates all the manifests as if they were created in an empty cluster one after the other by the webhook
+// Rules spanning multiple objects are evaluated against a fake client seeded with all the manifests, so e.g. TenantNetworks are validated against the TenantConfig,
+// routing tables shared by multiple networks are reported, and the VNI, and routing table ranges of the TenantConfig are checked to be big enough for all the networks
+func LintManifests(manifests []Manifest) []LintResult {
+  results := make([]LintResult, len(manifests))
+  client := danmfake.NewSimpleClientset()
+  seen := make(map[string]string)
+  tconfIndexes := make([]int, 0)
+  netIndexes := make([]int, 0)
+  for index, manifest := range manifests {
+    results[index].Manifest = manifest
+    results[index].Err = checkIdentity(manifest, seen)
+    if results[index].Err != nil {
+      continue
+    }
+    if manifest.Kind == tenantConfigKind {
+      tconfIndexes = append(tconfIndexes, index)
+    } else {
+      netIndexes = append(netIndexes, index)
+    }
+  }
+  //TenantConfigs come first, as the networks depend on them
+  for _, index := range tconfIndexes {
+    tconf, err := DryRunTenantConfig(manifests[index].Content)
+    if err == nil {
+      _, err = client.DanmV1().TenantConfigs().Create(tconf)
+    }
+    results[index].Err = err
+  }
+  //Every network is known before the first is validated, so conflicts are reported for all the involved networks, independently of their order
+  seededNets := make(map[int]bool)
+  for _, index := range netIndexes {
+    dnet, err := decodeLintedNetwork(manifests[index])
+    if err == nil && createNetwork(client, dnet) == nil {
+      seededNets[index] = true
+    }
+  }
+  for _, index := range netIndexes {
+    results[index].Err = lintNetwork(client, manifests[index], seededNets[index])
+  }
+  return results
+}
+
+func checkIdentity(manifest Manifest, seen map[string]string) error {
+  if manifest.Name == "" {
+    return errors.New("metadata.name is mandatory")
+  }
+  if manifest.Kind != tenantConfigKind && danmValidationConfig[manifest.Kind] == nil {
+    return errors.New("K8s API type:" + manifest.Kind + " is not handled by DANM webhook")
+  }
+  id := manifest.Kind + ":" + manifest.Namespace + "/" + manifest.Name
+  if firstSource, isSeen := seen[id]; isSeen {
+    return errors.New(id + " is already defined in:" + firstSource)
+  }
+  seen[id] = manifest.Source
+  return nil
+}
+
+func decodeLintedNetwork(manifest Manifest) (*danmtypes.DanmNet, error) {
+  dnet, err := getNetworkManifest(manifest.Content)
+  if err != nil {
+    return nil, err
+  }
+  dnet.ObjectMeta.Namespace = manifest.Namespace
+  return dnet, nil
+}
+
+func lintNetwork(client danmclientset.Interface, manifest Manifest, isSeeded bool) error {
+  dnet, err := decodeLintedNetwork(manifest)
+  if err != nil {
+    return err
+  }
+  err = dryRunNetwork(dnet, client)
+  if err != nil {
+    return err
+  }
+  //Later networks shall see the routing table, and other details allocated to this one during mutation
+  if isSeeded {
+    _, err = netcontrol.PutNetwork(client, dnet)
+  }
+  return err
+}
+
+func createNetwork(client danmclientset.Interface, dnet *danmtypes.DanmNet) error {
+  var err error
+  switch dnet.TypeMeta.Kind {
+  case netcontrol.TenantNetworkKind:
+    _, err = client.DanmV1().TenantNetworks(dnet.ObjectMeta.Namespace).Create(netcontrol.ConvertDnetToTnet(dnet))
+  case netcontrol.ClusterNetworkKind:
+    _, err = client.DanmV1().ClusterNetworks().Create(netcontrol.ConvertDnetToCnet(dnet))
+  default:
+    _, err = client.DanmV1().DanmNets(dnet.ObjectMeta.Namespace).Create(dnet)
+  }
+  return err
+}
@@ -27,6 +27,7 @@ var (
     "Device": "/spec/Options/host_device",
     "Vlan": "/spec/Options/vlan",
     "Vxlan": "/spec/Options/vxlan",
+    "RTables": "/spec/Options/rt_tables",
   }
 )
 
@@ -66,14 +67,14 @@ func (validator *Validator) ValidateNetwork(responseWriter http.ResponseWriter,
     SendErroneousAdmissionResponse(responseWriter, admissionReview.Request, err)
     return
   }
-  err = mutateNetManifest(validator.Client, newManifest)
+  err = mutateNetManifest(validator.Client, newManifest, admissionReview.Request.Operation)
   if err != nil {
-    SendErroneousAdmissionResponse(responseWriter, admissionReview.Request, err)
+    SendErroneousAdmissionResponse(responseWriter, admissionReview.Request, releaseMutatedDetails(validator.Client, &origNewManifest, newManifest, err))
     return
   }
-  err = postValidateManifest(newManifest)
+  err = postValidateManifest(validator.Client, oldManifest, newManifest, admissionReview.Request.Operation)
   if err != nil {
-    SendErroneousAdmissionResponse(responseWriter, admissionReview.Request, err)
+    SendErroneousAdmissionResponse(responseWriter, admissionReview.Request, releaseMutatedDetails(validator.Client, &origNewManifest, newManifest, err))
     return
   }
   responseAdmissionReview := v1beta1.AdmissionReview {
@@ -112,7 +113,7 @@ func validateNetworkByType(oldManifest, newManifest *danmtypes.DanmNet, opType v
   return true, nil
 }
 
-func mutateNetManifest(danmClient danmclientset.Interface, dnet *danmtypes.DanmNet) error {
+func mutateNetManifest(danmClient danmclientset.Interface, dnet *danmtypes.DanmNet, opType v1beta1.Operation) error {
   if dnet.Spec.NetworkType == "" {
     dnet.Spec.NetworkType = "ipvlan"
   }
@@ -120,16 +121,75 @@ func mutateNetManifest(danmClient danmclientset.Interface, dnet *danmtypes.DanmN
   //L3, freshly added network
   if dnet.TypeMeta.Kind == "TenantNetwork" {
     err = addTenantSpecificDetails(danmClient, dnet)
+    if err != nil {
+      return err
+    }
+  }
+  if opType == v1beta1.Create && dnet.Spec.Options.RTables == 0 {
+    err = allocateRtTable(danmClient, dnet)
   }
   return err
 }
 
+//Policy-based routes are requested in the annotation of Pods, which the webhook never sees, and the CNI cannot modify the network when the first Pod asks for them.
+//So every new network gets its own routing table upfront when the administrator configured a range for them
+func allocateRtTable(danmClient danmclientset.Interface, dnet *danmtypes.DanmNet) error {
+  tconf, err := confman.GetTenantConfig(danmClient)
+  if err != nil || tconf.RtTableRange == "" {
+    return nil
+  }
+  usedTables, err := getUsedRtTables(danmClient, dnet)
+  if err != nil {
+    return errors.New("cannot allocate routing table because networks cannot be listed:" + err.Error())
+  }
+  rtTable, err := confman.ReserveRtTable(danmClient, tconf, usedTables)
+  if err != nil {
+    return errors.New("cannot allocate routing table because:" + err.Error())
+  }
+  dnet.Spec.Options.RTables = rtTable
+  return nil
+}
+
 //This is needed because some mandatory validation rules might be only enforced during mutation phase.
 //So we cannot validate those rules beforehand, but we also can't be sure they are satisfied by variable user configuration.
 //Example is NetworkID related validations for TenantNetworks
 //TODO: make this also fancy when more post validation needs surface
-func postValidateManifest(dnet *danmtypes.DanmNet) error {
-  return validateNetworkId(nil, dnet, "", nil)
+func postValidateManifest(danmClient danmclientset.Interface, oldManifest, dnet *danmtypes.DanmNet, opType v1beta1.Operation) error {
+  err := validateNetworkId(nil, dnet, "", danmClient)
+  if err != nil {
+    return err
+  }
+  //The host device, VNI, and NetworkID of TenantNetworks are only known after mutation
+  if dnet.TypeMeta.Kind == "TenantNetwork" {
+    return validateNetworkConflicts(oldManifest, dnet, opType, danmClient)
+  }
+  return nil
+}
+
+//The VNI, and routing table reserved during the mutation of a network are given back when the mutation fails midway, or the network is rejected afterwards
+func releaseMutatedDetails(danmClient danmclientset.Interface, origManifest, dnet *danmtypes.DanmNet, rejectionErr error) error {
+  isVniReserved := origManifest.Spec.Options.Vlan == 0 && origManifest.Spec.Options.Vxlan == 0 && (dnet.Spec.Options.Vlan != 0 || dnet.Spec.Options.Vxlan != 0)
+  isRtTableReserved := origManifest.Spec.Options.RTables == 0 && dnet.Spec.Options.RTables != 0
+  if !isVniReserved && !isRtTableReserved {
+    return rejectionErr
+  }
+  tconf, err := confman.GetTenantConfig(danmClient)
+  if err != nil {
+    return errors.New(rejectionErr.Error() + ", and the details reserved for the network cannot be freed because:" + err.Error())
+  }
+  if isVniReserved && dnet.TypeMeta.Kind == "TenantNetwork" {
+    err = confman.Free(danmClient, tconf, dnet)
+    if err != nil {
+      return errors.New(rejectionErr.Error() + ", and the VNI reserved for the network cannot be freed because:" + err.Error())
+    }
+  }
+  if isRtTableReserved && confman.IsRtTableInRange(tconf, dnet.Spec.Options.RTables) {
+    err = confman.FreeRtTable(danmClient, tconf, dnet.Spec.Options.RTables)
+    if err != nil {
+      return errors.New(rejectionErr.Error() + ", and the routing table reserved for the network cannot be freed because:" + err.Error())
+    }
+  }
+  return rejectionErr
 }
 
 //TODO: we could easily add CIDR + allocation pool overwrites as well for TenantNetworks, if needed
@@ -139,7 +199,11 @@ func addTenantSpecificDetails(danmClient danmclientset.Interface, tnet *danmtype
   if err != nil {
     return err
   }
-  if IsTypeDynamic(tnet.Spec.NetworkType) {
+  isTypeDynamic, err := IsTypeDynamic(danmClient, tnet.Spec.NetworkType)
+  if err != nil {
+    return errors.New("no way to tell if NetworkType:" + tnet.Spec.NetworkType + " is dynamic due to:" + err.Error())
+  }
+  if isTypeDynamic {
     err = allocateDetailsForDynamicBackends(danmClient, tnet,tconf)
     if err != nil {
       return err
@@ -233,5 +297,8 @@ func createPatchListFromNetChanges(origNetwork danmtypes.DanmNet, changedNetwork
   if origNetwork.Spec.Options.Vxlan != changedNetwork.Spec.Options.Vxlan {
     patchList = append(patchList, CreateGenericPatchFromChange(NetworkPatchPaths["Vxlan"], changedNetwork.Spec.Options.Vxlan))
   }
+  if origNetwork.Spec.Options.RTables != changedNetwork.Spec.Options.RTables {
+    patchList = append(patchList, CreateGenericPatchFromChange(NetworkPatchPaths["RTables"], changedNetwork.Spec.Options.RTables))
+  }
   return patchList
 }
@@ -33,7 +33,13 @@ func (validator *Validator) DeleteNetwork(responseWriter http.ResponseWriter, re
     errors.New("Network cannot be deleted because there are Pods still connected to it e.g. Pod:" + connectedEp.Spec.Pod + " in namespace:" + connectedEp.ObjectMeta.Namespace))
     return   
   }
-  if oldManifest.TypeMeta.Kind == "TenantNetwork" && IsTypeDynamic(oldManifest.Spec.NetworkType) {
+  isTypeDynamic, err := IsTypeDynamic(validator.Client, oldManifest.Spec.NetworkType)
+  if err != nil {
+    SendErroneousAdmissionResponse(responseWriter, admissionReview.Request,
+    errors.New("The network's VNI could not be freed, because there is no way to tell if its NetworkType is dynamic due to:" + err.Error()))
+    return
+  }
+  if oldManifest.TypeMeta.Kind == "TenantNetwork" && isTypeDynamic {
     tconf, err := confman.GetTenantConfig(validator.Client)
     if err != nil {
       SendErroneousAdmissionResponse(responseWriter, admissionReview.Request,
@@ -47,6 +53,17 @@ func (validator *Validator) DeleteNetwork(responseWriter http.ResponseWriter, re
       return
     }
   }
+  if oldManifest.Spec.Options.RTables != 0 {
+    tconf, err := confman.GetTenantConfig(validator.Client)
+    if err == nil && confman.IsRtTableInRange(tconf, oldManifest.Spec.Options.RTables) {
+      err = confman.FreeRtTable(validator.Client, tconf, oldManifest.Spec.Options.RTables)
+      if err != nil {
+        SendErroneousAdmissionResponse(responseWriter, admissionReview.Request,
+        errors.New("The network's routing table could not be freed, because:" + err.Error()))
+        return
+      }
+    }
+  }
   responseAdmissionReview := v1beta1.AdmissionReview {
     Response: CreateReviewResponseFromPatches(nil),
   }
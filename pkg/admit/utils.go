@@ -2,7 +2,6 @@ package admit
 
 import (
   "errors"
-  "log"
   "strings"
   "encoding/json"
   "io/ioutil"
@@ -11,7 +10,9 @@ import (
   metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
   "k8s.io/apimachinery/pkg/runtime"
   "k8s.io/apimachinery/pkg/runtime/serializer"
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
   "github.com/nokia/danm/pkg/cnidel"
+  "github.com/nokia/danm/pkg/logger"
 )
 
 type Patch struct {
@@ -37,7 +38,7 @@ func DecodeAdmissionReview(httpRequest *http.Request) (v1beta1.AdmissionReview,e
 }
 
 func SendErroneousAdmissionResponse(responseWriter http.ResponseWriter, request *v1beta1.AdmissionRequest, err error) {
-  log.Println("ERROR: Admitting resource failed with error:" + err.Error())
+  getRequestLogger(request).Error("Admitting resource failed with error:" + err.Error())
   failedResponse := &v1beta1.AdmissionResponse {
     Result: &metav1.Status {
       Message: err.Error(),
@@ -53,15 +54,22 @@ func SendErroneousAdmissionResponse(responseWriter http.ResponseWriter, request
   SendAdmissionResponse(responseWriter, responseAdmissionReview)
 }
 
+func getRequestLogger(request *v1beta1.AdmissionRequest) *logger.Entry {
+  if request == nil {
+    return logger.WithFields(nil)
+  }
+  return logger.WithFields(logger.Fields{logger.NamespaceField: request.Namespace, "kind": request.Kind.Kind, "name": request.Name})
+}
+
 func SendAdmissionResponse(responseWriter http.ResponseWriter, reviewResponse v1beta1.AdmissionReview) {
   respBytes, err := json.Marshal(reviewResponse)
   if err != nil {
-    log.Println("ERROR: Failed to send AdmissionResponse for request:" + string(reviewResponse.Response.UID) + " because JSON marshalling failed with error:" + err.Error())
+    logger.Error("Failed to send AdmissionResponse for request:" + string(reviewResponse.Response.UID) + " because JSON marshalling failed with error:" + err.Error())
   }
   responseWriter.Header().Set("Content-Type", "application/json")
   _, err = responseWriter.Write(respBytes)
   if err != nil {
-    log.Println("ERROR: Failed to send AdmissionRespons for request:" + string(reviewResponse.Response.UID) + " because putting the HTTP response on the wire failed with error:" + err.Error())
+    logger.Error("Failed to send AdmissionResponse for request:" + string(reviewResponse.Response.UID) + " because putting the HTTP response on the wire failed with error:" + err.Error())
   }
 }
 
@@ -94,10 +102,24 @@ func CreateGenericPatchFromChange(path string, value interface{}) Patch {
   return patch
 }
 
-func IsTypeDynamic(cniType string) bool {
+// IsTypeDeviceBased tells if the backend of a NetworkType requires a K8s Device, backends defined by CniTemplates included
+func IsTypeDeviceBased(client danmclientset.Interface, cniType string) (bool,error) {
+  cni, err := cnidel.GetBackendConfig(client, cniType)
+  if err != nil {
+    return false, err
+  }
+  return cni != nil && cni.DeviceNeeded, nil
+}
+
+// IsTypeDynamic tells if the CNI config of a NetworkType is generated by DANM, i.e. if the backend is natively supported, or defined by a CniTemplate
+func IsTypeDynamic(client danmclientset.Interface, cniType string) (bool,error) {
   neType := strings.ToLower(cniType)
-  if _, ok := cnidel.SupportedNativeCnis[neType]; ok || neType == "" || neType == "ipvlan" {
-    return true
+  if neType == "" || neType == "ipvlan" || neType == "bridge" || neType == "ovs" {
+    return true, nil
+  }
+  cni, err := cnidel.GetBackendConfig(client, neType)
+  if err != nil {
+    return false, err
   }
-  return false
+  return cni != nil, nil
 }
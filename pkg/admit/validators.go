@@ -4,12 +4,15 @@ import (
   "errors"
   "net"
   "strconv"
+  "strings"
   admissionv1 "k8s.io/api/admission/v1beta1"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
   danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
   danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
   "github.com/nokia/danm/pkg/datastructs"
   "github.com/nokia/danm/pkg/danmep"
   "github.com/nokia/danm/pkg/ipam"
+  "github.com/nokia/danm/pkg/netcontrol"
   "k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 )
 
@@ -18,9 +21,9 @@ const (
 )
 
 var (
-  DanmNetMapping = []ValidatorFunc{validateIpv4Fields,validateIpv6Fields,validateAllocationPools,validateVids,validateNetworkId,validateAbsenceOfAllowedTenants,validateNeType,validateVniChange}
-  ClusterNetMapping = []ValidatorFunc{validateIpv4Fields,validateIpv6Fields,validateAllocationPools,validateVids,validateNetworkId,validateNeType,validateVniChange}
-  TenantNetMapping = []ValidatorFunc{validateIpv4Fields,validateIpv6Fields,validateAllocationPools,validateAbsenceOfAllowedTenants,validateTenantNetRules,validateNeType}
+  DanmNetMapping = []ValidatorFunc{validateIpv4Fields,validateIpv6Fields,validateAllocationPools,validateVids,validateNetworkId,validateAbsenceOfAllowedTenants,validateNeType,validateVniChange,validateSysctls,validateBandwidth,validateRtTables,validateNetworkConflicts}
+  ClusterNetMapping = []ValidatorFunc{validateIpv4Fields,validateIpv6Fields,validateAllocationPools,validateVids,validateNetworkId,validateNeType,validateVniChange,validateSysctls,validateBandwidth,validateRtTables,validateNetworkConflicts}
+  TenantNetMapping = []ValidatorFunc{validateIpv4Fields,validateIpv6Fields,validateAllocationPools,validateAbsenceOfAllowedTenants,validateTenantNetRules,validateNeType,validateSysctls,validateBandwidth,validateRtTables}
   danmValidationConfig = map[string]ValidatorMapping {
     "DanmNet": DanmNetMapping,
     "ClusterNetwork": ClusterNetMapping,
@@ -32,16 +35,16 @@ type ValidatorFunc func(oldManifest, newManifest *danmtypes.DanmNet, opType admi
 type ValidatorMapping []ValidatorFunc
 
 func validateIpv4Fields(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation, client danmclientset.Interface) error {
-  return validateIpFields(newManifest.Spec.Options.Cidr, newManifest.Spec.Options.Routes)
+  return validateIpFields(newManifest.Spec.Options.Cidr, newManifest.Spec.Options.Routes, danmep.GetIpRoutesOfFamily(newManifest.Spec.Options.IpRoutes, false))
 }
 
 func validateIpv6Fields(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation, client danmclientset.Interface) error {
-  return validateIpFields(newManifest.Spec.Options.Net6, newManifest.Spec.Options.Routes6)
+  return validateIpFields(newManifest.Spec.Options.Net6, newManifest.Spec.Options.Routes6, danmep.GetIpRoutesOfFamily(newManifest.Spec.Options.IpRoutes, true))
 }
 
-func validateIpFields(cidr string, routes map[string]string) error {
+func validateIpFields(cidr string, routes map[string]string, ipRoutes []danmtypes.IpRoute) error {
   if cidr == "" {
-    if routes != nil  {
+    if routes != nil || len(ipRoutes) > 0 {
       return errors.New("IP routes cannot be defined for a L2 network")
     }
     return nil
@@ -50,11 +53,74 @@ func validateIpFields(cidr string, routes map[string]string) error {
   if err != nil {
     return errors.New("Invalid CIDR: " + cidr)
   }
-  for _, gw := range routes {
+  isIpv6 := ipnet.IP.To4() == nil
+  for dst, gw := range routes {
+    _, dstNet, err := net.ParseCIDR(dst)
+    if err != nil || (dstNet.IP.To4() == nil) != isIpv6 {
+      return errors.New("Specified route destination:" + dst + " is not a valid CIDR from the same IP family as CIDR:" + cidr)
+    }
+    if net.ParseIP(gw) == nil {
+      return errors.New("Specified GW address:" + gw + " of route destination:" + dst + " is not a valid IP address")
+    }
     if !ipnet.Contains(net.ParseIP(gw)) {
       return errors.New("Specified GW address:" + gw + " is not part of CIDR:" + cidr)
     }
   }
+  for _, ipRoute := range ipRoutes {
+    err = validateIpRoute(ipRoute, ipnet)
+    if err != nil {
+      return errors.New("Invalid IP route with destination:" + ipRoute.Destination + " because:" + err.Error())
+    }
+  }
+  return nil
+}
+
+func validateIpRoute(ipRoute danmtypes.IpRoute, ipnet *net.IPNet) error {
+  _, _, err := net.ParseCIDR(ipRoute.Destination)
+  if err != nil {
+    return errors.New("destination is not a valid CIDR")
+  }
+  if !danmep.IsRouteScopeValid(ipRoute.Scope) {
+    return errors.New("scope must be one of universe, link, or host")
+  }
+  if len(ipRoute.Gateways) == 0 {
+    if ipRoute.Onlink {
+      return errors.New("onlink can only be set for routes having gateways")
+    }
+  } else if ipRoute.Scope == "link" || ipRoute.Scope == "host" {
+    return errors.New("routes having gateways must have universe scope")
+  }
+  isIpv6 := ipnet.IP.To4() == nil
+  gateways := make(map[string]bool, len(ipRoute.Gateways))
+  for _, nextHop := range ipRoute.Gateways {
+    gw := net.ParseIP(nextHop.Gateway)
+    if gw == nil || (gw.To4() == nil) != isIpv6 {
+      return errors.New("gateway:" + nextHop.Gateway + " is not a valid IP address of the same family as the destination")
+    }
+    if !ipRoute.Onlink && !ipnet.Contains(gw) {
+      return errors.New("gateway:" + nextHop.Gateway + " is not part of CIDR:" + ipnet.String() + ", and the route is not onlink")
+    }
+    if gateways[gw.String()] {
+      return errors.New("gateway:" + nextHop.Gateway + " is listed multiple times")
+    }
+    gateways[gw.String()] = true
+    //An omitted weight is 0, which is installed with the default weight of 1
+    if nextHop.Weight < 0 || nextHop.Weight > danmep.MaxNextHopWeight {
+      return errors.New("weight of gateway:" + nextHop.Gateway + " must be between 1 and " + strconv.Itoa(danmep.MaxNextHopWeight) + ", or omitted")
+    }
+  }
+  if ipRoute.Metric < 0 {
+    return errors.New("metric cannot be negative")
+  }
+  if ipRoute.Mtu < 0 {
+    return errors.New("MTU cannot be negative")
+  }
+  if ipRoute.Source != "" {
+    src := net.ParseIP(ipRoute.Source)
+    if src == nil || (src.To4() == nil) != isIpv6 {
+      return errors.New("source:" + ipRoute.Source + " is not a valid IP address of the same family as the destination")
+    }
+  }
   return nil
 }
 
@@ -91,7 +157,7 @@ func validateAllocV4(newManifest *danmtypes.DanmNet) error {
     return errors.New("Netmask of the IPv4 CIDR is bigger than the maximum allowed /"+ strconv.Itoa(datastructs.MaxV4MaskLength))
   }
   newManifest.Spec.Options.Pool.Start, newManifest.Spec.Options.Pool.End, newManifest.Spec.Options.Alloc =
-    ipam.InitAllocPool(newManifest.Spec.Options.Cidr, newManifest.Spec.Options.Pool.Start, newManifest.Spec.Options.Pool.End, newManifest.Spec.Options.Alloc, newManifest.Spec.Options.Routes)
+    ipam.InitAllocPool(newManifest.Spec.Options.Cidr, newManifest.Spec.Options.Pool.Start, newManifest.Spec.Options.Pool.End, newManifest.Spec.Options.Alloc, ipam.GetRouteGateways(newManifest.Spec.Options.Routes, newManifest.Spec.Options.IpRoutes))
   if !ipnet.Contains(net.ParseIP(newManifest.Spec.Options.Pool.Start)) || !ipnet.Contains(net.ParseIP(newManifest.Spec.Options.Pool.End)) {
     return errors.New("Allocation pool is outside of defined CIDR!")
   }
@@ -138,7 +204,7 @@ func validateAllocV6(newManifest *danmtypes.DanmNet) error {
     return errors.New("IPv6 allocation pool is outside of the defined IPv6 subnet!")
   }
   newManifest.Spec.Options.Pool6.Start, newManifest.Spec.Options.Pool6.End, newManifest.Spec.Options.Alloc6 =
-    ipam.InitAllocPool(newManifest.Spec.Options.Pool6.Cidr, newManifest.Spec.Options.Pool6.Start, newManifest.Spec.Options.Pool6.End, newManifest.Spec.Options.Alloc6, newManifest.Spec.Options.Routes6)
+    ipam.InitAllocPool(newManifest.Spec.Options.Pool6.Cidr, newManifest.Spec.Options.Pool6.Start, newManifest.Spec.Options.Pool6.End, newManifest.Spec.Options.Alloc6, ipam.GetRouteGateways(newManifest.Spec.Options.Routes6, newManifest.Spec.Options.IpRoutes))
   if ipam.Ip62int(net.ParseIP(newManifest.Spec.Options.Pool6.End)).Cmp(ipam.Ip62int(net.ParseIP(newManifest.Spec.Options.Pool6.Start))) <=0 {
     return errors.New("Allocation pool start:" + newManifest.Spec.Options.Pool6.Start + " is bigger than or equal to allocation pool end:" + newManifest.Spec.Options.Pool6.End)
   }
@@ -158,9 +224,17 @@ func validateNetworkId(oldManifest, newManifest *danmtypes.DanmNet, opType admis
   if newManifest.Spec.NetworkID == "" {
     return errors.New("Spec.NetworkID mandatory parameter is missing!")
   }
-  if len(newManifest.Spec.NetworkID) > MaxNidLength && IsTypeDynamic(newManifest.Spec.NetworkType) &&
-    (newManifest.Spec.Options.Vxlan != 0 || newManifest.Spec.Options.Vlan != 0) {
-    return errors.New("Spec.NetworkID cannot be longer than " + strconv.Itoa(MaxNidLength) + " characters (otherwise VLAN and VxLAN host interface creation might fail)!")
+  if len(newManifest.Spec.NetworkID) > MaxNidLength && (newManifest.Spec.Options.Vxlan != 0 || newManifest.Spec.Options.Vlan != 0) {
+    isTypeDynamic, err := IsTypeDynamic(client, newManifest.Spec.NetworkType)
+    if err != nil {
+      return errors.New("no way to tell if NetworkType:" + newManifest.Spec.NetworkType + " is dynamic due to:" + err.Error())
+    }
+    if isTypeDynamic {
+      return errors.New("Spec.NetworkID cannot be longer than " + strconv.Itoa(MaxNidLength) + " characters (otherwise VLAN and VxLAN host interface creation might fail)!")
+    }
+  }
+  if len(newManifest.Spec.NetworkID) > MaxNidLength && strings.ToLower(newManifest.Spec.NetworkType) == "bridge" {
+    return errors.New("Spec.NetworkID cannot be longer than " + strconv.Itoa(MaxNidLength) + " characters for bridge networks (otherwise host bridge creation might fail)!")
   }
   return nil
 }
@@ -188,11 +262,14 @@ func validateTenantNetRules(oldManifest, newManifest *danmtypes.DanmNet, opType
   return nil
 }
 
-func validateTenantconfig(oldManifest, newManifest *danmtypes.TenantConfig, opType admissionv1.Operation) error {
-  if len(newManifest.HostDevices) == 0 && len(newManifest.NetworkIds) == 0 {
-    return errors.New("Either hostDevices, or networkIds must be provided!")
+func validateTenantconfig(oldManifest, newManifest *danmtypes.TenantConfig, opType admissionv1.Operation, client danmclientset.Interface) error {
+  if len(newManifest.HostDevices) == 0 && len(newManifest.NetworkIds) == 0 && newManifest.RtTableRange == "" {
+    return errors.New("Either hostDevices, networkIds, or rtTableRange must be provided!")
+  }
+  err := validateRtTableRange(newManifest, opType)
+  if err != nil {
+    return err
   }
-  var err error
   for _, ifaceConf := range newManifest.HostDevices {
     err = validateIfaceConfig(ifaceConf, opType)
     if err != nil {
@@ -203,13 +280,48 @@ func validateTenantconfig(oldManifest, newManifest *danmtypes.TenantConfig, opTy
     if nType == "" || nId == "" {
       return errors.New("neither NetworkID, nor NetworkType can be empty in a NetworkID mapping!")
     }
-    if len(nId) > MaxNidLength && IsTypeDynamic(nType) {
+    if len(nId) <= MaxNidLength {
+      continue
+    }
+    isTypeDynamic, err := IsTypeDynamic(client, nType)
+    if err != nil {
+      return errors.New("no way to tell if NetworkType:" + nType + " is dynamic due to:" + err.Error())
+    }
+    if isTypeDynamic {
       return errors.New("NetworkID:" + nId + " cannot be longer than " + strconv.Itoa(MaxNidLength) + " characters (otherwise VLAN and VxLAN host interface creation might fail)!")
     }
   }
   return nil
 }
 
+func validateRtTableRange(tconf *danmtypes.TenantConfig, opType admissionv1.Operation) error {
+  if tconf.RtTableRange == "" {
+    if tconf.RtTableAlloc != "" {
+      return errors.New("rtTableAlloc cannot be provided without rtTableRange!")
+    }
+    return nil
+  }
+  if opType == admissionv1.Create && tconf.RtTableAlloc != "" {
+    return errors.New("Allocation bitmask of routing tables shall not be manually defined upon creation!")
+  }
+  tableSet, err := cpuset.Parse(tconf.RtTableRange)
+  if err != nil {
+    return errors.New("rtTableRange must be improperly formatted because its parsing fails with:" + err.Error())
+  }
+  invalidSet := tableSet.Filter(func(table int) bool {
+    return table == 0 || table > MaxAllowedRtTable || isRtTableReserved(table)
+  })
+  if invalidSet.Size() > 0 {
+    return errors.New("rtTableRange is invalid, because it cannot contain 0, the reserved tables:" + reservedRtTableNames + ", or IDs over the maximum supported number that is:" + strconv.Itoa(MaxAllowedRtTable))
+  }
+  return nil
+}
+
+func isRtTableReserved(table int) bool {
+  _, isReserved := reservedRtTables[table]
+  return isReserved
+}
+
 func validateIfaceConfig(ifaceConf danmtypes.IfaceProfile, opType admissionv1.Operation) error {
   if ifaceConf.Name == "" {
     return errors.New("name attribute of a hostDevice must not be empty!")
@@ -239,13 +351,151 @@ func validateIfaceConfig(ifaceConf danmtypes.IfaceProfile, opType admissionv1.Op
 }
 
 func validateNeType(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation, client danmclientset.Interface) error {
-  if newManifest.Spec.NetworkType == "sriov" {
+  isTypeDeviceBased, err := IsTypeDeviceBased(client, newManifest.Spec.NetworkType)
+  if err != nil {
+    return errors.New("no way to tell if NetworkType:" + newManifest.Spec.NetworkType + " requires a K8s Device due to:" + err.Error())
+  }
+  if isTypeDeviceBased {
     if newManifest.Spec.Options.DevicePool == "" || newManifest.Spec.Options.Device != "" {
-      return errors.New("Spec.Options.device_pool must, and Spec.Options.host_device cannot be provided for SR-IOV networks!")
+      return errors.New("Spec.Options.device_pool must, and Spec.Options.host_device cannot be provided for " + newManifest.Spec.NetworkType + " networks!")
     }
-  } else if newManifest.Spec.Options.Device != "" && newManifest.Spec.Options.DevicePool != "" {
+  } else if newManifest.Spec.Options.Device != "" && newManifest.Spec.Options.DevicePool != "" && strings.ToLower(newManifest.Spec.NetworkType) != "ovs" {
     return errors.New("Spec.Options.device_pool and Spec.Options.host_device cannot be provided together!")
   }
+  err = validateBridgeOptions(newManifest)
+  if err != nil {
+    return err
+  }
+  err = validateRoutedOptions(newManifest)
+  if err != nil {
+    return err
+  }
+  err = validateSriovOptions(newManifest)
+  if err != nil {
+    return err
+  }
+  err = validateOvsOptions(newManifest)
+  if err != nil {
+    return err
+  }
+  err = validateMacOptions(newManifest)
+  if err != nil {
+    return err
+  }
+  return validateCniConfigMap(newManifest, client)
+}
+
+func validateBridgeOptions(dnet *danmtypes.DanmNet) error {
+  if strings.ToLower(dnet.Spec.NetworkType) != "bridge" {
+    if dnet.Spec.Options.BridgeMac != "" || dnet.Spec.Options.Hairpin {
+      return errors.New("Spec.Options.bridge_mac and Spec.Options.hairpin can only be provided for bridge networks!")
+    }
+    return nil
+  }
+  if dnet.Spec.Options.DevicePool != "" {
+    return errors.New("Spec.Options.device_pool cannot be provided for bridge networks!")
+  }
+  if dnet.Spec.Options.BridgeMac != "" {
+    mac, err := net.ParseMAC(dnet.Spec.Options.BridgeMac)
+    if err != nil || len(mac) != 6 {
+      return errors.New("Spec.Options.bridge_mac:" + dnet.Spec.Options.BridgeMac + " is not a valid MAC address!")
+    }
+    if mac[0] & 0x01 != 0 {
+      return errors.New("Spec.Options.bridge_mac:" + dnet.Spec.Options.BridgeMac + " cannot be a multicast MAC address!")
+    }
+  }
+  return nil
+}
+
+func validateRoutedOptions(dnet *danmtypes.DanmNet) error {
+  if strings.ToLower(dnet.Spec.NetworkType) != "routed" {
+    return nil
+  }
+  if dnet.Spec.Options.Device != "" || dnet.Spec.Options.DevicePool != "" || dnet.Spec.Options.Vlan != 0 || dnet.Spec.Options.Vxlan != 0 {
+    return errors.New("Spec.Options.host_device, device_pool, vlan, and vxlan cannot be provided for routed networks!")
+  }
+  return nil
+}
+
+func validateSriovOptions(dnet *danmtypes.DanmNet) error {
+  opts := dnet.Spec.Options
+  if strings.ToLower(dnet.Spec.NetworkType) != "sriov" {
+    if opts.VlanQos != 0 || opts.SpoofChk != "" || opts.Trust != "" || opts.MinTxRate != 0 || opts.MaxTxRate != 0 || opts.LinkState != "" {
+      return errors.New("Spec.Options.vlan_qos, spoofchk, trust, min_tx_rate, max_tx_rate, and link_state can only be provided for SR-IOV networks!")
+    }
+    return nil
+  }
+  if opts.SpoofChk != "" && opts.SpoofChk != "on" && opts.SpoofChk != "off" {
+    return errors.New("Spec.Options.spoofchk:" + opts.SpoofChk + " is invalid, it can only be on or off!")
+  }
+  if opts.Trust != "" && opts.Trust != "on" && opts.Trust != "off" {
+    return errors.New("Spec.Options.trust:" + opts.Trust + " is invalid, it can only be on or off!")
+  }
+  if opts.LinkState != "" && opts.LinkState != "auto" && opts.LinkState != "enable" && opts.LinkState != "disable" {
+    return errors.New("Spec.Options.link_state:" + opts.LinkState + " is invalid, it can only be auto, enable, or disable!")
+  }
+  if opts.MinTxRate < 0 || opts.MaxTxRate < 0 {
+    return errors.New("Spec.Options.min_tx_rate and max_tx_rate cannot be negative!")
+  }
+  if opts.MaxTxRate != 0 && opts.MinTxRate > opts.MaxTxRate {
+    return errors.New("Spec.Options.min_tx_rate:" + strconv.Itoa(opts.MinTxRate) + " cannot be higher than Spec.Options.max_tx_rate:" + strconv.Itoa(opts.MaxTxRate))
+  }
+  if opts.VlanQos < 0 || opts.VlanQos > 7 {
+    return errors.New("Spec.Options.vlan_qos:" + strconv.Itoa(opts.VlanQos) + " is invalid, it must be between 0 and 7!")
+  }
+  //TenantNetworks get their VLAN from the interface profile only during mutation
+  if opts.VlanQos != 0 && opts.Vlan == 0 && dnet.TypeMeta.Kind != "TenantNetwork" {
+    return errors.New("Spec.Options.vlan_qos can only be provided together with Spec.Options.vlan!")
+  }
+  return nil
+}
+
+func validateOvsOptions(dnet *danmtypes.DanmNet) error {
+  if strings.ToLower(dnet.Spec.NetworkType) != "ovs" {
+    return nil
+  }
+  //The OVS bridge of TenantNetworks is chosen from the interface profiles of the TenantConfig
+  if dnet.Spec.Options.Device == "" && dnet.TypeMeta.Kind != "TenantNetwork" {
+    return errors.New("Spec.Options.host_device must contain the name of the OVS bridge for OVS networks!")
+  }
+  if dnet.Spec.Options.Vxlan != 0 {
+    return errors.New("Spec.Options.vxlan cannot be provided for OVS networks!")
+  }
+  return nil
+}
+
+func validateMacOptions(dnet *danmtypes.DanmNet) error {
+  if !dnet.Spec.Options.MacFromIp {
+    return nil
+  }
+  if !danmep.IsMacConfigurable(dnet.Spec.NetworkType) {
+    return errors.New("Spec.Options.mac_from_ip can only be provided for bridge, routed, OVS, MACVLAN, and SR-IOV networks!")
+  }
+  if dnet.Spec.Options.Cidr == "" && dnet.Spec.Options.Net6 == "" {
+    return errors.New("Spec.Options.mac_from_ip requires DANM to allocate the IPs of the network, so either Spec.Options.cidr, or Spec.Options.net6 must be provided!")
+  }
+  return nil
+}
+
+func validateCniConfigMap(dnet *danmtypes.DanmNet, client danmclientset.Interface) error {
+  cmRef := dnet.Spec.Options.CniConfigMap
+  if cmRef == "" {
+    return nil
+  }
+  isTypeDynamic, err := IsTypeDynamic(client, dnet.Spec.NetworkType)
+  if err != nil {
+    return errors.New("no way to tell if NetworkType:" + dnet.Spec.NetworkType + " is dynamic due to:" + err.Error())
+  }
+  if isTypeDynamic || strings.ToLower(dnet.Spec.NetworkType) == "routed" {
+    return errors.New("Spec.Options.cni_config_map can only be provided for networks with static backends!")
+  }
+  refParts := strings.Split(cmRef, "/")
+  if len(refParts) > 2 || refParts[0] == "" || refParts[len(refParts)-1] == "" {
+    return errors.New("Spec.Options.cni_config_map:" + cmRef + " is invalid, it must be in <namespace>/<name>, or <name> format!")
+  }
+  if dnet.TypeMeta.Kind == "ClusterNetwork" && len(refParts) != 2 {
+    return errors.New("Spec.Options.cni_config_map of ClusterNetworks must contain the namespace of the ConfigMap!")
+  }
   return nil
 }
 
@@ -266,3 +516,212 @@ func validateVniChange(oldManifest, newManifest *danmtypes.DanmNet, opType admis
   }
   return nil
 }
+
+func validateRtTables(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation, client danmclientset.Interface) error {
+  rtTable := newManifest.Spec.Options.RTables
+  if rtTable == 0 || (opType == admissionv1.Update && oldManifest.Spec.Options.RTables == rtTable) {
+    return nil
+  }
+  if rtTable < 0 || isRtTableReserved(rtTable) {
+    return errors.New("Spec.Options.rt_tables cannot be negative, or one of the reserved routing tables:" + reservedRtTableNames)
+  }
+  usedTables, err := getUsedRtTables(client, newManifest)
+  if err != nil {
+    return errors.New("no way to tell if Spec.Options.rt_tables is already used by another network due to:" + err.Error())
+  }
+  if owner, isUsed := usedTables[rtTable]; isUsed {
+    return errors.New("Spec.Options.rt_tables:" + strconv.Itoa(rtTable) + " is already used by " + owner + ", policy-based routes of different networks cannot share a routing table")
+  }
+  return nil
+}
+
+//Returns the routing tables of all the networks in the cluster except the one under validation, mapped to the network using them
+func getUsedRtTables(client danmclientset.Interface, self *danmtypes.DanmNet) (map[int]string, error) {
+  usedTables := make(map[int]string)
+  networks, err := listNetworks(client)
+  if err != nil {
+    return nil, err
+  }
+  for _, dnet := range networks {
+    if dnet.Spec.Options.RTables == 0 || isSameNetwork(&dnet, self) {
+      continue
+    }
+    usedTables[dnet.Spec.Options.RTables] = getNetworkId(&dnet)
+  }
+  return usedTables, nil
+}
+
+//Returns all the networks of the cluster, their Kind set to the API they belong to
+func listNetworks(client danmclientset.Interface) ([]danmtypes.DanmNet, error) {
+  networks := make([]danmtypes.DanmNet, 0)
+  dnets, err := client.DanmV1().DanmNets("").List(meta_v1.ListOptions{})
+  if err != nil {
+    return nil, err
+  }
+  if dnets != nil {
+    for _, dnet := range dnets.Items {
+      dnet.TypeMeta.Kind = netcontrol.DanmNetKind
+      networks = append(networks, dnet)
+    }
+  }
+  tnets, err := client.DanmV1().TenantNetworks("").List(meta_v1.ListOptions{})
+  if err != nil {
+    return nil, err
+  }
+  if tnets != nil {
+    for index := range tnets.Items {
+      networks = append(networks, *netcontrol.ConvertTnetToDnet(&tnets.Items[index]))
+    }
+  }
+  cnets, err := client.DanmV1().ClusterNetworks().List(meta_v1.ListOptions{})
+  if err != nil {
+    return nil, err
+  }
+  if cnets != nil {
+    for index := range cnets.Items {
+      networks = append(networks, *netcontrol.ConvertCnetToDnet(&cnets.Items[index]))
+    }
+  }
+  return networks, nil
+}
+
+func isSameNetwork(dnet, other *danmtypes.DanmNet) bool {
+  return getNetworkKind(dnet) == getNetworkKind(other) && dnet.ObjectMeta.Namespace == other.ObjectMeta.Namespace && dnet.ObjectMeta.Name == other.ObjectMeta.Name
+}
+
+func getNetworkKind(dnet *danmtypes.DanmNet) string {
+  if dnet.TypeMeta.Kind == "" {
+    return netcontrol.DanmNetKind
+  }
+  return dnet.TypeMeta.Kind
+}
+
+func getNetworkId(dnet *danmtypes.DanmNet) string {
+  return getNetworkKind(dnet) + ":" + dnet.ObjectMeta.Namespace + "/" + dnet.ObjectMeta.Name
+}
+
+//Networks are rejected if their host interfaces would share the name with the ones of another network connecting to a different L2 domain,
+//or if they connect to the same L2 domain as another network of the same scope, and their subnets overlap
+func validateNetworkConflicts(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation, client danmclientset.Interface) error {
+  if opType == admissionv1.Update && !isL2ConfigChanged(oldManifest, newManifest) {
+    return nil
+  }
+  networks, err := listNetworks(client)
+  if err != nil {
+    return errors.New("no way to tell if the network conflicts with existing networks due to:" + err.Error())
+  }
+  hostLinks := getHostLinks(newManifest)
+  l2Domain := getL2Domain(newManifest)
+  for _, dnet := range networks {
+    if isSameNetwork(&dnet, newManifest) {
+      continue
+    }
+    for linkName, otherL2Domain := range getHostLinks(&dnet) {
+      if myL2Domain, isShared := hostLinks[linkName]; isShared && myL2Domain != otherL2Domain {
+        return errors.New("host interface:" + linkName + " of the network would be shared with " + getNetworkId(&dnet) +
+          ", but it connects to L2 domain:" + myL2Domain + " instead of:" + otherL2Domain + ". Spec.NetworkID must be changed!")
+      }
+    }
+    if l2Domain == "" || l2Domain != getL2Domain(&dnet) || !isInSameScope(newManifest, &dnet) {
+      continue
+    }
+    if doSubnetsOverlap(newManifest.Spec.Options.Cidr, dnet.Spec.Options.Cidr) {
+      return errors.New("Spec.Options.cidr:" + newManifest.Spec.Options.Cidr + " overlaps with cidr:" + dnet.Spec.Options.Cidr + " of " + getNetworkId(&dnet) +
+        ", which connects to the same L2 domain:" + l2Domain)
+    }
+    if doSubnetsOverlap(newManifest.Spec.Options.Net6, dnet.Spec.Options.Net6) {
+      return errors.New("Spec.Options.net6:" + newManifest.Spec.Options.Net6 + " overlaps with net6:" + dnet.Spec.Options.Net6 + " of " + getNetworkId(&dnet) +
+        ", which connects to the same L2 domain:" + l2Domain)
+    }
+  }
+  return nil
+}
+
+func isL2ConfigChanged(oldManifest, newManifest *danmtypes.DanmNet) bool {
+  oldOpts, newOpts := oldManifest.Spec.Options, newManifest.Spec.Options
+  return oldManifest.Spec.NetworkID != newManifest.Spec.NetworkID || !strings.EqualFold(oldManifest.Spec.NetworkType, newManifest.Spec.NetworkType) ||
+    oldOpts.Device != newOpts.Device || oldOpts.DevicePool != newOpts.DevicePool || oldOpts.Vlan != newOpts.Vlan || oldOpts.Vxlan != newOpts.Vxlan ||
+    oldOpts.Cidr != newOpts.Cidr || oldOpts.Net6 != newOpts.Net6
+}
+
+//Returns the names of the host interfaces netwatcher creates for the network, mapped to the L2 domain they connect to
+func getHostLinks(dnet *danmtypes.DanmNet) map[string]string {
+  hostLinks := make(map[string]string)
+  if dnet.Spec.Options.Device != "" && !netcontrol.IsOvsNetwork(dnet) {
+    if dnet.Spec.Options.Vxlan != 0 {
+      hostLinks["vx_" + dnet.Spec.NetworkID] = getL2Domain(dnet)
+    }
+    if dnet.Spec.Options.Vlan != 0 {
+      hostLinks[dnet.Spec.NetworkID + "." + strconv.Itoa(dnet.Spec.Options.Vlan)] = getL2Domain(dnet)
+    }
+  }
+  if netcontrol.IsBridgeNetwork(dnet) {
+    hostLinks[netcontrol.GetBridgeName(dnet)] = getL2Domain(dnet)
+  }
+  return hostLinks
+}
+
+//The L2 domain of a network is identified by its parent device, and VNI. Bridges without VLAN, or VxLAN uplink are local to the host, and to the networks sharing the bridge.
+//Networks whose Pods are not connected to a shared L2 domain (e.g. routed networks, or static delegates without a host device) return an empty string
+func getL2Domain(dnet *danmtypes.DanmNet) string {
+  opts := dnet.Spec.Options
+  if netcontrol.IsRoutedNetwork(dnet) {
+    return ""
+  }
+  if netcontrol.IsBridgeNetwork(dnet) && (opts.Device == "" || (opts.Vlan == 0 && opts.Vxlan == 0)) {
+    return netcontrol.GetBridgeName(dnet)
+  }
+  parent := opts.Device
+  if parent == "" {
+    parent = opts.DevicePool
+  }
+  if parent == "" {
+    return ""
+  }
+  if opts.Vxlan != 0 {
+    return parent + " vxlan:" + strconv.Itoa(opts.Vxlan)
+  }
+  if opts.Vlan != 0 {
+    return parent + " vlan:" + strconv.Itoa(opts.Vlan)
+  }
+  return parent
+}
+
+//Only networks of the same scope are compared: namespaced networks of the same namespace, and ClusterNetworks with each other
+//The same network is intentionally defined in multiple namespaces in many deployments, so namespaced networks of different namespaces are not compared
+func isInSameScope(dnet, other *danmtypes.DanmNet) bool {
+  isClusterWide := getNetworkKind(dnet) == netcontrol.ClusterNetworkKind
+  isOtherClusterWide := getNetworkKind(other) == netcontrol.ClusterNetworkKind
+  if isClusterWide || isOtherClusterWide {
+    return isClusterWide && isOtherClusterWide
+  }
+  return dnet.ObjectMeta.Namespace == other.ObjectMeta.Namespace
+}
+
+func doSubnetsOverlap(cidr, otherCidr string) bool {
+  _, subnet, err := net.ParseCIDR(cidr)
+  if err != nil {
+    return false
+  }
+  _, otherSubnet, err := net.ParseCIDR(otherCidr)
+  if err != nil {
+    return false
+  }
+  return subnet.Contains(otherSubnet.IP) || otherSubnet.Contains(subnet.IP)
+}
+
+func validateSysctls(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation, client danmclientset.Interface) error {
+  err := danmep.ValidateSysctls(newManifest.Spec.Options.Sysctls)
+  if err != nil {
+    return errors.New("Spec.Options.sysctls is invalid:" + err.Error())
+  }
+  return nil
+}
+
+func validateBandwidth(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation, client danmclientset.Interface) error {
+  err := danmep.ValidateBandwidth(newManifest.Spec.Options.Bandwidth)
+  if err != nil {
+    return errors.New("Spec.Options.bandwidth is invalid:" + err.Error())
+  }
+  return nil
+}
@@ -1,13 +1,22 @@
 package cnidel
 
 import (
+  "bytes"
   "errors"
   "encoding/json"
   "io/ioutil"
+  "os"
+  "strings"
+  "path/filepath"
+  "text/template"
+  "github.com/vishvananda/netlink"
   danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
   "github.com/nokia/danm/pkg/danmep"
   "github.com/nokia/danm/pkg/datastructs"
+  "github.com/nokia/danm/pkg/logger"
   sriov_utils "github.com/intel/sriov-cni/pkg/utils"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  "k8s.io/client-go/kubernetes"
 )
 
 //This function creates CNI configuration for all static-level backends
@@ -18,12 +27,91 @@ func readCniConfigFile(cniconfDir string, netInfo *danmtypes.DanmNet, ipamOption
   if err != nil {
     return nil, errors.New("Could not load CNI config file: " + cniConfig +".conf for plugin:" + netInfo.Spec.NetworkType + " from directory:" + cniconfDir)
   }
-  //Only overwrite "ipam" of the static CNI config if user wants
+  return overwriteIpamConfig(rawConfig, netInfo, ipamOptions)
+}
+
+//This function creates CNI configuration for static-level backends whose configuration is stored in a ConfigMap
+//The configuration is stored under the key matching with the NetworkID parameter
+//Every successfully read configuration is cached on the node, so DEL can succeed even if the API server is unavailable
+func readCniConfigMap(netConf *datastructs.NetConf, k8sClient kubernetes.Interface, cniOpType string, netInfo *danmtypes.DanmNet, ipamOptions datastructs.IpamConfig) ([]byte, error) {
+  cmNamespace, cmName := splitCniConfigMapRef(getCniConfigMapRef(netConf, netInfo), netInfo.ObjectMeta.Namespace)
+  if cmNamespace == "" {
+    return nil, errors.New("namespace of the CNI config ConfigMap:" + cmName + " of network:" + netInfo.ObjectMeta.Name + " cannot be determined")
+  }
+  cmKey := netInfo.Spec.NetworkID + ".conf"
+  cacheFile := filepath.Join(getCniConfigCacheDir(netConf), cmNamespace + "_" + cmName + "_" + cmKey)
+  rawConfig, err := getConfigFromConfigMap(k8sClient, cmNamespace, cmName, cmKey)
+  if err != nil {
+    if cniOpType != CniDelOp {
+      return nil, err
+    }
+    logger.WithFields(logger.Fields{logger.NetworkField: netInfo.ObjectMeta.Name}).Info("DEL: using cached CNI config of network:" + netInfo.ObjectMeta.Name + " because:" + err.Error())
+    rawConfig, err = ioutil.ReadFile(cacheFile)
+    if err != nil {
+      return nil, errors.New("CNI config could not be read neither from ConfigMap, nor from the node-local cache:" + err.Error())
+    }
+  } else {
+    err = cacheCniConfig(cacheFile, rawConfig)
+    if err != nil {
+      logger.WithFields(logger.Fields{logger.NetworkField: netInfo.ObjectMeta.Name}).Info("CNI config of network:" + netInfo.ObjectMeta.Name + " could not be cached because:" + err.Error())
+    }
+  }
+  return overwriteIpamConfig(rawConfig, netInfo, ipamOptions)
+}
+
+func getCniConfigMapRef(netConf *datastructs.NetConf, netInfo *danmtypes.DanmNet) string {
+  if netInfo.Spec.Options.CniConfigMap != "" {
+    return netInfo.Spec.Options.CniConfigMap
+  }
+  return netConf.CniConfigMap
+}
+
+func splitCniConfigMapRef(cmRef, defaultNamespace string) (string,string) {
+  if strings.Contains(cmRef, "/") {
+    refParts := strings.SplitN(cmRef, "/", 2)
+    return refParts[0], refParts[1]
+  }
+  return defaultNamespace, cmRef
+}
+
+func getCniConfigCacheDir(netConf *datastructs.NetConf) string {
+  if netConf.CniConfigCacheDir != "" {
+    return netConf.CniConfigCacheDir
+  }
+  return DefaultCniConfigCacheDir
+}
+
+func getConfigFromConfigMap(k8sClient kubernetes.Interface, cmNamespace, cmName, cmKey string) ([]byte, error) {
+  if k8sClient == nil {
+    return nil, errors.New("there is no K8s client to read ConfigMap:" + cmNamespace + "/" + cmName + " with")
+  }
+  configMap, err := k8sClient.CoreV1().ConfigMaps(cmNamespace).Get(cmName, meta_v1.GetOptions{})
+  if err != nil {
+    return nil, errors.New("ConfigMap:" + cmNamespace + "/" + cmName + " could not be read because:" + err.Error())
+  }
+  rawConfig, ok := configMap.Data[cmKey]
+  if !ok {
+    return nil, errors.New("ConfigMap:" + cmNamespace + "/" + cmName + " does not contain key:" + cmKey)
+  }
+  return []byte(rawConfig), nil
+}
+
+func cacheCniConfig(cacheFile string, rawConfig []byte) error {
+  err := os.MkdirAll(filepath.Dir(cacheFile), 0700)
+  if err != nil {
+    return err
+  }
+  return ioutil.WriteFile(cacheFile, rawConfig, 0600)
+}
+
+//Only overwrite "ipam" of the static CNI config if user wants
+func overwriteIpamConfig(rawConfig []byte, netInfo *danmtypes.DanmNet, ipamOptions datastructs.IpamConfig) ([]byte, error) {
   if len(ipamOptions.Ips) > 0 {
+    cniConfig := netInfo.Spec.NetworkID
     genericCniConf := map[string]interface{}{}
-    err = json.Unmarshal(rawConfig, &genericCniConf)
+    err := json.Unmarshal(rawConfig, &genericCniConf)
     if err != nil {
-      return nil, errors.New("could not Unmarshal CNI config file:" + cniConfig + ".conf for plugin: " + netInfo.Spec.NetworkType + ", because:" + err.Error())
+      return nil, errors.New("could not Unmarshal CNI config:" + cniConfig + ".conf for plugin: " + netInfo.Spec.NetworkType + ", because:" + err.Error())
     }
     ipamRaw,_ := json.Marshal(ipamOptions)
     ipamInGenericFormat := map[string]interface{}{}
@@ -48,6 +136,13 @@ func getSriovCniConfig(netInfo *danmtypes.DanmNet, ipamOptions datastructs.IpamC
   sriovConfig.Master   = pfname
   sriovConfig.Vlan     = netInfo.Spec.Options.Vlan
   sriovConfig.DeviceID = ep.Spec.Iface.DeviceID
+  sriovConfig.MAC       = ep.Spec.Iface.MacAddress
+  sriovConfig.VlanQoS   = netInfo.Spec.Options.VlanQos
+  sriovConfig.SpoofChk  = netInfo.Spec.Options.SpoofChk
+  sriovConfig.Trust     = netInfo.Spec.Options.Trust
+  sriovConfig.LinkState = netInfo.Spec.Options.LinkState
+  sriovConfig.MinTxRate = netInfo.Spec.Options.MinTxRate
+  sriovConfig.MaxTxRate = netInfo.Spec.Options.MaxTxRate
   if len(ipamOptions.Ips) > 0 {
     sriovConfig.Ipam   = ipamOptions
   }
@@ -77,3 +172,86 @@ func getMacvlanCniConfig(netInfo *danmtypes.DanmNet, ipamOptions datastructs.Ipa
   }
   return rawConfig, nil
 }
+
+//This function creates CNI configuration for the dynamic-level host-device backend
+func getHostDeviceCniConfig(netInfo *danmtypes.DanmNet, ipamOptions datastructs.IpamConfig, ep *danmtypes.DanmEp, cniVersion string) ([]byte, error) {
+  var hostDeviceConfig HostDeviceNet
+  // initialize common fields of "github.com/containernetworking/cni/pkg/types".NetConf
+  hostDeviceConfig.CNIVersion = cniVersion
+  hostDeviceConfig.Name       = netInfo.Spec.NetworkID
+  hostDeviceConfig.Type       = "host-device"
+  // initialize HostDeviceNet specific fields:
+  hostDeviceConfig.PciBusID   = ep.Spec.Iface.DeviceID
+  if len(ipamOptions.Ips) > 0 {
+    hostDeviceConfig.Ipam   = ipamOptions
+  }
+  rawConfig, err := json.Marshal(hostDeviceConfig)
+  if err != nil {
+    return nil, errors.New("Error putting together CNI config for host-device plugin: " + err.Error())
+  }
+  return rawConfig, nil
+}
+
+func newTemplatedBackend(cniTemplate *danmtypes.CniTemplate) *datastructs.CniBackendConfig {
+  cniVersion := cniTemplate.Spec.CniVersion
+  if cniVersion == "" {
+    cniVersion = DefaultCniVersion
+  }
+  return &datastructs.CniBackendConfig {
+    CNIVersion: cniVersion,
+    ReadConfig: datastructs.CniConfigReader(func(netInfo *danmtypes.DanmNet, ipamOptions datastructs.IpamConfig, ep *danmtypes.DanmEp, cniVersion string) ([]byte, error) {
+      return renderCniTemplate(cniTemplate, netInfo, ipamOptions, ep, cniVersion)
+    }),
+    IpamNeeded: cniTemplate.Spec.IpamNeeded,
+    DeviceNeeded: cniTemplate.Spec.DeviceNeeded,
+  }
+}
+
+//This function creates CNI configuration for dynamic-level backends defined via CniTemplate API objects
+//The placeholders of the template are substituted with the attributes of the network, and the interface
+func renderCniTemplate(cniTemplate *danmtypes.CniTemplate, netInfo *danmtypes.DanmNet, ipamOptions datastructs.IpamConfig, ep *danmtypes.DanmEp, cniVersion string) ([]byte, error) {
+  tmpl, err := template.New(cniTemplate.ObjectMeta.Name).Option("missingkey=error").Parse(cniTemplate.Spec.Template)
+  if err != nil {
+    return nil, errors.New("CniTemplate:" + cniTemplate.ObjectMeta.Name + " could not be parsed because:" + err.Error())
+  }
+  ipamConfig := []byte("{}")
+  if len(ipamOptions.Ips) > 0 {
+    ipamConfig, _ = json.Marshal(ipamOptions)
+  }
+  master := danmep.DetermineHostDeviceName(netInfo)
+  if master == "" && ep.Spec.Iface.DeviceID != "" {
+    master, _ = sriov_utils.GetPfName(ep.Spec.Iface.DeviceID)
+  }
+  values := CniTemplateValues {
+    CniVersion: cniVersion,
+    NetworkName: netInfo.ObjectMeta.Name,
+    NetworkID: netInfo.Spec.NetworkID,
+    Device: netInfo.Spec.Options.Device,
+    Master: master,
+    Vlan: netInfo.Spec.Options.Vlan,
+    Vxlan: netInfo.Spec.Options.Vxlan,
+    DeviceID: ep.Spec.Iface.DeviceID,
+    Ipam: string(ipamConfig),
+    Mtu: getLinkMtu(master),
+  }
+  var rawConfig bytes.Buffer
+  err = tmpl.Execute(&rawConfig, values)
+  if err != nil {
+    return nil, errors.New("CniTemplate:" + cniTemplate.ObjectMeta.Name + " could not be rendered because:" + err.Error())
+  }
+  if !json.Valid(rawConfig.Bytes()) {
+    return nil, errors.New("CniTemplate:" + cniTemplate.ObjectMeta.Name + " was rendered into an invalid JSON:" + rawConfig.String())
+  }
+  return rawConfig.Bytes(), nil
+}
+
+func getLinkMtu(linkName string) int {
+  if linkName == "" {
+    return DefaultMtu
+  }
+  link, err := netlink.LinkByName(linkName)
+  if err != nil || link.Attrs().MTU == 0 {
+    return DefaultMtu
+  }
+  return link.Attrs().MTU
+}
@@ -3,7 +3,6 @@ package cnidel
 import (
   "context"
   "errors"
-  "log"
   "os"
   "strings"
   "path/filepath"
@@ -11,9 +10,14 @@ import (
   "github.com/containernetworking/cni/pkg/types"
   "github.com/containernetworking/cni/pkg/types/current"
   "github.com/containernetworking/cni/pkg/version"
+  k8serrors "k8s.io/apimachinery/pkg/api/errors"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  "k8s.io/client-go/kubernetes"
   "github.com/nokia/danm/pkg/datastructs"
   "github.com/nokia/danm/pkg/ipam"
+  "github.com/nokia/danm/pkg/logger"
   danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
 )
 
 const (
@@ -30,17 +34,18 @@ var (
 // IsDelegationRequired decides if the interface creation operations should be delegated to a 3rd party CNI, or can be handled by DANM
 // Decision is made based on the NetworkType parameter of the network object
 func IsDelegationRequired(netInfo *danmtypes.DanmNet) bool {
-  neType := strings.ToLower(netInfo.Spec.NetworkType)
-  if neType == "ipvlan" || neType == "" {
-    return false
-  }
-  return true
+  return !isDanmNativeType(strings.ToLower(netInfo.Spec.NetworkType))
+}
+
+func isDanmNativeType(neType string) bool {
+  return neType == "ipvlan" || neType == "" || neType == "bridge" || neType == "routed" || neType == "ovs"
 }
 
 // DelegateInterfaceSetup delegates K8s Pod network interface setup task to the input 3rd party CNI plugin
 // Returns the CNI compatible result object, or an error if interface creation was unsuccessful, or if the 3rd party CNI config could not be loaded
 //TODO: I hate myself for the bool input parameter, but that's what we are going with for the time being. Could be this information cleverly defaulted from existing DanmEp spec in all cases?
-func DelegateInterfaceSetup(netConf *datastructs.NetConf, wasIpReservedByDanmIpam bool, netInfo *danmtypes.DanmNet, ep *danmtypes.DanmEp) (*current.Result,error) {
+//The backend of the network shall be resolved by GetBackendConfig beforehand
+func DelegateInterfaceSetup(ctx context.Context, netConf *datastructs.NetConf, cni *datastructs.CniBackendConfig, k8sClient kubernetes.Interface, wasIpReservedByDanmIpam bool, netInfo *danmtypes.DanmNet, ep *danmtypes.DanmEp) (*current.Result,error) {
   var (
     err error
     ipamOptions datastructs.IpamConfig
@@ -48,13 +53,22 @@ func DelegateInterfaceSetup(netConf *datastructs.NetConf, wasIpReservedByDanmIpa
   if wasIpReservedByDanmIpam {
     ipamOptions = getCniIpamConfig(netInfo, ep.Spec.Iface.Address, ep.Spec.Iface.AddressIPv6)
   }
-  rawConfig, err := getCniPluginConfig(netConf, netInfo, ipamOptions, ep)
+  rawConfig, err := getCniPluginConfig(netConf, cni, k8sClient, CniAddOp, netInfo, ipamOptions, ep)
   if err != nil {
     return nil, err
   }
   cniType := netInfo.Spec.NetworkType
-  cniResult,err := execCniPlugin(cniType, CniAddOp, netInfo, rawConfig, ep)
+  if isHostDeviceType(strings.ToLower(cniType)) {
+    err = saveHostDeviceState(ep.Spec.Iface.DeviceID)
+    if err != nil {
+      return nil, err
+    }
+  }
+  cniResult,err := execCniPlugin(ctx, cniType, CniAddOp, netInfo, rawConfig, ep)
   if err != nil {
+    if isHostDeviceType(strings.ToLower(cniType)) {
+      rollbackHostDeviceState(ep.Spec.Iface.DeviceID)
+    }
     return nil, errors.New("Error delegating ADD to CNI plugin:" + cniType + " because:" + err.Error())
   }
   if cniResult != nil {
@@ -63,8 +77,8 @@ func DelegateInterfaceSetup(netConf *datastructs.NetConf, wasIpReservedByDanmIpa
   return cniResult, nil
 }
 
-func IsDanmIpamNeededForDelegation(iface datastructs.Interface, netInfo *danmtypes.DanmNet) bool {
-  if cni, ok := SupportedNativeCnis[strings.ToLower(netInfo.Spec.NetworkType)]; ok {
+func IsDanmIpamNeededForDelegation(cni *datastructs.CniBackendConfig, iface datastructs.Interface, netInfo *danmtypes.DanmNet) bool {
+  if cni != nil {
     return cni.IpamNeeded
   }
   //For static delegates we should only overwrite the original IPAM if an IP was explicitly "requested" from the Pod, and the request "makes sense"
@@ -81,12 +95,29 @@ func IsDanmIpamNeededForDelegation(iface datastructs.Interface, netInfo *danmtyp
   return false
 }
 
-func IsDeviceNeeded(cniType string) bool {
-  if cni, ok := SupportedNativeCnis[strings.ToLower(cniType)]; ok {
-    return cni.DeviceNeeded
-  } else {
-    return false
+func IsDeviceNeeded(cni *datastructs.CniBackendConfig) bool {
+  return cni != nil && cni.DeviceNeeded
+}
+
+// GetBackendConfig returns the configuration of the dynamic backend handling the given NetworkType.
+// Natively supported backends take precedence, otherwise the CniTemplate named after the NetworkType is used.
+// A nil config is returned for static backends, i.e. when neither exists
+func GetBackendConfig(danmClient danmclientset.Interface, cniType string) (*datastructs.CniBackendConfig,error) {
+  neType := strings.ToLower(cniType)
+  if cni, ok := SupportedNativeCnis[neType]; ok {
+    return cni, nil
+  }
+  if danmClient == nil || isDanmNativeType(neType) {
+    return nil, nil
+  }
+  template, err := danmClient.DanmV1().CniTemplates().Get(neType, meta_v1.GetOptions{})
+  if k8serrors.IsNotFound(err) || (err == nil && template == nil) {
+    return nil, nil
   }
+  if err != nil {
+    return nil, errors.New("CniTemplate:" + neType + " could not be read from the API server because:" + err.Error())
+  }
+  return newTemplatedBackend(template), nil
 }
 
 func getCniIpamConfig(netinfo *danmtypes.DanmNet, ip4, ip6 string) datastructs.IpamConfig {
@@ -109,21 +140,23 @@ func getCniIpamConfig(netinfo *danmtypes.DanmNet, ip4, ip6 string) datastructs.I
           }
 }
 
-func getCniPluginConfig(netConf *datastructs.NetConf, netInfo *danmtypes.DanmNet, ipamOptions datastructs.IpamConfig, ep *danmtypes.DanmEp) ([]byte, error) {
-  if cni, ok := SupportedNativeCnis[strings.ToLower(netInfo.Spec.NetworkType)]; ok {
+func getCniPluginConfig(netConf *datastructs.NetConf, cni *datastructs.CniBackendConfig, k8sClient kubernetes.Interface, cniOpType string, netInfo *danmtypes.DanmNet, ipamOptions datastructs.IpamConfig, ep *danmtypes.DanmEp) ([]byte, error) {
+  if cni != nil {
     return cni.ReadConfig(netInfo, ipamOptions, ep, cni.CNIVersion)
-  } else {
-    return readCniConfigFile(netConf.CniConfigDir, netInfo, ipamOptions)
   }
+  if getCniConfigMapRef(netConf, netInfo) != "" {
+    return readCniConfigMap(netConf, k8sClient, cniOpType, netInfo, ipamOptions)
+  }
+  return readCniConfigFile(netConf.CniConfigDir, netInfo, ipamOptions)
 }
 
-func execCniPlugin(cniType, cniOpType string, netInfo *danmtypes.DanmNet, rawConfig []byte, ep *danmtypes.DanmEp) (*current.Result,error) {
+func execCniPlugin(ctx context.Context, cniType, cniOpType string, netInfo *danmtypes.DanmNet, rawConfig []byte, ep *danmtypes.DanmEp) (*current.Result,error) {
   cniPath, cniArgs, err := getExecCniParams(cniType, cniOpType, netInfo, ep)
   if err != nil {
     return nil, errors.New("exec CNI params couldn't be gathered:" + err.Error())
   }
   exec := invoke.RawExec{Stderr: os.Stderr}
-  rawResult, err := exec.ExecPlugin(context.Background(), cniPath, rawConfig, cniArgs)
+  rawResult, err := exec.ExecPlugin(ctx, cniPath, rawConfig, cniArgs)
   if err != nil {
     return nil, errors.New("OS exec call failed:" + err.Error())
   }
@@ -171,7 +204,7 @@ func setEpIfaceAddress(cniResult *current.Result, epIface *danmtypes.DanmEpIface
 
 // DelegateInterfaceDelete delegates Ks8 Pod network interface delete task to the input 3rd party CNI plugin
 // Returns an error if interface creation was unsuccessful, or if the 3rd party CNI config could not be loaded
-func DelegateInterfaceDelete(netConf *datastructs.NetConf, netInfo *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
+func DelegateInterfaceDelete(ctx context.Context, netConf *datastructs.NetConf, danmClient danmclientset.Interface, k8sClient kubernetes.Interface, netInfo *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
   var ip4, ip6 string
   if ipam.WasIpAllocatedByDanm(ep.Spec.Iface.Address, netInfo.Spec.Options.Cidr) {
     ip4 = ep.Spec.Iface.Address
@@ -180,17 +213,29 @@ func DelegateInterfaceDelete(netConf *datastructs.NetConf, netInfo *danmtypes.Da
     ip6 = ep.Spec.Iface.AddressIPv6
   }
   ipamForDelete := getCniIpamConfig(netInfo, ip4, ip6)
-  rawConfig, err := getCniPluginConfig(netConf, netInfo, ipamForDelete, ep)
+  cni, err := GetBackendConfig(danmClient, netInfo.Spec.NetworkType)
+  if err != nil {
+    FreeDelegatedIps(netInfo, ep.Spec.Iface.Address, ep.Spec.Iface.AddressIPv6)
+    return err
+  }
+  rawConfig, err := getCniPluginConfig(netConf, cni, k8sClient, CniDelOp, netInfo, ipamForDelete, ep)
   if err != nil {
     FreeDelegatedIps(netInfo, ep.Spec.Iface.Address, ep.Spec.Iface.AddressIPv6)
     return err
   }
   cniType := netInfo.Spec.NetworkType
-  _, err = execCniPlugin(cniType, CniDelOp, netInfo, rawConfig, ep)
+  _, err = execCniPlugin(ctx, cniType, CniDelOp, netInfo, rawConfig, ep)
   if err != nil {
     FreeDelegatedIps(netInfo, ep.Spec.Iface.Address, ep.Spec.Iface.AddressIPv6)
     return errors.New("Error delegating DEL to CNI plugin:" + cniType + " because:" + err.Error())
   }
+  if isHostDeviceType(strings.ToLower(cniType)) {
+    err = restoreHostDeviceState(ep.Spec.Iface.DeviceID)
+    if err != nil {
+      FreeDelegatedIps(netInfo, ep.Spec.Iface.Address, ep.Spec.Iface.AddressIPv6)
+      return errors.New("host-device:" + ep.Spec.Iface.DeviceID + " could not be restored because:" + err.Error())
+    }
+  }
   return FreeDelegatedIps(netInfo, ep.Spec.Iface.Address, ep.Spec.Iface.AddressIPv6)
 }
 
@@ -225,7 +270,7 @@ func flannelIpExhaustionWorkaround(ip string) {
 func convertCniResult(rawCniResult types.Result) *current.Result {
   convertedResult, err := current.NewResultFromResult(rawCniResult)
   if err != nil {
-    log.Println("Delegated CNI result could not be converted:" + err.Error())
+    logger.Error("Delegated CNI result could not be converted:" + err.Error())
     return nil
   }
   return convertedResult
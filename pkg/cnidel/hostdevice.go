@@ -0,0 +1,181 @@
+package cnidel
+
+import (
+  "encoding/json"
+  "errors"
+  "io/ioutil"
+  "net"
+  "os"
+  "path/filepath"
+  "syscall"
+  "github.com/vishvananda/netlink"
+  sriov_utils "github.com/intel/sriov-cni/pkg/utils"
+  "github.com/nokia/danm/pkg/logger"
+)
+
+// hostDeviceState is the host side configuration of a NIC passed through to a Pod, saved before the NIC leaves the host network namespace
+// The host-device CNI only restores the original name of the NIC when it returns it to the host, its IPs, and routes are lost when it leaves the host
+type hostDeviceState struct {
+  Name      string            `json:"name"`
+  Addresses []string          `json:"addresses,omitempty"`
+  Routes    []hostDeviceRoute `json:"routes,omitempty"`
+  IsUp      bool              `json:"isUp"`
+}
+
+type hostDeviceRoute struct {
+  Destination string `json:"destination"`
+  Gateway     string `json:"gateway,omitempty"`
+  Source      string `json:"source,omitempty"`
+  Table       int    `json:"table,omitempty"`
+  Metric      int    `json:"metric,omitempty"`
+  Scope       int    `json:"scope,omitempty"`
+}
+
+func isHostDeviceType(cniType string) bool {
+  return cniType == "host-device"
+}
+
+func getHostDeviceStateFile(deviceID string) string {
+  return filepath.Join(HostDeviceStateDir, deviceID + ".json")
+}
+
+//This function saves the name, IPs, routes, and state of a NIC to the node-local disk before it is moved into a Pod
+func saveHostDeviceState(deviceID string) error {
+  linkName, err := sriov_utils.GetVFLinkNames(deviceID)
+  if err != nil {
+    //Devices bound to userspace drivers do not have any kernel configuration to restore
+    return nil
+  }
+  link, err := netlink.LinkByName(linkName)
+  if err != nil {
+    return errors.New("cannot find the host interface of Device:" + deviceID + " because:" + err.Error())
+  }
+  addresses, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+  if err != nil {
+    return errors.New("cannot list the IPs of host interface:" + linkName + " because:" + err.Error())
+  }
+  state := hostDeviceState{Name: linkName, IsUp: link.Attrs().Flags & net.FlagUp != 0}
+  for _, address := range addresses {
+    //Link-local IPv6 addresses are regenerated by the kernel anyway
+    if address.IP.IsLinkLocalUnicast() {
+      continue
+    }
+    state.Addresses = append(state.Addresses, address.IPNet.String())
+  }
+  state.Routes, err = getHostDeviceRoutes(link)
+  if err != nil {
+    return errors.New("cannot list the routes of host interface:" + linkName + " because:" + err.Error())
+  }
+  rawState, err := json.Marshal(state)
+  if err != nil {
+    return errors.New("cannot marshal the state of host interface:" + linkName + " because:" + err.Error())
+  }
+  err = os.MkdirAll(HostDeviceStateDir, 0700)
+  if err == nil {
+    err = ioutil.WriteFile(getHostDeviceStateFile(deviceID), rawState, 0600)
+  }
+  if err != nil {
+    return errors.New("cannot save the state of host interface:" + linkName + " because:" + err.Error())
+  }
+  return nil
+}
+
+//Routes the kernel creates on its own for the IPs, and state of the NIC are not saved, they come back together with the IPs
+func getHostDeviceRoutes(link netlink.Link) ([]hostDeviceRoute, error) {
+  var savedRoutes []hostDeviceRoute
+  for family, defaultDst := range map[int]string{netlink.FAMILY_V4: "0.0.0.0/0", netlink.FAMILY_V6: "::/0"} {
+    //Without an explicit table filter only the routes of the main table would be listed
+    routes, err := netlink.RouteListFiltered(family, &netlink.Route{LinkIndex: link.Attrs().Index, Table: syscall.RT_TABLE_UNSPEC}, netlink.RT_FILTER_OIF|netlink.RT_FILTER_TABLE)
+    if err != nil {
+      return nil, err
+    }
+    for _, route := range routes {
+      if route.Protocol == syscall.RTPROT_KERNEL || route.Protocol == syscall.RTPROT_RA || route.Table == syscall.RT_TABLE_LOCAL {
+        continue
+      }
+      savedRoute := hostDeviceRoute{Destination: defaultDst, Table: route.Table, Metric: route.Priority, Scope: int(route.Scope)}
+      if route.Dst != nil {
+        savedRoute.Destination = route.Dst.String()
+      }
+      if route.Gw != nil {
+        savedRoute.Gateway = route.Gw.String()
+      }
+      if route.Src != nil {
+        savedRoute.Source = route.Src.String()
+      }
+      savedRoutes = append(savedRoutes, savedRoute)
+    }
+  }
+  return savedRoutes, nil
+}
+
+//This function restores the name, IPs, routes, and state of a NIC returned to the host by the host-device CNI
+func restoreHostDeviceState(deviceID string) error {
+  stateFile := getHostDeviceStateFile(deviceID)
+  rawState, err := ioutil.ReadFile(stateFile)
+  if os.IsNotExist(err) {
+    return nil
+  } else if err != nil {
+    return errors.New("cannot read the saved state of Device:" + deviceID + " because:" + err.Error())
+  }
+  var state hostDeviceState
+  err = json.Unmarshal(rawState, &state)
+  if err != nil {
+    return errors.New("cannot unmarshal the saved state of Device:" + deviceID + " because:" + err.Error())
+  }
+  linkName, err := sriov_utils.GetVFLinkNames(deviceID)
+  if err != nil {
+    return errors.New("Device:" + deviceID + " was not returned to the host network namespace")
+  }
+  link, err := netlink.LinkByName(linkName)
+  if err != nil {
+    return errors.New("cannot find the host interface of Device:" + deviceID + " because:" + err.Error())
+  }
+  if linkName != state.Name {
+    netlink.LinkSetDown(link)
+    err = netlink.LinkSetName(link, state.Name)
+    if err != nil {
+      return errors.New("cannot rename host interface:" + linkName + " to:" + state.Name + " because:" + err.Error())
+    }
+  }
+  for _, address := range state.Addresses {
+    addr, err := netlink.ParseAddr(address)
+    if err != nil {
+      return errors.New("cannot parse saved IP:" + address + " of host interface:" + state.Name + " because:" + err.Error())
+    }
+    err = netlink.AddrAdd(link, addr)
+    if err != nil && err != syscall.EEXIST {
+      return errors.New("cannot restore IP:" + address + " of host interface:" + state.Name + " because:" + err.Error())
+    }
+  }
+  if state.IsUp {
+    err = netlink.LinkSetUp(link)
+    if err != nil {
+      return errors.New("cannot set host interface:" + state.Name + " UP because:" + err.Error())
+    }
+  }
+  //Routes can only be added once the IPs, and the state of the NIC are restored
+  for _, savedRoute := range state.Routes {
+    _, dst, err := net.ParseCIDR(savedRoute.Destination)
+    if err != nil {
+      return errors.New("cannot parse saved route:" + savedRoute.Destination + " of host interface:" + state.Name + " because:" + err.Error())
+    }
+    route := netlink.Route{LinkIndex: link.Attrs().Index, Dst: dst, Table: savedRoute.Table, Priority: savedRoute.Metric, Scope: netlink.Scope(savedRoute.Scope)}
+    route.Gw = net.ParseIP(savedRoute.Gateway)
+    route.Src = net.ParseIP(savedRoute.Source)
+    err = netlink.RouteReplace(&route)
+    if err != nil {
+      return errors.New("cannot restore route:" + savedRoute.Destination + " of host interface:" + state.Name + " because:" + err.Error())
+    }
+  }
+  return os.Remove(stateFile)
+}
+
+//The host-device CNI might fail before, or after moving the NIC into the Pod, so its state is restored when it is still on the host.
+//The saved state is kept otherwise, so the original configuration of the NIC can still be recovered
+func rollbackHostDeviceState(deviceID string) {
+  err := restoreHostDeviceState(deviceID)
+  if err != nil {
+    logger.Warning("saved state of Device:" + deviceID + " is kept in:" + getHostDeviceStateFile(deviceID) + ", because it could not be restored:" + err.Error())
+  }
+}
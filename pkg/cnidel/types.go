@@ -6,6 +6,13 @@ import (
   "github.com/nokia/danm/pkg/datastructs"
 )
 
+const (
+  DefaultCniVersion = "0.3.1"
+  DefaultMtu = 1500
+  DefaultCniConfigCacheDir = "/var/lib/danm/cniconfs"
+  HostDeviceStateDir = "/var/lib/danm/hostdevices"
+)
+
 var(
   SupportedNativeCnis = map[string]*datastructs.CniBackendConfig {
     "sriov": &datastructs.CniBackendConfig {
@@ -20,12 +27,31 @@ var(
       IpamNeeded: true,
       DeviceNeeded: false,
     },
+    "host-device": &datastructs.CniBackendConfig {
+      CNIVersion: "0.3.1",
+      ReadConfig: datastructs.CniConfigReader(getHostDeviceCniConfig),
+      IpamNeeded: true,
+      DeviceNeeded: true,
+    },
   }
 )
 
 // sriovNet represent the configuration of sriov cni v1.0.0
 type SriovNet struct {
   sriov_types.NetConf
+  // Fixed MAC address of the VF
+  MAC       string `json:"mac,omitempty"`
+  // 802.1p priority of the VLAN tag
+  VlanQoS   int    `json:"vlanQoS,omitempty"`
+  // Spoof checking of the VF ("on" or "off")
+  SpoofChk  string `json:"spoofchk,omitempty"`
+  // Trusted mode of the VF ("on" or "off")
+  Trust     string `json:"trust,omitempty"`
+  // Link state of the VF ("auto", "enable" or "disable")
+  LinkState string `json:"link_state,omitempty"`
+  // TX rate limits of the VF in Mbps
+  MinTxRate int    `json:"min_tx_rate,omitempty"`
+  MaxTxRate int    `json:"max_tx_rate,omitempty"`
   // IPAM configuration to be used for this network
   Ipam   datastructs.IpamConfig `json:"ipam,omitEmpty"`
 }
@@ -41,3 +67,30 @@ type MacvlanNet struct {
   //IPAM configuration to be used for this network
   Ipam   datastructs.IpamConfig `json:"ipam,omitEmpty"`
 }
+
+type HostDeviceNet struct {
+  types.NetConf
+  //PCI address of the NIC moved into the Pod
+  PciBusID string `json:"pciBusID"`
+  //IPAM configuration to be used for this network
+  Ipam     datastructs.IpamConfig `json:"ipam,omitEmpty"`
+}
+
+// CniTemplateValues are the values substituted into the placeholders of CniTemplates
+type CniTemplateValues struct {
+  CniVersion  string
+  NetworkName string
+  NetworkID   string
+  //host_device of the network
+  Device      string
+  //Name of the host interface the Pod interface is connected to, i.e. the VLAN, or VxLAN interface if the network has any
+  Master      string
+  Vlan        int
+  Vxlan       int
+  //PCI address of the Device allocated to the interface
+  DeviceID    string
+  //JSON encoded IPAM configuration, {} if DANM did not allocate any IPs
+  Ipam        string
+  //MTU of the Master interface, 1500 if it does not exist
+  Mtu         int
+}
@@ -2,12 +2,12 @@ package confman
 
 import (
   "errors"
-  "log"
   "strings"
   danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
   danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
   "github.com/nokia/danm/pkg/bitarray"
   "github.com/nokia/danm/pkg/datastructs"
+  "github.com/nokia/danm/pkg/logger"
   metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
   "k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 )
@@ -76,6 +76,25 @@ func reserveVni(iface danmtypes.IfaceProfile) (int,string,error) {
   return chosenVni, allocs.Encode(), nil
 }
 
+// GetReservedVnis decodes the VNI allocation bitmap of an interface profile, and returns the reserved VNIs of its range, and the size of the range
+func GetReservedVnis(iface danmtypes.IfaceProfile) ([]int,int,error) {
+  reservedVnis := make([]int, 0)
+  if iface.VniType == "" {
+    return reservedVnis, 0, nil
+  }
+  vnis, err := cpuset.Parse(iface.VniRange)
+  if err != nil {
+    return nil, 0, errors.New("vniRange for interface:" + iface.Name + " cannot be parsed because:" + err.Error())
+  }
+  allocs := bitarray.NewBitArrayFromBase64(iface.Alloc)
+  for _, vni := range vnis.ToSlice() {
+    if uint32(vni) < allocs.Len() && allocs.Get(uint32(vni)) {
+      reservedVnis = append(reservedVnis, vni)
+    }
+  }
+  return reservedVnis, vnis.Size(), nil
+}
+
 func getIfaceIndex(tconf *danmtypes.TenantConfig, name, vniType string) int {
   for index, iface := range tconf.HostDevices {
     //As HostDevices is a list, the same interface might be added multiple types but with different VNI types
@@ -102,7 +121,7 @@ func Free(danmClient danmclientset.Interface, tconf *danmtypes.TenantConfig, dne
   for {
     index := getIfaceIndex(tconf,ifaceName,vniType)
     if index < 0 {
-      log.Println("WARNING: There is a data incosistency between TenantNetwork:" + dnet.ObjectMeta.Name + " in namespace:" +
+      logger.WithFields(logger.Fields{logger.NetworkField: dnet.ObjectMeta.Name, logger.NamespaceField: dnet.ObjectMeta.Namespace}).Warning("There is a data incosistency between TenantNetwork:" + dnet.ObjectMeta.Name + " in namespace:" +
       dnet.ObjectMeta.Namespace + " , and TenantConfig:" + tconf.ObjectMeta.Name +
       " as the used network details (interface name, VNI type) doe not match any entries in TenantConfig. This means your APIs were possibly tampered with!")
       return nil
@@ -137,6 +156,79 @@ func freeVni(dnet *danmtypes.DanmNet, iface danmtypes.IfaceProfile) (string,erro
   return allocs.Encode(), nil
 }
 
+// ReserveRtTable allocates a routing table ID from the rtTableRange of the TenantConfig
+// IDs present in usedTables are skipped, as they are already manually configured for some networks
+func ReserveRtTable(danmClient danmclientset.Interface, tconf *danmtypes.TenantConfig, usedTables map[int]string) (int,error) {
+  for {
+    chosenTable, newAlloc, err := reserveRtTable(tconf, usedTables)
+    if err != nil {
+      return 0, err
+    }
+    tconf.RtTableAlloc = newAlloc
+    newConf, wasRefreshed, err := updateTenantConf(danmClient, tconf)
+    if err != nil {
+      return chosenTable, err
+    }
+    if wasRefreshed {
+      tconf = newConf
+      continue
+    }
+    return chosenTable, nil
+  }
+}
+
+func reserveRtTable(tconf *danmtypes.TenantConfig, usedTables map[int]string) (int,string,error) {
+  allocs := bitarray.NewBitArrayFromBase64(tconf.RtTableAlloc)
+  if allocs.Len() == 0 {
+    return 0, "", errors.New("routing table allocations of TenantConfig:" + tconf.ObjectMeta.Name + " is corrupt! Are you running without webhook?")
+  }
+  tables, err := cpuset.Parse(tconf.RtTableRange)
+  if err != nil {
+    return 0, "", errors.New("rtTableRange of TenantConfig:" + tconf.ObjectMeta.Name + " cannot be parsed because:" + err.Error())
+  }
+  for _, table := range tables.ToSlice() {
+    if allocs.Get(uint32(table)) {
+      continue
+    }
+    if _, isUsed := usedTables[table]; isUsed {
+      continue
+    }
+    allocs.Set(uint32(table))
+    return table, allocs.Encode(), nil
+  }
+  return 0, "", errors.New("routing table cannot be allocated because the whole rtTableRange of TenantConfig:" + tconf.ObjectMeta.Name + " is already reserved")
+}
+
+// IsRtTableInRange returns true if the routing table ID belongs to the rtTableRange of the TenantConfig
+func IsRtTableInRange(tconf *danmtypes.TenantConfig, rtTable int) bool {
+  tables, err := cpuset.Parse(tconf.RtTableRange)
+  if err != nil {
+    return false
+  }
+  return tables.Contains(rtTable)
+}
+
+// FreeRtTable releases a routing table ID previously allocated from the rtTableRange of the TenantConfig
+func FreeRtTable(danmClient danmclientset.Interface, tconf *danmtypes.TenantConfig, rtTable int) error {
+  for {
+    allocs := bitarray.NewBitArrayFromBase64(tconf.RtTableAlloc)
+    if allocs.Len() == 0 {
+      return errors.New("routing table allocations of TenantConfig:" + tconf.ObjectMeta.Name + " is corrupt! Are you running without webhook?")
+    }
+    allocs.Reset(uint32(rtTable))
+    tconf.RtTableAlloc = allocs.Encode()
+    newConf, wasRefreshed, err := updateTenantConf(danmClient, tconf)
+    if err != nil {
+      return err
+    }
+    if wasRefreshed {
+      tconf = newConf
+      continue
+    }
+    return nil
+  }
+}
+
 func updateTenantConf(danmClient danmclientset.Interface, tconf *danmtypes.TenantConfig) (*danmtypes.TenantConfig,bool,error) {
   var wasRefreshed bool
   var newConf *danmtypes.TenantConfig
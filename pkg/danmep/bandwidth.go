@@ -0,0 +1,216 @@
+package danmep
+
+import (
+  "errors"
+  "net"
+  "runtime"
+  "strconv"
+  "syscall"
+  "github.com/containernetworking/plugins/pkg/ns"
+  "github.com/vishvananda/netlink"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+)
+
+const (
+  IfbPrefix = "ifb"
+  //Maximum amount of time a packet can wait in the TBF queue before it is dropped
+  ShapingLatencyInMillis = 25
+)
+
+// ValidateBandwidth checks if the traffic shaping limits are consistent
+// Rates and bursts must be provided together in both directions
+func ValidateBandwidth(limits danmtypes.BandwidthLimits) error {
+  if (limits.IngressRate == 0) != (limits.IngressBurst == 0) {
+    return errors.New("ingressRate and ingressBurst must be provided together")
+  }
+  if (limits.EgressRate == 0) != (limits.EgressBurst == 0) {
+    return errors.New("egressRate and egressBurst must be provided together")
+  }
+  //TBF calculates with bytes, so anything below that would be silently rounded to an unlimited 0
+  if (limits.IngressRate != 0 && limits.IngressRate < 8) || (limits.EgressRate != 0 && limits.EgressRate < 8) {
+    return errors.New("rates must be at least 8 bits per second")
+  }
+  if (limits.IngressBurst != 0 && limits.IngressBurst < 8) || (limits.EgressBurst != 0 && limits.EgressBurst < 8) {
+    return errors.New("bursts must be at least 8 bits")
+  }
+  return nil
+}
+
+// MergeBandwidth merges the traffic shaping limits requested for a Pod's connection into the limits of its network
+// Pods can only tighten the limits set by the network administrator, but cannot loosen them
+func MergeBandwidth(netLimits, podLimits danmtypes.BandwidthLimits) (danmtypes.BandwidthLimits, error) {
+  limits := netLimits
+  if podLimits.IngressRate != 0 {
+    if netLimits.IngressRate != 0 && podLimits.IngressRate > netLimits.IngressRate {
+      return limits, errors.New("requested ingressRate:" + strconv.FormatUint(podLimits.IngressRate, 10) + " is higher than the limit of the network:" + strconv.FormatUint(netLimits.IngressRate, 10))
+    }
+    limits.IngressRate  = podLimits.IngressRate
+    limits.IngressBurst = podLimits.IngressBurst
+  }
+  if podLimits.EgressRate != 0 {
+    if netLimits.EgressRate != 0 && podLimits.EgressRate > netLimits.EgressRate {
+      return limits, errors.New("requested egressRate:" + strconv.FormatUint(podLimits.EgressRate, 10) + " is higher than the limit of the network:" + strconv.FormatUint(netLimits.EgressRate, 10))
+    }
+    limits.EgressRate  = podLimits.EgressRate
+    limits.EgressBurst = podLimits.EgressBurst
+  }
+  return limits, nil
+}
+
+// IsBandwidthLimited returns true if traffic shaping is configured in any direction
+func IsBandwidthLimited(limits danmtypes.BandwidthLimits) bool {
+  return limits.IngressRate != 0 || limits.EgressRate != 0
+}
+
+// GetIfbName returns the name of the IFB device shaping the ingress traffic of a DanmEp
+func GetIfbName(ep *danmtypes.DanmEp) string {
+  return IfbPrefix + ep.Spec.EndpointID[0:12]
+}
+
+//Must be called from within the network namespace of the Pod
+//Egress traffic of the Pod is shaped by a TBF qdisc directly on the container interface.
+//Ingress traffic can be only policed on the interface itself, so it is redirected to an IFB device, and shaped on the egress of the IFB instead.
+//This works the same way for all backends, including IPVLAN slaves which do not have a host side peer on which ingress could be shaped
+func setBandwidthLimits(ep *danmtypes.DanmEp) error {
+  limits := ep.Spec.Iface.Bandwidth
+  if !IsBandwidthLimited(limits) {
+    return nil
+  }
+  iface, err := netlink.LinkByName(ep.Spec.Iface.Name)
+  if err != nil {
+    return errors.New("cannot find link:" + ep.Spec.Iface.Name + " because:" + err.Error())
+  }
+  if limits.EgressRate != 0 {
+    err = createTbf(limits.EgressRate, limits.EgressBurst, iface.Attrs().Index)
+    if err != nil {
+      return errors.New("cannot shape egress traffic of link:" + ep.Spec.Iface.Name + " because:" + err.Error())
+    }
+  }
+  if limits.IngressRate != 0 {
+    err = redirectIngressToIfb(ep, iface)
+    if err != nil {
+      return errors.New("cannot shape ingress traffic of link:" + ep.Spec.Iface.Name + " because:" + err.Error())
+    }
+  }
+  return nil
+}
+
+func redirectIngressToIfb(ep *danmtypes.DanmEp, iface netlink.Link) error {
+  ifb := &netlink.Ifb {
+    LinkAttrs: netlink.LinkAttrs {
+      Name:  GetIfbName(ep),
+      Flags: net.FlagUp,
+      MTU:   iface.Attrs().MTU,
+    },
+  }
+  err := netlink.LinkAdd(ifb)
+  if err != nil {
+    return errors.New("cannot create IFB device because:" + err.Error())
+  }
+  ifbLink, err := netlink.LinkByName(GetIfbName(ep))
+  if err != nil {
+    return errors.New("cannot find created IFB device because:" + err.Error())
+  }
+  ingress := &netlink.Ingress {
+    QdiscAttrs: netlink.QdiscAttrs {
+      LinkIndex: iface.Attrs().Index,
+      Handle:    netlink.MakeHandle(0xffff, 0),
+      Parent:    netlink.HANDLE_INGRESS,
+    },
+  }
+  err = netlink.QdiscAdd(ingress)
+  if err != nil {
+    return errors.New("cannot create ingress qdisc because:" + err.Error())
+  }
+  filter := &netlink.U32 {
+    FilterAttrs: netlink.FilterAttrs {
+      LinkIndex: iface.Attrs().Index,
+      Parent:    ingress.QdiscAttrs.Handle,
+      Priority:  1,
+      Protocol:  syscall.ETH_P_ALL,
+    },
+    Actions: []netlink.Action {
+      netlink.NewMirredAction(ifbLink.Attrs().Index),
+    },
+  }
+  err = netlink.FilterAdd(filter)
+  if err != nil {
+    return errors.New("cannot redirect ingress traffic to IFB device because:" + err.Error())
+  }
+  return createTbf(ep.Spec.Iface.Bandwidth.IngressRate, ep.Spec.Iface.Bandwidth.IngressBurst, ifbLink.Attrs().Index)
+}
+
+func createTbf(rateInBits, burstInBits uint64, linkIndex int) error {
+  rateInBytes := rateInBits / 8
+  burstInBytes := burstInBits / 8
+  bufferInTicks := uint32(netlink.Xmittime(rateInBytes, uint32(burstInBytes)))
+  latencyInUsec := float64(netlink.TIME_UNITS_PER_SEC) * ShapingLatencyInMillis / 1000.0
+  limitInBytes := uint32(float64(rateInBytes) * latencyInUsec / float64(netlink.TIME_UNITS_PER_SEC)) + uint32(burstInBytes)
+  tbf := &netlink.Tbf {
+    QdiscAttrs: netlink.QdiscAttrs {
+      LinkIndex: linkIndex,
+      Handle:    netlink.MakeHandle(1, 0),
+      Parent:    netlink.HANDLE_ROOT,
+    },
+    Limit:  limitInBytes,
+    Rate:   rateInBytes,
+    Buffer: bufferInTicks,
+  }
+  err := netlink.QdiscAdd(tbf)
+  if err != nil {
+    return errors.New("cannot create TBF qdisc because:" + err.Error())
+  }
+  return nil
+}
+
+// DeleteBandwidthLimits removes the traffic shaping configuration of a DanmEp from the network namespace of its Pod
+// Qdiscs are removed explicitly, because interfaces like SR-IOV VFs are not destroyed, but returned to the host when the Pod is deleted
+func DeleteBandwidthLimits(ep *danmtypes.DanmEp) error {
+  if !IsBandwidthLimited(ep.Spec.Iface.Bandwidth) {
+    return nil
+  }
+  isEpLocal, err := isEpOnThisHost(ep)
+  if !isEpLocal {
+    //Nothing to clean-up if the network namespace is already gone
+    return nil
+  }
+  runtime.LockOSThread()
+  defer runtime.UnlockOSThread()
+  origNs, err := ns.GetCurrentNS()
+  if err != nil {
+    return errors.New("getting current namespace failed")
+  }
+  hns, err := ns.GetNS(ep.Spec.Netns)
+  if err != nil {
+    return nil
+  }
+  defer func() {
+    hns.Close()
+    err = origNs.Set()
+    if err != nil {
+      GetEpLogger(ep).Error("Could not switch back to default ns during traffic shaping removal:" + err.Error())
+    }
+  }()
+  err = hns.Set()
+  if err != nil {
+    return errors.New("failed to enter network namespace of CID:" + ep.Spec.Netns + " with error:" + err.Error())
+  }
+  iface, err := netlink.LinkByName(ep.Spec.Iface.Name)
+  if err == nil {
+    qdiscs, _ := netlink.QdiscList(iface)
+    for _, qdisc := range qdiscs {
+      if qdisc.Type() == "tbf" || qdisc.Type() == "ingress" {
+        netlink.QdiscDel(qdisc)
+      }
+    }
+  }
+  ifb, err := netlink.LinkByName(GetIfbName(ep))
+  if err != nil {
+    return nil
+  }
+  err = netlink.LinkDel(ifb)
+  if err != nil {
+    return errors.New("cannot delete IFB device:" + GetIfbName(ep) + " because:" + err.Error())
+  }
+  return nil
+}
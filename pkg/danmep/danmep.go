@@ -4,7 +4,6 @@ import (
   "errors"
   "fmt"
   "os"
-  "log"
   "runtime"
   "strconv"
   "time"
@@ -16,6 +15,7 @@ import (
   danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
   "github.com/nokia/danm/pkg/datastructs"
   "github.com/nokia/danm/pkg/ipam"
+  "github.com/nokia/danm/pkg/logger"
   "github.com/nokia/danm/pkg/netcontrol"
   "github.com/satori/go.uuid"
 )
@@ -50,6 +50,8 @@ var sysctls = []sysctlTask {
 const (
   MaxRetryCount = 10
   RetryInterval = 100
+  HostVethPrefix = "dv"
+  RoutedV6Gateway = "fe80::1"
 )
 
 // DeleteIpvlanInterface deletes a Pod's IPVLAN network interface based on the related DanmEp
@@ -134,6 +136,39 @@ func AddIpvlanInterface(dnet *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
   return createIpvlanInterface(dnet, ep)
 }
 
+// AddBridgeInterface connects a Pod to the host bridge of its network via a veth pair
+func AddBridgeInterface(dnet *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
+  if !netcontrol.IsBridgeNetwork(dnet) {
+    return nil
+  }
+  return createBridgeInterface(dnet, ep)
+}
+
+// DeleteBridgeInterface deletes a Pod's veth interface based on the related DanmEp
+// The host side of the veth pair is automatically removed together with it by the kernel
+func DeleteBridgeInterface(ep *danmtypes.DanmEp) error {
+  return deleteEp(ep)
+}
+
+// AddRoutedInterface connects a Pod to the host via a veth pair, and makes it reachable via host routes
+func AddRoutedInterface(dnet *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
+  if !netcontrol.IsRoutedNetwork(dnet) {
+    return nil
+  }
+  return createRoutedInterface(dnet, ep)
+}
+
+// DeleteRoutedInterface deletes a Pod's veth interface based on the related DanmEp
+// The host side of the veth pair, together with the host routes pointing to it are automatically removed by the kernel
+func DeleteRoutedInterface(ep *danmtypes.DanmEp) error {
+  return deleteEp(ep)
+}
+
+// GetHostVethName returns the name of the host side veth interface belonging to a DanmEp
+func GetHostVethName(ep *danmtypes.DanmEp) string {
+  return HostVethPrefix + ep.Spec.EndpointID[0:13]
+}
+
 func DetermineHostDeviceName(dnet *danmtypes.DanmNet) string {
   var device string
   isVlanDefined := (dnet.Spec.Options.Vlan!=0)
@@ -149,6 +184,8 @@ func DetermineHostDeviceName(dnet *danmtypes.DanmNet) string {
   return device
 }
 
+// PostProcessInterface configures the generally supported features of DANM on an already created container interface
+// A *RouteError is returned when only some IP routes could not be installed
 func PostProcessInterface(ep *danmtypes.DanmEp, dnet *danmtypes.DanmNet) error {
   runtime.LockOSThread()
   defer runtime.UnlockOSThread()
@@ -164,7 +201,7 @@ func PostProcessInterface(ep *danmtypes.DanmEp, dnet *danmtypes.DanmNet) error {
     hns.Close()
     err = origNs.Set()
     if err != nil {
-      log.Println("Could not switch back to default ns during IP route provisioning operation:" + err.Error())
+      GetEpLogger(ep).Error("Could not switch back to default ns during IP route provisioning operation:" + err.Error())
     }
   }()
   err = hns.Set()
@@ -172,16 +209,29 @@ func PostProcessInterface(ep *danmtypes.DanmEp, dnet *danmtypes.DanmNet) error {
     return errors.New("failed to enter network namespace of CID:" + ep.Spec.Netns + " with error:" + err.Error())
   }
   isVfAttachedToDpdkDriver,_ := sriov_utils.HasDpdkDriver(ep.Spec.Iface.DeviceID)
-  if isVfAttachedToDpdkDriver {
+  if isVfAttachedToDpdkDriver || IsVhostUserEp(dnet, ep) {
     err = createDummyInterface(ep)
     if err != nil {
       return errors.New("failed to create dummy kernel interface for " + ep.Spec.Iface.Name + " because:" + err.Error())
     }
   }
+  //Delegated backends not supporting MAC configuration on their own still create the interface with a random MAC
+  err = setContainerMac(ep)
+  if err != nil {
+    return errors.New("failed to set MAC address of interface:" + ep.Spec.Iface.Name + " because:" + err.Error())
+  }
   err = setDanmEpSysctls(ep)
   if err != nil {
     return errors.New("failed to set kernel configs for interface" + ep.Spec.Iface.Name + " beause:" + err.Error())
   }
+  err = setUserSysctls(ep)
+  if err != nil {
+    return errors.New("failed to set requested kernel configs for interface:" + ep.Spec.Iface.Name + " because:" + err.Error())
+  }
+  err = setBandwidthLimits(ep)
+  if err != nil {
+    return errors.New("failed to set traffic shaping for interface:" + ep.Spec.Iface.Name + " because:" + err.Error())
+  }
   return addIpRoutes(ep,dnet)
 }
 
@@ -235,6 +285,33 @@ func ArePodsConnectedToNetwork(client danmclientset.Interface, dnet *danmtypes.D
   return false, danmtypes.DanmEp{}, nil
 }
 
+// FindByNetwork returns the DanmEps of all the Pods connected to the particular network
+func FindByNetwork(client danmclientset.Interface, dnet *danmtypes.DanmNet)([]danmtypes.DanmEp, error) {
+  result, err := client.DanmV1().DanmEps("").List(meta_v1.ListOptions{})
+  if err != nil {
+    return nil, errors.New("cannot list DanmEps because:" + err.Error())
+  }
+  eps := make([]danmtypes.DanmEp, 0)
+  if result == nil {
+    return eps, nil
+  }
+  netKind := dnet.TypeMeta.Kind
+  if netKind == "" {
+    netKind = "DanmNet"
+  }
+  for _, ep := range result.Items {
+    epKind := ep.Spec.ApiType
+    if epKind == "" {
+      epKind = "DanmNet"
+    }
+    if (epKind == netKind && ep.Spec.NetworkName == dnet.ObjectMeta.Name) &&
+       (netKind == "ClusterNetwork" || ep.ObjectMeta.Namespace == dnet.ObjectMeta.Namespace) {
+      eps = append(eps, ep)
+    }
+  }
+  return eps, nil
+}
+
 //CreateDanmEp is a RAII-like API to automatically reserve IP allocations whenever an object holding these allocations is created
 //It helps making sure IPs are for sure universally reserved upon DanmEp creation itself
 //TODO: I hate myself for the bool input parameter, but ipam absolutely should not depend on cnidel. Could be changed to cleverly defaulting iface attributes to sthing?
@@ -244,18 +321,31 @@ func CreateDanmEp(danmClient danmclientset.Interface, namingScheme string, isIpR
     ip6 = iface.Ip6
     err error
   )
+  //Checked before IPs are reserved, so they don't leak
+  bandwidth, err := MergeBandwidth(netInfo.Spec.Options.Bandwidth, iface.Bandwidth)
+  if err != nil {
+    return nil, netInfo, errors.New("invalid traffic shaping request for network:" + netInfo.ObjectMeta.Name + " because:" + err.Error())
+  }
   if isIpReservationNeeded {
     ip4, ip6, err = ipam.Reserve(danmClient, *netInfo, iface.Ip, iface.Ip6)
     if err != nil {
       return nil, netInfo, errors.New("IP address reservation failed for network:" + netInfo.ObjectMeta.Name + " with error:" + err.Error())
     }
   }
+  mac := iface.Mac
+  if mac == "" && netInfo.Spec.Options.MacFromIp {
+    mac = GenerateMacFromIp(ip4, ip6)
+  }
   epSpec := danmtypes.DanmEpIface {
-    Name: calculateIfaceName(namingScheme, netInfo.Spec.Options.Prefix, iface.DefaultIfaceName, iface.SequenceId),
+    Name: CalculateIfaceName(namingScheme, netInfo, iface),
     Address:     ip4,
     AddressIPv6: ip6,
     Proutes:     iface.Proutes,
     Proutes6:    iface.Proutes6,
+    MacAddress:  mac,
+    DeviceID:    iface.Device,
+    Sysctls:     MergeSysctls(netInfo.Spec.Options.Sysctls, iface.Sysctls),
+    Bandwidth:   bandwidth,
   }
   ep, err := createDanmEp(danmClient, epSpec, netInfo, args)
   if err != nil {
@@ -271,14 +361,22 @@ func CreateDanmEp(danmClient danmclientset.Interface, namingScheme string, isIpR
 }
 
 // CalculateIfaceName decides what should be the name of a container's interface.
+// If a name is explicitly requested for the connection in the Pod's annotation, the NIC will be named exactly so.
 // If a name is explicitly set in the related network API object, the NIC will be named accordingly.
 // If a name is not explicitly set, then DANM names the interface ethX where X=sequence number of the interface
 // When legacy naming scheme is configured container_prefix behaves as the exact name of an interface, rather than its name suggest
-func calculateIfaceName(namingScheme, chosenName, defaultName string, sequenceId int) string {
+func CalculateIfaceName(namingScheme string, netInfo *danmtypes.DanmNet, iface datastructs.Interface) string {
   //Kubelet expects the first interface to be literally named "eth0", so...
-  if sequenceId == 0 {
+  if iface.SequenceId == 0 {
     return "eth0"
   }
+  if iface.IfName != "" {
+    return iface.IfName
+  }
+  return calculateIfaceName(namingScheme, netInfo.Spec.Options.Prefix, iface.DefaultIfaceName, iface.SequenceId)
+}
+
+func calculateIfaceName(namingScheme, chosenName, defaultName string, sequenceId int) string {
   if chosenName != "" {
     if namingScheme != datastructs.LegacyNamingScheme {
       chosenName += strconv.Itoa(sequenceId)
@@ -336,8 +434,13 @@ func createDanmEp(danmClient danmclientset.Interface, epInput danmtypes.DanmEpIf
 func UpdateDanmEp(client danmclientset.Interface, ep *danmtypes.DanmEp) error {
   var err error
   for i := 0; i < MaxRetryCount; i++ {
-    _, err = client.DanmV1().DanmEps(ep.Namespace).Update(ep)
+    var newEp *danmtypes.DanmEp
+    newEp, err = client.DanmV1().DanmEps(ep.Namespace).Update(ep)
     if err == nil {
+      //Keeps the object updatable in case it needs to be updated again during the same CNI operation
+      if newEp != nil {
+        ep.ObjectMeta.ResourceVersion = newEp.ObjectMeta.ResourceVersion
+      }
       break
     }
     time.Sleep(RetryInterval * time.Millisecond)
@@ -360,4 +463,10 @@ func DeleteDanmEp(danmClient danmclientset.Interface, ep *danmtypes.DanmEp, dnet
     }
   }
   return danmClient.DanmV1().DanmEps(ep.ObjectMeta.Namespace).Delete(ep.ObjectMeta.Name, &meta_v1.DeleteOptions{})
-}
\ No newline at end of file
+}
+
+// GetEpLogger returns a log Entry carrying the Fields identifying the Pod, network, and interface of the DanmEp
+func GetEpLogger(ep *danmtypes.DanmEp) *logger.Entry {
+  return logger.WithFields(logger.Fields{logger.PodField: ep.Spec.Pod, logger.NamespaceField: ep.ObjectMeta.Namespace, logger.CidField: ep.Spec.CID,
+                                         logger.NetworkField: ep.Spec.NetworkName, logger.EndpointIdField: ep.Spec.EndpointID, logger.IfaceField: ep.Spec.Iface.Name})
+}
@@ -2,32 +2,217 @@ package danmep
 
 import (
   "errors"
-  "log"
+  "fmt"
   "net"
   "os"
   "runtime"
   "strconv"
+  "syscall"
   "github.com/vishvananda/netlink"
   "github.com/containernetworking/plugins/pkg/ns"
+  "github.com/containernetworking/plugins/pkg/utils/sysctl"
   danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
   "github.com/nokia/danm/pkg/ipam"
+  "github.com/nokia/danm/pkg/netcontrol"
   "github.com/j-keck/arping"
 )
 
 func createIpvlanInterface(dnet *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
+  isEpLocal, err := isEpOnThisHost(ep)
+  if !isEpLocal {
+    return err
+  }
+  device := DetermineHostDeviceName(dnet)
+  return createContainerIface(ep, dnet, device)
+}
+
+func createBridgeInterface(dnet *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
+  isEpLocal, err := isEpOnThisHost(ep)
+  if !isEpLocal {
+    return err
+  }
+  brName := netcontrol.GetBridgeName(dnet)
+  bridge, err := netlink.LinkByName(brName)
+  if err != nil {
+    return errors.New("cannot find host bridge:" + brName + " because:" + err.Error())
+  }
+  return createVethIface(ep, bridge.Attrs().MTU, func(hostVeth netlink.Link) error {
+    err := netlink.LinkSetMaster(hostVeth, bridge)
+    if err != nil {
+      return errors.New("cannot connect veth interface to bridge:" + brName + " because:" + err.Error())
+    }
+    if dnet.Spec.Options.Hairpin {
+      err = netlink.LinkSetHairpin(hostVeth, true)
+      if err != nil {
+        return errors.New("cannot set hairpin mode on veth interface because:" + err.Error())
+      }
+    }
+    return nil
+  })
+}
+
+func createRoutedInterface(dnet *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
+  isEpLocal, err := isEpOnThisHost(ep)
+  if !isEpLocal {
+    return err
+  }
+  return createVethIface(ep, 0, func(hostVeth netlink.Link) error {
+    //Host routes can only be added to UP interfaces
+    err := netlink.LinkSetUp(hostVeth)
+    if err != nil {
+      return errors.New("cannot set host veth interface UP because:" + err.Error())
+    }
+    err = setupHostRouting(hostVeth, ep.Spec.Iface.Address, "net.ipv4.conf.%s.proxy_arp")
+    if err != nil {
+      return err
+    }
+    if isAddressAllocated(ep.Spec.Iface.AddressIPv6) {
+      //Pods reach their IPv6 subnet via this link-local address of the host, see addIpRoutes
+      gwAddr := &netlink.Addr{IPNet: &net.IPNet{IP: net.ParseIP(RoutedV6Gateway), Mask: net.CIDRMask(64,128)}, Flags: syscall.IFA_F_NODAD}
+      err = netlink.AddrAdd(hostVeth, gwAddr)
+      if err != nil {
+        return errors.New("cannot add IPv6 gateway address to host veth interface because:" + err.Error())
+      }
+    }
+    return setupHostRouting(hostVeth, ep.Spec.Iface.AddressIPv6, "net.ipv6.conf.%s.proxy_ndp")
+  })
+}
+
+// setupHostRouting turns on proxy ARP/NDP on the host end of the veth pair, and adds a host route pointing to it for the IP of the Pod
+func setupHostRouting(hostVeth netlink.Link, cidr, proxySysctl string) error {
+  if !isAddressAllocated(cidr) {
+    return nil
+  }
+  ip, _, err := net.ParseCIDR(cidr)
+  if err != nil {
+    return errors.New("cannot parse IP address because:" + err.Error())
+  }
+  _, err = sysctl.Sysctl(fmt.Sprintf(proxySysctl, hostVeth.Attrs().Name), "1")
+  if err != nil {
+    return errors.New("failed to set sysctl due to:" + err.Error())
+  }
+  maskLength := net.IPv6len*8
+  if ip.To4() != nil {
+    ip = ip.To4()
+    maskLength = net.IPv4len*8
+  }
+  route := netlink.Route {
+    LinkIndex: hostVeth.Attrs().Index,
+    Dst:       &net.IPNet{IP: ip, Mask: net.CIDRMask(maskLength, maskLength)},
+    Scope:     netlink.SCOPE_LINK,
+  }
+  err = netlink.RouteAdd(&route)
+  if err != nil {
+    return errors.New("cannot add host route:" + route.Dst.String() + " because:" + err.Error())
+  }
+  return nil
+}
+
+func isAddressAllocated(cidr string) bool {
+  return cidr != "" && cidr != ipam.NoneAllocType
+}
+
+func isEpOnThisHost(ep *danmtypes.DanmEp) (bool,error) {
   host, err := os.Hostname()
   if err != nil {
-    return errors.New("cannot get hostname because:" + err.Error())
+    return false, errors.New("cannot get hostname because:" + err.Error())
   }
   if host != ep.Spec.Host {
     //It should never happen that an interface is created from an Ep belonging to another host
-    return nil
+    return false, nil
   }
   if ns.IsNSorErr(ep.Spec.Netns) != nil {
-    return errors.New("Cannot get container pid!")
+    return false, errors.New("Cannot get container pid!")
   }
-  device := DetermineHostDeviceName(dnet)
-  return createContainerIface(ep, dnet, device)
+  return true, nil
+}
+
+// createVethIface creates a veth pair, and moves one end of it into the network namespace of the Pod.
+// The host end is named deterministically from the EndpointID, and is configured via the provided function before it is brought UP.
+func createVethIface(ep *danmtypes.DanmEp, mtu int, setupHostEnd func(netlink.Link) error) error {
+  runtime.LockOSThread()
+  defer runtime.UnlockOSThread()
+  origns, err := ns.GetCurrentNS()
+  if err != nil {
+    return errors.New("getting current namespace failed")
+  }
+  hns, err := ns.GetNS(ep.Spec.Netns)
+  if err != nil {
+    return errors.New("cannot open network namespace:" + ep.Spec.Netns)
+  }
+  defer func() {
+    hns.Close()
+    err = origns.Set()
+    if err != nil {
+      GetEpLogger(ep).Error("Could not switch back to default ns during veth interface creation:" + err.Error())
+    }
+  }()
+  hostVethName := GetHostVethName(ep)
+  outer := ep.Spec.EndpointID[0:15]
+  veth := &netlink.Veth {
+    LinkAttrs: netlink.LinkAttrs {
+      Name: hostVethName,
+      MTU:  mtu,
+    },
+    PeerName: outer,
+  }
+  err = netlink.LinkAdd(veth)
+  if err != nil {
+    return errors.New("cannot create veth interface because:" + err.Error())
+  }
+  hostVeth, err := netlink.LinkByName(hostVethName)
+  if err != nil {
+    return errors.New("cannot find created veth interface because:" + err.Error())
+  }
+  err = setupHostEnd(hostVeth)
+  if err != nil {
+    netlink.LinkDel(hostVeth)
+    return err
+  }
+  err = netlink.LinkSetUp(hostVeth)
+  if err != nil {
+    netlink.LinkDel(hostVeth)
+    return errors.New("cannot set host veth interface UP because:" + err.Error())
+  }
+  peer, err := netlink.LinkByName(outer)
+  if err != nil {
+    netlink.LinkDel(hostVeth)
+    return errors.New("cannot find the container end of the created veth pair because:" + err.Error())
+  }
+  err = netlink.LinkSetNsFd(peer, int(hns.Fd()))
+  if err != nil {
+    netlink.LinkDel(hostVeth)
+    return errors.New("cannot move veth interface to netns because:" + err.Error())
+  }
+  //The host end is deleted from the original namespace, which also removes the container end of the pair, and the bridge port
+  deleteVeth := func() {
+    origns.Do(func(ns.NetNS) error {
+      return netlink.LinkDel(hostVeth)
+    })
+  }
+  err = hns.Set()
+  if err != nil {
+    deleteVeth()
+    return errors.New("failed to enter network namespace of CID:"+ep.Spec.Netns+" with error:"+err.Error())
+  }
+  iface, err := netlink.LinkByName(outer)
+  if err != nil {
+    deleteVeth()
+    return errors.New("cannot find veth interface in network namespace:" + err.Error())
+  }
+  err = configureLink(iface, ep)
+  if err != nil {
+    deleteVeth()
+    return err
+  }
+  if ep.Spec.Iface.Address != "" && ep.Spec.Iface.Address != ipam.NoneAllocType {
+    addr,_,_ := net.ParseCIDR(ep.Spec.Iface.Address)
+    err = arping.GratuitousArpOverIfaceByName(addr, ep.Spec.Iface.Name)
+    if err != nil {
+      GetEpLogger(ep).Warning("sending gARP failed with error:" + err.Error() + ", but we will ignore that for now!")
+    }
+  }
+  return nil
 }
 
 func createContainerIface(ep *danmtypes.DanmEp, dnet *danmtypes.DanmNet, device string) error {
@@ -45,7 +230,7 @@ func createContainerIface(ep *danmtypes.DanmEp, dnet *danmtypes.DanmNet, device
     hns.Close()
     err = origns.Set()
     if err != nil {
-      log.Println("Could not switch back to default ns during IPVLAN interface creation:" + err.Error())
+      GetEpLogger(ep).Error("Could not switch back to default ns during IPVLAN interface creation:" + err.Error())
     }
   }()
   iface, err := netlink.LinkByName(device)
@@ -91,7 +276,7 @@ func createContainerIface(ep *danmtypes.DanmEp, dnet *danmtypes.DanmNet, device
     addr,_,_ := net.ParseCIDR(ep.Spec.Iface.Address)
     err = arping.GratuitousArpOverIfaceByName(addr, ep.Spec.Iface.Name)
     if err != nil {
-      log.Println("WARNING: sending gARP failed with error:" + err.Error(), ", but we will ignore that for now!")
+      GetEpLogger(ep).Warning("sending gARP failed with error:" + err.Error() + ", but we will ignore that for now!")
     }
   }
   return nil
@@ -111,6 +296,16 @@ func configureLink(iface netlink.Link, ep *danmtypes.DanmEp) error {
       return err
     }
   }
+  if ep.Spec.Iface.MacAddress != "" {
+    mac, err := net.ParseMAC(ep.Spec.Iface.MacAddress)
+    if err != nil {
+      return errors.New("invalid MAC address:" + ep.Spec.Iface.MacAddress + " because:" + err.Error())
+    }
+    err = netlink.LinkSetHardwareAddr(iface, mac)
+    if err != nil {
+      return errors.New("cannot set MAC address:" + mac.String() + " on link:" + ep.Spec.Iface.Name + " because:" + err.Error())
+    }
+  }
   err = netlink.LinkSetName(iface, ep.Spec.Iface.Name)
   if err != nil {
     return errors.New("cannot rename link:" + ep.Spec.Iface.Name + " because:" + err.Error())
@@ -137,38 +332,78 @@ func addIpToLink(ip string, iface netlink.Link) error {
 
 func addIpRoutes(ep *danmtypes.DanmEp, dnet *danmtypes.DanmNet) error {
   defaultRoutingTable := 0
-  err := addRoutes(dnet.Spec.Options.Routes, ep.Spec.Iface.Address, defaultRoutingTable)
-  if err != nil {
-    return err
+  routes6, proutes6 := dnet.Spec.Options.Routes6, ep.Spec.Iface.Proutes6
+  ipRoutes6 := GetIpRoutesOfFamily(dnet.Spec.Options.IpRoutes, true)
+  if netcontrol.IsRoutedNetwork(dnet) && isAddressAllocated(ep.Spec.Iface.AddressIPv6) {
+    //There is no proxy NDP for whole subnets, so in routed networks the host is explicitly set as the next hop of every IPv6 destination
+    err := routeV6SubnetViaHost(ep)
+    if err != nil {
+      return err
+    }
+    routes6 = overwriteGateways(routes6, RoutedV6Gateway)
+    proutes6 = overwriteGateways(proutes6, RoutedV6Gateway)
+    ipRoutes6 = overwriteIpRouteGateways(ipRoutes6, RoutedV6Gateway)
+  }
+  var routeErrs []error
+  routeErrs = append(routeErrs, addRoutes(ep, dnet.Spec.Options.Routes, ep.Spec.Iface.Address, defaultRoutingTable)...)
+  routeErrs = append(routeErrs, addRoutes(ep, routes6, ep.Spec.Iface.AddressIPv6, defaultRoutingTable)...)
+  routeErrs = append(routeErrs, addIpRouteList(ep, GetIpRoutesOfFamily(dnet.Spec.Options.IpRoutes, false), ep.Spec.Iface.Address)...)
+  routeErrs = append(routeErrs, addIpRouteList(ep, ipRoutes6, ep.Spec.Iface.AddressIPv6)...)
+  routeErrs = append(routeErrs, addPolicyRoute(ep, dnet.Spec.Options.RTables, ep.Spec.Iface.Address, ep.Spec.Iface.Proutes)...)
+  routeErrs = append(routeErrs, addPolicyRoute(ep, dnet.Spec.Options.RTables, ep.Spec.Iface.AddressIPv6, proutes6)...)
+  if len(routeErrs) == 0 {
+    return nil
   }
-  err = addRoutes(dnet.Spec.Options.Routes6, ep.Spec.Iface.AddressIPv6, defaultRoutingTable)
+  return &RouteError{Errors: routeErrs, Lenient: dnet.Spec.Options.LenientRoutes}
+}
+
+func routeV6SubnetViaHost(ep *danmtypes.DanmEp) error {
+  _, subnet, err := net.ParseCIDR(ep.Spec.Iface.AddressIPv6)
   if err != nil {
-    return err
+    return errors.New("cannot parse IP address because:" + err.Error())
   }
-  err = addPolicyRoute(dnet.Spec.Options.RTables, ep.Spec.Iface.Address, ep.Spec.Iface.Proutes)
+  iface, err := netlink.LinkByName(ep.Spec.Iface.Name)
   if err != nil {
-    return err
+    return errors.New("cannot find interface:" + ep.Spec.Iface.Name + " because:" + err.Error())
+  }
+  route := netlink.Route {
+    LinkIndex: iface.Attrs().Index,
+    Dst:       subnet,
+    Gw:        net.ParseIP(RoutedV6Gateway),
   }
-  err = addPolicyRoute(dnet.Spec.Options.RTables, ep.Spec.Iface.AddressIPv6, ep.Spec.Iface.Proutes6)
+  err = netlink.RouteReplace(&route)
   if err != nil {
-    return err
+    return errors.New("cannot route IPv6 subnet:" + subnet.String() + " via the host because:" + err.Error())
   }
+  recordRoute(ep, &route)
   return nil
 }
 
-func addRoutes(routes map[string]string, allocatedIp string, rtable int) error {
+func overwriteGateways(routes map[string]string, gw string) map[string]string {
+  if routes == nil {
+    return nil
+  }
+  newRoutes := make(map[string]string, len(routes))
+  for dst := range routes {
+    newRoutes[dst] = gw
+  }
+  return newRoutes
+}
+
+func addRoutes(ep *danmtypes.DanmEp, routes map[string]string, allocatedIp string, rtable int) []error {
   if routes == nil || allocatedIp == "" || allocatedIp == ipam.NoneAllocType {
     return nil
   }
+  var routeErrs []error
   for key, value := range routes {
     _, ipnet, err := net.ParseCIDR(key)
     if err != nil {
-      //Bad destination in IP route, ignoring the route
+      routeErrs = append(routeErrs, errors.New("cannot parse destination of IP route:" + key + " because:" + err.Error()))
       continue
     }
     ip := net.ParseIP(value)
     if ip == nil {
-      //Bad gateway in IP route, ignoring the route
+      routeErrs = append(routeErrs, errors.New("cannot parse gateway:" + value + " of IP route with destination:" + key))
       continue
     }
     route := netlink.Route{
@@ -182,30 +417,48 @@ func addRoutes(routes map[string]string, allocatedIp string, rtable int) error {
     }
     err = netlink.RouteAdd(&route)
     if err != nil {
-      return errors.New("Adding IP route with destination:" + ipnet.String() + " and gateway:" + ip.String() + "failed with error:" + err.Error())
+      routeErrs = append(routeErrs, errors.New("adding IP route with destination:" + ipnet.String() + " and gateway:" + ip.String() + " failed with error:" + err.Error()))
+      continue
     }
+    recordRoute(ep, &route)
   }
-  return nil
+  return routeErrs
 }
 
-func addPolicyRoute(rtable int, cidr string, proutes map[string]string) error {
+func addPolicyRoute(ep *danmtypes.DanmEp, rtable int, cidr string, proutes map[string]string) []error {
   if rtable == 0 || cidr == "" || cidr == ipam.NoneAllocType || proutes == nil {
     return nil
   }
   srcIp, srcNet, _ := net.ParseCIDR(cidr)
   srcPref := &net.IPNet{IP: srcIp, Mask: srcNet.Mask}
-  rule := netlink.NewRule()
-  rule.Src = srcPref
-  rule.Table = rtable
-  err := netlink.RuleAdd(rule)
-  if err != nil {
-    return errors.New("cannot add rule for policy-based IP routes because:" + err.Error())
+  family := netlink.FAMILY_V4
+  if srcIp.To4() == nil {
+    family = netlink.FAMILY_V6
+  }
+  rules := []*netlink.Rule{netlink.NewRule(), netlink.NewRule(), netlink.NewRule()}
+  rules[0].Src = srcPref
+  //Traffic leaving or entering through the interface is also looked up from the table, regardless of its source address
+  rules[1].OifName = ep.Spec.Iface.Name
+  rules[2].IifName = ep.Spec.Iface.Name
+  var routeErrs []error
+  for _, rule := range rules {
+    rule.Table = rtable
+    rule.Family = family
+    err := netlink.RuleAdd(rule)
+    if err != nil {
+      routeErrs = append(routeErrs, errors.New("cannot add rule for policy-based IP routes because:" + err.Error()))
+      continue
+    }
+    installedRule := danmtypes.IfaceRule{Table: rtable, Family: family, OifName: rule.OifName, IifName: rule.IifName}
+    if rule.Src != nil {
+      installedRule.Src = rule.Src.String()
+    }
+    ep.Spec.Iface.Rules = append(ep.Spec.Iface.Rules, installedRule)
   }
-  err = addRoutes(proutes, cidr, rtable)
-  if err != nil {
-    return err
+  if len(routeErrs) == len(rules) {
+    return routeErrs
   }
-  return nil
+  return append(routeErrs, addRoutes(ep, proutes, cidr, rtable)...)
 }
 
 func deleteContainerIface(ep *danmtypes.DanmEp) error {
@@ -0,0 +1,75 @@
+package danmep
+
+import (
+  "errors"
+  "net"
+  "strings"
+  "github.com/vishvananda/netlink"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+)
+
+const (
+  //Locally administered, unicast prefixes of the MACs derived from IPv4, and IPv6 addresses respectively
+  Ipv4MacPrefix = 0x00
+  Ipv6MacPrefix = 0x06
+)
+
+// IsMacConfigurable returns true if DANM can set the MAC address of the container interfaces connected to the given NetworkType
+// IPVLAN slaves always inherit the MAC of their parent, while the other backends manage their MACs on their own
+func IsMacConfigurable(neType string) bool {
+  switch strings.ToLower(neType) {
+  case "bridge", "routed", "ovs", "macvlan", "sriov":
+    return true
+  }
+  return false
+}
+
+// GenerateMacFromIp derives a locally administered unicast MAC address from the allocated IPs of an interface, so the same IP always comes with the same MAC
+// The MAC is 02:00 followed by the IPv4 address, or 02:06 followed by the last four bytes of the IPv6 address for IPv6-only interfaces
+// Returns an empty string if neither of the addresses is a valid CIDR
+func GenerateMacFromIp(ip4, ip6 string) string {
+  if ip, _, err := net.ParseCIDR(ip4); err == nil && ip.To4() != nil {
+    return buildMac(Ipv4MacPrefix, ip.To4())
+  }
+  if ip, _, err := net.ParseCIDR(ip6); err == nil && ip.To16() != nil {
+    return buildMac(Ipv6MacPrefix, ip.To16()[12:])
+  }
+  return ""
+}
+
+func buildMac(prefix byte, suffix net.IP) string {
+  mac := net.HardwareAddr{0x02, prefix, suffix[0], suffix[1], suffix[2], suffix[3]}
+  return mac.String()
+}
+
+//Must be called from within the network namespace of the Pod
+func setContainerMac(ep *danmtypes.DanmEp) error {
+  if ep.Spec.Iface.MacAddress == "" {
+    return nil
+  }
+  mac, err := net.ParseMAC(ep.Spec.Iface.MacAddress)
+  if err != nil {
+    return errors.New("invalid MAC address:" + ep.Spec.Iface.MacAddress + " because:" + err.Error())
+  }
+  iface, err := netlink.LinkByName(ep.Spec.Iface.Name)
+  if err != nil {
+    return errors.New("cannot find link:" + ep.Spec.Iface.Name + " because:" + err.Error())
+  }
+  if iface.Attrs().HardwareAddr.String() == mac.String() {
+    return nil
+  }
+  //Not all drivers support changing the MAC of a link which is UP
+  err = netlink.LinkSetDown(iface)
+  if err != nil {
+    return errors.New("cannot set link:" + ep.Spec.Iface.Name + " DOWN because:" + err.Error())
+  }
+  err = netlink.LinkSetHardwareAddr(iface, mac)
+  if err != nil {
+    return errors.New("cannot set MAC address:" + mac.String() + " on link:" + ep.Spec.Iface.Name + " because:" + err.Error())
+  }
+  err = netlink.LinkSetUp(iface)
+  if err != nil {
+    return errors.New("cannot set link:" + ep.Spec.Iface.Name + " UP because:" + err.Error())
+  }
+  return nil
+}
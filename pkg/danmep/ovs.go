@@ -0,0 +1,129 @@
+package danmep
+
+import (
+  "errors"
+  "os/exec"
+  "path/filepath"
+  "strconv"
+  "strings"
+  "github.com/vishvananda/netlink"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  "github.com/nokia/danm/pkg/netcontrol"
+)
+
+const (
+  VhostUserPrefix = "vhu"
+  VhostUserSocketDir = "/var/lib/danm/vhostuser"
+  OvsTimeout = 10
+)
+
+// OvsExecutor executes ovs-vsctl commands towards the OVSDB of the host
+type OvsExecutor interface {
+  Vsctl(args ...string) error
+}
+
+type vsctlExecutor struct {}
+
+// OvsExec is the OvsExecutor used by DANM to manage OVS ports. Unit tests can replace it with a fake implementation
+var OvsExec OvsExecutor = vsctlExecutor{}
+
+func (vsctlExecutor) Vsctl(args ...string) error {
+  vsctlArgs := append([]string{"--timeout=" + strconv.Itoa(OvsTimeout)}, args...)
+  output, err := exec.Command("ovs-vsctl", vsctlArgs...).CombinedOutput()
+  if err != nil {
+    return errors.New("ovs-vsctl failed with error:" + err.Error() + ", output:" + strings.TrimSpace(string(output)))
+  }
+  return nil
+}
+
+// AddOvsInterface connects a Pod to the OVS bridge of its network
+// Interfaces having a Device popped from the device_pool of the network are connected via DPDK vhost-user ports, all others via veth pairs
+func AddOvsInterface(dnet *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
+  if !netcontrol.IsOvsNetwork(dnet) {
+    return nil
+  }
+  isEpLocal, err := isEpOnThisHost(ep)
+  if !isEpLocal {
+    return err
+  }
+  if IsVhostUserEp(dnet, ep) {
+    return AddOvsPort(dnet, ep)
+  }
+  err = createVethIface(ep, getOvsBridgeMtu(dnet), func(hostVeth netlink.Link) error {
+    return AddOvsPort(dnet, ep)
+  })
+  if err != nil {
+    //The veth is already gone at this point, but its port might be still registered in OVSDB
+    DeleteOvsPort(ep)
+  }
+  return err
+}
+
+// DeleteOvsInterface removes the port of a Pod from OVS, and deletes its interface based on the related DanmEp
+func DeleteOvsInterface(ep *danmtypes.DanmEp) error {
+  err := DeleteOvsPort(ep)
+  //The container interface is deleted even if OVSDB could not be updated
+  delErr := deleteEp(ep)
+  if err != nil {
+    return err
+  }
+  return delErr
+}
+
+// AddOvsPort adds the port of a DanmEp to the OVS bridge configured as the host_device of its network
+// The VLAN of the network is set as the access tag of the port
+func AddOvsPort(dnet *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
+  bridge := dnet.Spec.Options.Device
+  portName := GetOvsPortName(ep)
+  vsctlArgs := []string{"--may-exist", "add-port", bridge, portName}
+  if dnet.Spec.Options.Vlan != 0 {
+    vsctlArgs = append(vsctlArgs, "tag=" + strconv.Itoa(dnet.Spec.Options.Vlan))
+  }
+  vsctlArgs = append(vsctlArgs, "--", "set", "Interface", portName, "external_ids:danm-endpoint-id=" + ep.Spec.EndpointID)
+  if IsVhostUserEp(dnet, ep) {
+    vsctlArgs = append(vsctlArgs, "type=dpdkvhostuserclient", "options:vhost-server-path=" + GetVhostUserSocketPath(ep))
+  }
+  err := OvsExec.Vsctl(vsctlArgs...)
+  if err != nil {
+    return errors.New("cannot add port:" + portName + " to OVS bridge:" + bridge + " because:" + err.Error())
+  }
+  return nil
+}
+
+// DeleteOvsPort removes the port of a DanmEp from OVS, if it exists
+func DeleteOvsPort(ep *danmtypes.DanmEp) error {
+  portName := GetOvsPortName(ep)
+  err := OvsExec.Vsctl("--if-exists", "del-port", portName)
+  if err != nil {
+    return errors.New("cannot delete OVS port:" + portName + " because:" + err.Error())
+  }
+  return nil
+}
+
+// GetOvsPortName returns the name of the OVS port belonging to a DanmEp
+// Veth based ports are named after the host side veth interface
+func GetOvsPortName(ep *danmtypes.DanmEp) string {
+  if ep.Spec.Iface.DeviceID != "" {
+    return VhostUserPrefix + ep.Spec.EndpointID[0:13]
+  }
+  return GetHostVethName(ep)
+}
+
+// GetVhostUserSocketPath returns the path of the vhost-user socket belonging to a DanmEp
+// The Device allocated to the interface from the device_pool of the network is the name of the socket
+func GetVhostUserSocketPath(ep *danmtypes.DanmEp) string {
+  return filepath.Join(VhostUserSocketDir, ep.Spec.Iface.DeviceID)
+}
+
+// IsVhostUserEp returns true if the DanmEp is connected to its OVS network via a DPDK vhost-user port
+func IsVhostUserEp(dnet *danmtypes.DanmNet, ep *danmtypes.DanmEp) bool {
+  return netcontrol.IsOvsNetwork(dnet) && ep.Spec.Iface.DeviceID != ""
+}
+
+func getOvsBridgeMtu(dnet *danmtypes.DanmNet) int {
+  bridge, err := netlink.LinkByName(dnet.Spec.Options.Device)
+  if err != nil {
+    return 0
+  }
+  return bridge.Attrs().MTU
+}
@@ -0,0 +1,266 @@
+package danmep
+
+import (
+  "errors"
+  "net"
+  "runtime"
+  "strconv"
+  "strings"
+  "syscall"
+  "github.com/vishvananda/netlink"
+  "github.com/containernetworking/plugins/pkg/ns"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  "github.com/nokia/danm/pkg/ipam"
+)
+
+const (
+  MaxNextHopWeight = 256
+)
+
+var routeScopes = map[string]netlink.Scope {
+  "universe": netlink.SCOPE_UNIVERSE,
+  "link":     netlink.SCOPE_LINK,
+  "host":     netlink.SCOPE_HOST,
+}
+
+// GetIpRoutesOfFamily returns the IPv6 routes of a network if isIpv6 is true, otherwise its IPv4 routes
+// The family of a route is decided by its destination
+func GetIpRoutesOfFamily(ipRoutes []danmtypes.IpRoute, isIpv6 bool) []danmtypes.IpRoute {
+  var familyRoutes []danmtypes.IpRoute
+  for _, ipRoute := range ipRoutes {
+    if strings.Contains(ipRoute.Destination, ":") == isIpv6 {
+      familyRoutes = append(familyRoutes, ipRoute)
+    }
+  }
+  return familyRoutes
+}
+
+// IsRouteScopeValid returns true if the scope can be set for an IP route
+func IsRouteScopeValid(scope string) bool {
+  if scope == "" {
+    return true
+  }
+  _, ok := routeScopes[scope]
+  return ok
+}
+
+func overwriteIpRouteGateways(ipRoutes []danmtypes.IpRoute, gw string) []danmtypes.IpRoute {
+  newRoutes := make([]danmtypes.IpRoute, len(ipRoutes))
+  for index, ipRoute := range ipRoutes {
+    newRoutes[index] = *ipRoute.DeepCopy()
+    //Directly connected destinations are left intact
+    if len(ipRoute.Gateways) > 0 {
+      newRoutes[index].Gateways = []danmtypes.RouteNextHop{danmtypes.RouteNextHop{Gateway: gw}}
+    }
+  }
+  return newRoutes
+}
+
+// RouteError lists the IP routes of an interface which could not be installed
+// Lenient is set when the network of the interface tolerates these failures, in which case the interface is otherwise fully functional
+type RouteError struct {
+  Errors []error
+  Lenient bool
+}
+
+func (routeErr *RouteError) Error() string {
+  errMsgs := make([]string, len(routeErr.Errors))
+  for index, err := range routeErr.Errors {
+    errMsgs[index] = err.Error()
+  }
+  return strings.Join(errMsgs, "; ")
+}
+
+//Must be called from within the network namespace of the Pod
+func addIpRouteList(ep *danmtypes.DanmEp, ipRoutes []danmtypes.IpRoute, allocatedIp string) []error {
+  if len(ipRoutes) == 0 || allocatedIp == "" || allocatedIp == ipam.NoneAllocType {
+    return nil
+  }
+  iface, err := netlink.LinkByName(ep.Spec.Iface.Name)
+  if err != nil {
+    return []error{errors.New("cannot find interface:" + ep.Spec.Iface.Name + " because:" + err.Error())}
+  }
+  var routeErrs []error
+  for _, ipRoute := range ipRoutes {
+    route, err := buildRoute(ipRoute, iface.Attrs().Index)
+    if err != nil {
+      routeErrs = append(routeErrs, errors.New("invalid IP route with destination:" + ipRoute.Destination + " because:" + err.Error()))
+      continue
+    }
+    err = netlink.RouteAdd(route)
+    if err != nil {
+      routeErrs = append(routeErrs, errors.New("adding IP route with destination:" + ipRoute.Destination + " failed with error:" + err.Error()))
+      continue
+    }
+    recordRoute(ep, route)
+  }
+  return routeErrs
+}
+
+//Routes are recorded together with their gateway, and output interface, so only the routes installed for this interface are deleted later
+func recordRoute(ep *danmtypes.DanmEp, route *netlink.Route) {
+  ifaceRoute := danmtypes.IfaceRoute{Destination: route.Dst.String(), OifName: ep.Spec.Iface.Name, Table: route.Table, Metric: route.Priority}
+  if route.Gw != nil {
+    ifaceRoute.Gateway = route.Gw.String()
+  }
+  ep.Spec.Iface.Routes = append(ep.Spec.Iface.Routes, ifaceRoute)
+}
+
+// DeleteIfaceRoutes removes the policy rules, and IP routes DANM installed for the interface of a DanmEp from the network namespace of its Pod
+// Rules are not bound to the lifecycle of the interface, and the interface itself might not be destroyed (e.g. SR-IOV VFs, or hot-removed interfaces of a long-lived network namespace)
+func DeleteIfaceRoutes(ep *danmtypes.DanmEp) error {
+  if len(ep.Spec.Iface.Rules) == 0 && len(ep.Spec.Iface.Routes) == 0 {
+    return nil
+  }
+  isEpLocal, err := isEpOnThisHost(ep)
+  if err != nil && !isNetnsGone(ep) {
+    return errors.New("cannot decide if the IP routes of interface:" + ep.Spec.Iface.Name + " can be removed on this host because:" + err.Error())
+  }
+  if !isEpLocal {
+    //Nothing to clean-up if the network namespace is already gone
+    return nil
+  }
+  runtime.LockOSThread()
+  defer runtime.UnlockOSThread()
+  origNs, err := ns.GetCurrentNS()
+  if err != nil {
+    return errors.New("getting current namespace failed")
+  }
+  hns, err := ns.GetNS(ep.Spec.Netns)
+  if err != nil {
+    return nil
+  }
+  defer func() {
+    hns.Close()
+    err = origNs.Set()
+    if err != nil {
+      GetEpLogger(ep).Error("Could not switch back to default ns during IP route removal:" + err.Error())
+    }
+  }()
+  err = hns.Set()
+  if err != nil {
+    return errors.New("failed to enter network namespace of CID:" + ep.Spec.Netns + " with error:" + err.Error())
+  }
+  return deleteIfaceRoutes(ep)
+}
+
+//Must be called from within the network namespace of the Pod
+func deleteIfaceRoutes(ep *danmtypes.DanmEp) error {
+  var errMsgs []string
+  for _, ifaceRoute := range ep.Spec.Iface.Routes {
+    _, dst, err := net.ParseCIDR(ifaceRoute.Destination)
+    if err != nil {
+      errMsgs = append(errMsgs, "cannot parse destination of IP route:" + ifaceRoute.Destination + " because:" + err.Error())
+      continue
+    }
+    route := &netlink.Route{Dst: dst, Table: ifaceRoute.Table, Priority: ifaceRoute.Metric}
+    if ifaceRoute.Gateway != "" {
+      route.Gw = net.ParseIP(ifaceRoute.Gateway)
+    }
+    if ifaceRoute.OifName != "" {
+      link, err := netlink.LinkByName(ifaceRoute.OifName)
+      //Routes pointing to an already deleted interface are removed by the kernel
+      if _, isLinkGone := err.(netlink.LinkNotFoundError); isLinkGone {
+        continue
+      }
+      if err != nil {
+        errMsgs = append(errMsgs, "cannot find interface:" + ifaceRoute.OifName + " of IP route with destination:" + ifaceRoute.Destination + " because:" + err.Error())
+        continue
+      }
+      route.LinkIndex = link.Attrs().Index
+    }
+    err = netlink.RouteDel(route)
+    if err != nil && !isNotFound(err) {
+      errMsgs = append(errMsgs, "removing IP route with destination:" + ifaceRoute.Destination + " failed with error:" + err.Error())
+    }
+  }
+  for _, ifaceRule := range ep.Spec.Iface.Rules {
+    rule := netlink.NewRule()
+    rule.Table = ifaceRule.Table
+    rule.Family = ifaceRule.Family
+    rule.OifName = ifaceRule.OifName
+    rule.IifName = ifaceRule.IifName
+    if ifaceRule.Src != "" {
+      srcIp, srcNet, err := net.ParseCIDR(ifaceRule.Src)
+      if err != nil {
+        errMsgs = append(errMsgs, "cannot parse source of policy rule:" + ifaceRule.Src + " because:" + err.Error())
+        continue
+      }
+      rule.Src = &net.IPNet{IP: srcIp, Mask: srcNet.Mask}
+    }
+    err := netlink.RuleDel(rule)
+    if err != nil && !isNotFound(err) {
+      errMsgs = append(errMsgs, "removing policy rule pointing to table:" + strconv.Itoa(ifaceRule.Table) + " failed with error:" + err.Error())
+    }
+  }
+  if len(errMsgs) > 0 {
+    return errors.New(strings.Join(errMsgs, "; "))
+  }
+  ep.Spec.Iface.Rules = nil
+  ep.Spec.Iface.Routes = nil
+  return nil
+}
+
+func isNetnsGone(ep *danmtypes.DanmEp) bool {
+  _, isNsGone := ns.IsNSorErr(ep.Spec.Netns).(ns.NSPathNotExistErr)
+  return isNsGone
+}
+
+func isNotFound(err error) bool {
+  return err == syscall.ESRCH || err == syscall.ENOENT
+}
+
+func buildRoute(ipRoute danmtypes.IpRoute, linkIndex int) (*netlink.Route, error) {
+  _, dst, err := net.ParseCIDR(ipRoute.Destination)
+  if err != nil {
+    return nil, errors.New("cannot parse destination:" + err.Error())
+  }
+  route := &netlink.Route {
+    LinkIndex: linkIndex,
+    Dst:       dst,
+    Priority:  ipRoute.Metric,
+    MTU:       ipRoute.Mtu,
+    Scope:     netlink.SCOPE_UNIVERSE,
+  }
+  if len(ipRoute.Gateways) == 0 {
+    route.Scope = netlink.SCOPE_LINK
+  }
+  if ipRoute.Scope != "" {
+    scope, ok := routeScopes[ipRoute.Scope]
+    if !ok {
+      return nil, errors.New("unknown scope:" + ipRoute.Scope)
+    }
+    route.Scope = scope
+  }
+  if ipRoute.Source != "" {
+    route.Src = net.ParseIP(ipRoute.Source)
+    if route.Src == nil {
+      return nil, errors.New("cannot parse source address:" + ipRoute.Source)
+    }
+  }
+  var flags int
+  if ipRoute.Onlink {
+    flags = int(netlink.FLAG_ONLINK)
+  }
+  if len(ipRoute.Gateways) == 1 {
+    route.Gw = net.ParseIP(ipRoute.Gateways[0].Gateway)
+    if route.Gw == nil {
+      return nil, errors.New("cannot parse gateway:" + ipRoute.Gateways[0].Gateway)
+    }
+    route.Flags = flags
+    return route, nil
+  }
+  for _, nextHop := range ipRoute.Gateways {
+    gw := net.ParseIP(nextHop.Gateway)
+    if gw == nil {
+      return nil, errors.New("cannot parse gateway:" + nextHop.Gateway)
+    }
+    //The kernel expects the weight minus one, and treats the default 0 as a weight of 1
+    hops := 0
+    if nextHop.Weight > 0 {
+      hops = nextHop.Weight - 1
+    }
+    route.MultiPath = append(route.MultiPath, &netlink.NexthopInfo{LinkIndex: linkIndex, Gw: gw, Hops: hops, Flags: flags})
+  }
+  return route, nil
+}
@@ -0,0 +1,83 @@
+package danmep
+
+import (
+  "errors"
+  "runtime"
+  "time"
+  "github.com/vishvananda/netlink"
+  "github.com/containernetworking/plugins/pkg/ns"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
+)
+
+const (
+  IpamStep = "IPAM"
+  LinkCreationStep = "LinkCreation"
+  PostProcessingStep = "PostProcessing"
+)
+
+// SetFailedStep records into the Status of a DanmEp which step of the interface creation failed, and why
+func SetFailedStep(ep *danmtypes.DanmEp, step string, err error) {
+  ep.Status.FailedStep = step
+  ep.Status.Error = err.Error()
+}
+
+// SetIfaceStatus records the actual MAC address, operational state, MTU, and global IP addresses of the container interface of a DanmEp into its Status
+func SetIfaceStatus(ep *danmtypes.DanmEp) error {
+  runtime.LockOSThread()
+  defer runtime.UnlockOSThread()
+  origNs, err := ns.GetCurrentNS()
+  if err != nil {
+    return errors.New("getting current namespace failed")
+  }
+  hns, err := ns.GetNS(ep.Spec.Netns)
+  if err != nil {
+    return errors.New("cannot open network namespace:" + ep.Spec.Netns)
+  }
+  defer func() {
+    hns.Close()
+    err = origNs.Set()
+    if err != nil {
+      GetEpLogger(ep).Error("Could not switch back to default ns during interface status collection:" + err.Error())
+    }
+  }()
+  err = hns.Set()
+  if err != nil {
+    return errors.New("failed to enter network namespace of CID:" + ep.Spec.Netns + " with error:" + err.Error())
+  }
+  iface, err := netlink.LinkByName(ep.Spec.Iface.Name)
+  if err != nil {
+    return errors.New("cannot find interface:" + ep.Spec.Iface.Name + " because:" + err.Error())
+  }
+  ep.Status.MacAddress = iface.Attrs().HardwareAddr.String()
+  ep.Status.OperState = iface.Attrs().OperState.String()
+  ep.Status.Mtu = iface.Attrs().MTU
+  addrs, err := netlink.AddrList(iface, netlink.FAMILY_ALL)
+  if err != nil {
+    return errors.New("cannot list IP addresses of interface:" + ep.Spec.Iface.Name + " because:" + err.Error())
+  }
+  ep.Status.Addresses = nil
+  for _, addr := range addrs {
+    if addr.Scope == int(netlink.SCOPE_UNIVERSE) {
+      ep.Status.Addresses = append(ep.Status.Addresses, addr.IPNet.String())
+    }
+  }
+  return nil
+}
+
+// UpdateDanmEpStatus is the network outage resilient way of updating the Status of a DanmEp
+func UpdateDanmEpStatus(client danmclientset.Interface, ep *danmtypes.DanmEp) error {
+  var err error
+  for i := 0; i < MaxRetryCount; i++ {
+    var newEp *danmtypes.DanmEp
+    newEp, err = client.DanmV1().DanmEps(ep.Namespace).UpdateStatus(ep)
+    if err == nil {
+      if newEp != nil {
+        ep.ObjectMeta.ResourceVersion = newEp.ObjectMeta.ResourceVersion
+      }
+      break
+    }
+    time.Sleep(RetryInterval * time.Millisecond)
+  }
+  return err
+}
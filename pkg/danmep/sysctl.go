@@ -0,0 +1,85 @@
+package danmep
+
+import (
+  "errors"
+  "sort"
+  "strconv"
+  "strings"
+  "github.com/containernetworking/plugins/pkg/utils/sysctl"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+)
+
+// AllowedSysctls contains the interface-scoped kernel parameters which can be set for container interfaces, per address family
+// Parameters managed by DANM itself (e.g. disable_ipv6) are intentionally left out
+var AllowedSysctls = map[string][]string {
+  "ipv4": []string{"accept_local", "accept_redirects", "accept_source_route", "arp_accept", "arp_announce", "arp_filter", "arp_ignore", "arp_notify",
+                   "forwarding", "log_martians", "proxy_arp", "route_localnet", "rp_filter", "send_redirects"},
+  "ipv6": []string{"accept_dad", "accept_ra", "accept_redirects", "autoconf", "dad_transmits", "forwarding", "keep_addr_on_down",
+                   "router_solicitations", "use_tempaddr"},
+}
+
+// ValidateSysctls checks if all the kernel parameters are allowed, and their values are integers
+// Keys are in "<family>.<parameter>" format, e.g. "ipv4.rp_filter"
+func ValidateSysctls(sysctls map[string]string) error {
+  for key, value := range sysctls {
+    if !IsSysctlAllowed(key) {
+      return errors.New("sysctl:" + key + " is not in the list of allowed interface-scoped kernel parameters")
+    }
+    if _, err := strconv.Atoi(value); err != nil {
+      return errors.New("value:" + value + " of sysctl:" + key + " is not an integer")
+    }
+  }
+  return nil
+}
+
+// IsSysctlAllowed returns true if the kernel parameter can be set for container interfaces
+func IsSysctlAllowed(key string) bool {
+  keyParts := strings.Split(key, ".")
+  if len(keyParts) != 2 {
+    return false
+  }
+  for _, param := range AllowedSysctls[keyParts[0]] {
+    if param == keyParts[1] {
+      return true
+    }
+  }
+  return false
+}
+
+// MergeSysctls merges the kernel parameters requested for a Pod's connection into the parameters of its network
+// Parameters requested by the Pod take precedence
+func MergeSysctls(netSysctls, podSysctls map[string]string) map[string]string {
+  if len(netSysctls) == 0 && len(podSysctls) == 0 {
+    return nil
+  }
+  sysctls := make(map[string]string, len(netSysctls) + len(podSysctls))
+  for key, value := range netSysctls {
+    sysctls[key] = value
+  }
+  for key, value := range podSysctls {
+    sysctls[key] = value
+  }
+  return sysctls
+}
+
+//Must be called from within the network namespace of the Pod, after the DANM managed kernel parameters are set so user configuration can override them
+func setUserSysctls(ep *danmtypes.DanmEp) error {
+  keys := make([]string, 0, len(ep.Spec.Iface.Sysctls))
+  for key := range ep.Spec.Iface.Sysctls {
+    keys = append(keys, key)
+  }
+  //Some parameters depend on each other, so they are always applied in the same order
+  sort.Strings(keys)
+  for _, key := range keys {
+    if !IsSysctlAllowed(key) {
+      return errors.New("sysctl:" + key + " is not allowed")
+    }
+    keyParts := strings.Split(key, ".")
+    sysctlName := "net." + keyParts[0] + ".conf." + ep.Spec.Iface.Name + "." + keyParts[1]
+    _, err := sysctl.Sysctl(sysctlName, ep.Spec.Iface.Sysctls[key])
+    if err != nil {
+      return errors.New("failed to set sysctl:" + sysctlName + " due to:" + err.Error())
+    }
+  }
+  return nil
+}
@@ -4,7 +4,9 @@ import (
   "github.com/containernetworking/cni/pkg/types"
   "github.com/containernetworking/cni/pkg/version"
   danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  "github.com/nokia/danm/pkg/logger"
   core_v1 "k8s.io/api/core/v1"
+  "k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -24,7 +26,11 @@ type NetConf struct {
   types.NetConf
   Kubeconfig          string `json:"kubeconfig"`
   CniConfigDir        string `json:"cniDir"`
+  CniConfigMap        string `json:"cniConfigMap,omitempty"`
+  CniConfigCacheDir   string `json:"cniConfigCacheDir,omitempty"`
   NamingScheme        string `json:"namingScheme"`
+  Timeout             int    `json:"timeout,omitempty"`
+  Logging             logger.Config `json:"logging,omitempty"`
 }
 
 type CniConfigReader func(netInfo *danmtypes.DanmNet, ipam IpamConfig, ep *danmtypes.DanmEp, cniVersion string) ([]byte, error)
@@ -48,6 +54,11 @@ type Interface struct {
   Ip6 string `json:"ip6,omitempty"`
   Proutes  map[string]string `json:"proutes,omitempty"`
   Proutes6 map[string]string `json:"proutes6,omitempty"`
+  Mac string `json:"mac,omitempty"`
+  Primary bool `json:"primary,omitempty"`
+  IfName string `json:"ifName,omitempty"`
+  Sysctls map[string]string `json:"sysctls,omitempty"`
+  Bandwidth danmtypes.BandwidthLimits `json:"bandwidth,omitempty"`
   DefaultIfaceName string
   Device string
   SequenceId int
@@ -72,4 +83,5 @@ type CniArgs struct {
   Interfaces []Interface
   Pod *core_v1.Pod
   DefaultNetwork *danmtypes.DanmNet
+  K8sClient kubernetes.Interface
 }
\ No newline at end of file
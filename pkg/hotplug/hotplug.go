@@ -0,0 +1,231 @@
+package hotplug
+
+import (
+  "errors"
+  "io/ioutil"
+  "strconv"
+  "strings"
+  "sync"
+  "time"
+  corev1 "k8s.io/api/core/v1"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  "k8s.io/apimachinery/pkg/fields"
+  "k8s.io/apimachinery/pkg/util/wait"
+  "k8s.io/client-go/informers"
+  "k8s.io/client-go/kubernetes"
+  "k8s.io/client-go/rest"
+  "k8s.io/client-go/tools/cache"
+  "k8s.io/client-go/util/workqueue"
+  "github.com/nokia/danm/pkg/logger"
+  "github.com/nokia/danm/pkg/metacni"
+)
+
+const (
+  danmIfDefinitionSyntax = "danm.k8s.io/interfaces"
+  // MaxHotplugRetries is the number of times a failed hot-plug is retried with exponential back-off before it is given up
+  MaxHotplugRetries = 5
+)
+
+// PodWatcher watches the Pods running on one Node, and hot-plugs, or hot-unplugs their DANM interfaces whenever their network connection annotation changes
+// Changes are queued, and handled one by one, so failed hot-plugs can be retried
+type PodWatcher struct {
+  Factory informers.SharedInformerFactory
+  Controller cache.Controller
+  Indexer cache.Indexer
+  Queue workqueue.RateLimitingInterface
+  // Hotplug reconciles the interfaces of a Pod with the change of its annotation, metacni.HotplugInterfaces by default
+  Hotplug func(oldPod, newPod *corev1.Pod) error
+  lock sync.Mutex
+  //The last version of every queued Pod whose interfaces are known to be in place
+  appliedPods map[string]*corev1.Pod
+}
+
+// NewPodWatcher initializes and returns a new PodWatcher object for the Pods of the given Node
+// Interfaces are managed with the DANM CNI configuration read from cniConfigPath, exactly like CNI ADD, and DEL would do
+func NewPodWatcher(cfg *rest.Config, nodeName, cniConfigPath string) (*PodWatcher,error) {
+  if nodeName == "" {
+    return nil, errors.New("name of the Node is mandatory to hot-plug interfaces")
+  }
+  cniConfig, err := ioutil.ReadFile(cniConfigPath)
+  if err != nil {
+    return nil, errors.New("cannot read DANM CNI config file:" + cniConfigPath + " because:" + err.Error())
+  }
+  err = metacni.LoadNetConf(cniConfig)
+  if err != nil {
+    return nil, err
+  }
+  k8sClient, err := kubernetes.NewForConfig(cfg)
+  if err != nil {
+    return nil, err
+  }
+  factory := informers.NewSharedInformerFactoryWithOptions(k8sClient, time.Minute*10,
+    informers.WithTweakListOptions(func(options *meta_v1.ListOptions) {
+      options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+    }))
+  podInformer := factory.Core().V1().Pods().Informer()
+  podWatcher := NewPodQueue(podInformer.GetIndexer(), metacni.HotplugInterfaces)
+  podWatcher.Factory = factory
+  podWatcher.Controller = podInformer
+  podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+    UpdateFunc: podWatcher.UpdatePod,
+    DeleteFunc: podWatcher.DeletePod,
+  })
+  return podWatcher, nil
+}
+
+// NewPodQueue returns a PodWatcher without an informer, which hot-plugs the Pods stored in the given Indexer with the given function
+func NewPodQueue(indexer cache.Indexer, hotplug func(oldPod, newPod *corev1.Pod) error) *PodWatcher {
+  return &PodWatcher {
+    Indexer: indexer,
+    Queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Pods"),
+    Hotplug: hotplug,
+    appliedPods: make(map[string]*corev1.Pod),
+  }
+}
+
+//Pods are hot-plugged by a single worker, because the CNI environment of the process always belongs to the Pod currently handled
+func (podWatcher *PodWatcher) Run(stopCh *chan struct{}) {
+  go podWatcher.Controller.Run(*stopCh)
+  go wait.Until(podWatcher.runWorker, time.Second, *stopCh)
+  go func() {
+    <-*stopCh
+    podWatcher.Queue.ShutDown()
+  }()
+}
+
+func (podWatcher *PodWatcher) UpdatePod(oldObj, newObj interface{}) {
+  oldPod, isPod := oldObj.(*corev1.Pod)
+  if !isPod {
+    logger.Error("Can't hot-plug interfaces for Pod change, 'cause we have received an invalid old object from the K8s API server")
+    return
+  }
+  newPod, isPod := newObj.(*corev1.Pod)
+  if !isPod {
+    logger.Error("Can't hot-plug interfaces for Pod change, 'cause we have received an invalid new object from the K8s API server")
+    return
+  }
+  if !IsHotplugNeeded(oldPod, newPod) {
+    return
+  }
+  key, err := cache.MetaNamespaceKeyFunc(newPod)
+  if err != nil {
+    getPodLogger(newPod).Error("Can't hot-plug interfaces of the Pod, because its key could not be created:" + err.Error())
+    return
+  }
+  podWatcher.lock.Lock()
+  //When an earlier change is still queued the reconciliation starts from the last successfully applied version, not from the previous one
+  if _, isQueued := podWatcher.appliedPods[key]; !isQueued {
+    podWatcher.appliedPods[key] = oldPod
+  }
+  podWatcher.lock.Unlock()
+  podWatcher.Queue.Add(key)
+}
+
+func (podWatcher *PodWatcher) DeletePod(obj interface{}) {
+  key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+  if err != nil {
+    return
+  }
+  podWatcher.forgetPod(key)
+}
+
+func (podWatcher *PodWatcher) runWorker() {
+  for podWatcher.ProcessNextPod() {
+  }
+}
+
+// ProcessNextPod hot-plugs the next queued Pod, and returns false when the queue is shut down
+// Failed hot-plugs are re-queued with exponential back-off at most MaxHotplugRetries times
+func (podWatcher *PodWatcher) ProcessNextPod() bool {
+  obj, shutdown := podWatcher.Queue.Get()
+  if shutdown {
+    return false
+  }
+  defer podWatcher.Queue.Done(obj)
+  key := obj.(string)
+  err := podWatcher.syncPod(key)
+  if err == nil {
+    podWatcher.Queue.Forget(obj)
+    return true
+  }
+  namespace, name, _ := cache.SplitMetaNamespaceKey(key)
+  podLog := logger.WithFields(logger.Fields{logger.PodField: name, logger.NamespaceField: namespace})
+  if podWatcher.Queue.NumRequeues(obj) < MaxHotplugRetries {
+    podLog.Warning("Hot-plugging interfaces of the Pod failed, it will be retried:" + err.Error())
+    podWatcher.Queue.AddRateLimited(obj)
+    return true
+  }
+  podLog.Error("Hot-plugging interfaces of the Pod failed " + strconv.Itoa(MaxHotplugRetries + 1) + " times, giving up:" + err.Error())
+  podWatcher.Queue.Forget(obj)
+  podWatcher.forgetPod(key)
+  return true
+}
+
+func (podWatcher *PodWatcher) syncPod(key string) error {
+  podWatcher.lock.Lock()
+  oldPod, isQueued := podWatcher.appliedPods[key]
+  podWatcher.lock.Unlock()
+  if !isQueued {
+    return nil
+  }
+  obj, exists, err := podWatcher.Indexer.GetByKey(key)
+  if err != nil {
+    return err
+  }
+  //The Pod was deleted, or re-created in the meantime, its interfaces are handled by CNI from now on
+  if !exists || obj.(*corev1.Pod).ObjectMeta.UID != oldPod.ObjectMeta.UID {
+    podWatcher.forgetPod(key)
+    return nil
+  }
+  newPod := obj.(*corev1.Pod)
+  if !IsHotplugNeeded(oldPod, newPod) {
+    podWatcher.forgetPod(key)
+    return nil
+  }
+  err = podWatcher.Hotplug(oldPod, newPod)
+  if err != nil {
+    return err
+  }
+  podWatcher.lock.Lock()
+  defer podWatcher.lock.Unlock()
+  //A newer version of the Pod might have arrived during the hot-plug, that change is reconciled in the next round
+  latestObj, exists, _ := podWatcher.Indexer.GetByKey(key)
+  if exists && getConnectionAnnotation(latestObj.(*corev1.Pod)) != getConnectionAnnotation(newPod) {
+    podWatcher.appliedPods[key] = newPod
+  } else {
+    delete(podWatcher.appliedPods, key)
+  }
+  return nil
+}
+
+func (podWatcher *PodWatcher) forgetPod(key string) {
+  podWatcher.lock.Lock()
+  delete(podWatcher.appliedPods, key)
+  podWatcher.lock.Unlock()
+}
+
+func getPodLogger(pod *corev1.Pod) *logger.Entry {
+  return logger.WithFields(logger.Fields{logger.PodField: pod.ObjectMeta.Name, logger.NamespaceField: pod.ObjectMeta.Namespace})
+}
+
+// IsHotplugNeeded returns true if the network connections of a running Pod have changed
+// Periodic re-syncs, and changes of terminating Pods are ignored, the interfaces of the latter are removed by CNI DEL
+// Pending Pods are also ignored, because their CNI ADD might still be in progress
+func IsHotplugNeeded(oldPod, newPod *corev1.Pod) bool {
+  if newPod.ObjectMeta.DeletionTimestamp != nil || newPod.Spec.HostNetwork {
+    return false
+  }
+  if oldPod.Status.Phase != corev1.PodRunning || newPod.Status.Phase != corev1.PodRunning {
+    return false
+  }
+  return getConnectionAnnotation(oldPod) != getConnectionAnnotation(newPod)
+}
+
+func getConnectionAnnotation(pod *corev1.Pod) string {
+  for key, val := range pod.ObjectMeta.Annotations {
+    if strings.Contains(key, danmIfDefinitionSyntax) {
+      return val
+    }
+  }
+  return ""
+}
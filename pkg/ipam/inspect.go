@@ -0,0 +1,216 @@
+package ipam
+
+import (
+  "errors"
+  "net"
+  "strings"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
+  "github.com/nokia/danm/pkg/bitarray"
+)
+
+// PoolUsage is the number of all, and reserved addresses in the allocation pool of a network
+type PoolUsage struct {
+  Used uint32
+  Total uint32
+}
+
+// GetPoolUsage decodes the IPv4, and IPv6 allocation bitmaps of a network, and counts the reserved addresses within their allocation pools
+func GetPoolUsage(netInfo *danmtypes.DanmNet) (PoolUsage, PoolUsage) {
+  var usage4, usage6 PoolUsage
+  walkPool(netInfo.Spec.Options.Pool, netInfo.Spec.Options.Alloc, netInfo.Spec.Options.Cidr, func(index uint32, isReserved bool) bool {
+    usage4.Total++
+    if isReserved {
+      usage4.Used++
+    }
+    return true
+  })
+  walkPool(netInfo.Spec.Options.Pool6.IpPool, netInfo.Spec.Options.Alloc6, netInfo.Spec.Options.Pool6.Cidr, func(index uint32, isReserved bool) bool {
+    usage6.Total++
+    if isReserved {
+      usage6.Used++
+    }
+    return true
+  })
+  return usage4, usage6
+}
+
+// GetFreeIps returns at most limit unreserved addresses from the IPv4, and IPv6 allocation pools of a network, IPv4 addresses first
+func GetFreeIps(netInfo *danmtypes.DanmNet, limit int) []string {
+  freeIps := make([]string, 0)
+  collectFreeIps := func(pool danmtypes.IpPool, alloc, allocCidr string) {
+    _, allocSubnet, err := net.ParseCIDR(allocCidr)
+    if err != nil {
+      return
+    }
+    walkPool(pool, alloc, allocCidr, func(index uint32, isReserved bool) bool {
+      if len(freeIps) >= limit {
+        return false
+      }
+      if !isReserved {
+        ip := getIpFromIndex(index, allocSubnet, allocSubnet)
+        freeIps = append(freeIps, strings.Split(ip, "/")[0])
+      }
+      return true
+    })
+  }
+  collectFreeIps(netInfo.Spec.Options.Pool, netInfo.Spec.Options.Alloc, netInfo.Spec.Options.Cidr)
+  collectFreeIps(netInfo.Spec.Options.Pool6.IpPool, netInfo.Spec.Options.Alloc6, netInfo.Spec.Options.Pool6.Cidr)
+  return freeIps
+}
+
+//Calls visit for every position of the allocation pool until it returns false
+func walkPool(pool danmtypes.IpPool, alloc, allocCidr string, visit func(uint32, bool) bool) {
+  if alloc == "" || allocCidr == "" || pool.Start == "" || pool.End == "" {
+    return
+  }
+  _, allocSubnet, err := net.ParseCIDR(allocCidr)
+  if err != nil {
+    return
+  }
+  ba := bitarray.NewBitArrayFromBase64(alloc)
+  begin, end := getAllocRangeBasedOnCidr(&pool, allocSubnet)
+  for index := begin; index <= end && index < ba.Len(); index++ {
+    if !visit(index, ba.Get(index)) {
+      return
+    }
+  }
+}
+
+// RebuildAllocations recreates the IPv4, and IPv6 allocation bitmaps of a network purely from the addresses of its DanmEps,
+// and the addresses DANM always reserves: the network, broadcast, and gateway addresses
+// L2 networks, and the IP families the network does not have are left untouched
+func RebuildAllocations(netInfo *danmtypes.DanmNet, eps []danmtypes.DanmEp) (string, string) {
+  addresses := make([]string, 0, len(eps))
+  addresses6 := make([]string, 0, len(eps))
+  for _, ep := range eps {
+    addresses = append(addresses, ep.Spec.Iface.Address)
+    addresses6 = append(addresses6, ep.Spec.Iface.AddressIPv6)
+  }
+  alloc := rebuildAllocation(netInfo.Spec.Options.Alloc, netInfo.Spec.Options.Cidr,
+    GetRouteGateways(netInfo.Spec.Options.Routes, netInfo.Spec.Options.IpRoutes), addresses)
+  alloc6 := rebuildAllocation(netInfo.Spec.Options.Alloc6, netInfo.Spec.Options.Pool6.Cidr,
+    GetRouteGateways(netInfo.Spec.Options.Routes6, netInfo.Spec.Options.IpRoutes), addresses6)
+  return alloc, alloc6
+}
+
+func rebuildAllocation(alloc, allocCidr string, routes map[string]string, addresses []string) string {
+  if alloc == "" || allocCidr == "" {
+    return alloc
+  }
+  _, subnet, err := net.ParseCIDR(allocCidr)
+  if err != nil {
+    return alloc
+  }
+  bitArray, err := bitarray.CreateBitArrayFromIpnet(subnet)
+  if err != nil {
+    return alloc
+  }
+  reserveGatewayIps(routes, bitArray, subnet)
+  for _, address := range addresses {
+    ip := net.ParseIP(strings.Split(address, "/")[0])
+    if ip == nil || !subnet.Contains(ip) {
+      continue
+    }
+    bitArray.Set(GetIndexOfIp(ip, subnet))
+  }
+  return bitArray.Encode()
+}
+
+// DiffAllocations returns the addresses reserved in the original allocation bitmap, but free in the rebuilt one (leaked),
+// and the addresses free in the original, but reserved in the rebuilt one (missing)
+func DiffAllocations(origAlloc, rebuiltAlloc, allocCidr string) ([]string, []string) {
+  var leaked, missing []string
+  _, subnet, err := net.ParseCIDR(allocCidr)
+  if err != nil || origAlloc == rebuiltAlloc {
+    return leaked, missing
+  }
+  orig := bitarray.NewBitArrayFromBase64(origAlloc)
+  rebuilt := bitarray.NewBitArrayFromBase64(rebuiltAlloc)
+  for index := uint32(0); index < orig.Len() && index < rebuilt.Len(); index++ {
+    if orig.Get(index) == rebuilt.Get(index) {
+      continue
+    }
+    ip := strings.Split(getIpFromIndex(index, subnet, subnet), "/")[0]
+    if orig.Get(index) {
+      leaked = append(leaked, ip)
+    } else {
+      missing = append(missing, ip)
+    }
+  }
+  return leaked, missing
+}
+
+//Only the differences between the original, and the rebuilt bitmaps are applied onto the current one,
+//so addresses reserved, or freed by CNI calls since the original was read are kept intact
+func mergeAllocations(currentAlloc, origAlloc, rebuiltAlloc string) string {
+  if origAlloc == rebuiltAlloc {
+    return currentAlloc
+  }
+  current := bitarray.NewBitArrayFromBase64(currentAlloc)
+  orig := bitarray.NewBitArrayFromBase64(origAlloc)
+  rebuilt := bitarray.NewBitArrayFromBase64(rebuiltAlloc)
+  if current.Len() != rebuilt.Len() || orig.Len() != rebuilt.Len() {
+    return rebuiltAlloc
+  }
+  for index := uint32(0); index < rebuilt.Len(); index++ {
+    if orig.Get(index) == rebuilt.Get(index) {
+      continue
+    }
+    if rebuilt.Get(index) {
+      current.Set(index)
+    } else {
+      current.Reset(index)
+    }
+  }
+  return current.Encode()
+}
+
+//Leaked IPs not listed in confirmedLeaks stay reserved in the rebuilt bitmap
+func keepUnconfirmedLeaks(origAlloc, rebuiltAlloc, allocCidr string, confirmedLeaks []string) string {
+  leaked, _ := DiffAllocations(origAlloc, rebuiltAlloc, allocCidr)
+  if len(leaked) == 0 {
+    return rebuiltAlloc
+  }
+  _, subnet, _ := net.ParseCIDR(allocCidr)
+  rebuilt := bitarray.NewBitArrayFromBase64(rebuiltAlloc)
+  for _, ip := range leaked {
+    if !isIpListed(ip, confirmedLeaks) {
+      rebuilt.Set(GetIndexOfIp(net.ParseIP(ip), subnet))
+    }
+  }
+  return rebuilt.Encode()
+}
+
+func isIpListed(ip string, ips []string) bool {
+  for _, listedIp := range ips {
+    if listedIp == ip {
+      return true
+    }
+  }
+  return false
+}
+
+// RepairAllocations overwrites the allocation bitmaps of a network in the API server with the ones rebuilt from its DanmEps
+// IPs reserved without a DanmEp are only freed if they are also listed in confirmedLeaks, because Pods being created have their IPs reserved before their DanmEps exist.
+// Callers shall therefore confirm the leaked IPs by inspecting the network again after a grace period, and only pass the IPs found leaked both times
+func RepairAllocations(danmClient danmclientset.Interface, netInfo danmtypes.DanmNet, eps []danmtypes.DanmEp, confirmedLeaks []string) error {
+  origAlloc, origAlloc6 := netInfo.Spec.Options.Alloc, netInfo.Spec.Options.Alloc6
+  rebuiltAlloc, rebuiltAlloc6 := RebuildAllocations(&netInfo, eps)
+  rebuiltAlloc = keepUnconfirmedLeaks(origAlloc, rebuiltAlloc, netInfo.Spec.Options.Cidr, confirmedLeaks)
+  rebuiltAlloc6 = keepUnconfirmedLeaks(origAlloc6, rebuiltAlloc6, netInfo.Spec.Options.Pool6.Cidr, confirmedLeaks)
+  tempNet := netInfo
+  for {
+    tempNet.Spec.Options.Alloc = mergeAllocations(tempNet.Spec.Options.Alloc, origAlloc, rebuiltAlloc)
+    tempNet.Spec.Options.Alloc6 = mergeAllocations(tempNet.Spec.Options.Alloc6, origAlloc6, rebuiltAlloc6)
+    retryNeeded, err, newNet := updateIpAllocation(danmClient, tempNet)
+    if err != nil {
+      return errors.New("allocations of network:" + netInfo.ObjectMeta.Name + " cannot be updated:" + err.Error())
+    }
+    if retryNeeded {
+      tempNet = newNet
+      continue
+    }
+    return nil
+  }
+}
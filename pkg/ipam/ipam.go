@@ -20,6 +20,7 @@ import (
 const (
   NoneAllocType = "none"
   DynamicAllocType = "dynamic"
+  poolExhaustedError = "IP address cannot be dynamically allocated, all addresses are reserved!"
 )
 
 // Reserve inspects the network object received as an input, and allocates an IPv4 or IPv6 address from the appropriate allocation pool
@@ -108,10 +109,15 @@ func allocateIps(netInfo *danmtypes.DanmNet, req4, req6 string) (string, string,
   return ip4, ip6, err
 }
 
+// IsPoolExhausted returns true if the IP allocation failed because all the addresses of the network's allocation pool are already reserved
+func IsPoolExhausted(err error) bool {
+  return err != nil && strings.Contains(err.Error(), poolExhaustedError)
+}
+
 func InitV6AllocFields(netInfo *danmtypes.DanmNet) {
   InitV6PoolCidr(netInfo)
   netInfo.Spec.Options.Pool6.Start, netInfo.Spec.Options.Pool6.End, netInfo.Spec.Options.Alloc6 =
-    InitAllocPool(netInfo.Spec.Options.Pool6.Cidr, netInfo.Spec.Options.Pool6.Start, netInfo.Spec.Options.Pool6.End, netInfo.Spec.Options.Alloc6, netInfo.Spec.Options.Routes6)
+    InitAllocPool(netInfo.Spec.Options.Pool6.Cidr, netInfo.Spec.Options.Pool6.Start, netInfo.Spec.Options.Pool6.End, netInfo.Spec.Options.Alloc6, GetRouteGateways(netInfo.Spec.Options.Routes6, netInfo.Spec.Options.IpRoutes))
 }
 
 func allocateAddress(pool *danmtypes.IpPool, alloc, reqType, allocCidr, netCidr string) (string,string,error) {
@@ -151,7 +157,7 @@ func allocateAddress(pool *danmtypes.IpPool, alloc, reqType, allocCidr, netCidr
       }
     }
     if !doesAnyFreeIpExist {
-      return alloc, "", errors.New("IP address cannot be dynamically allocated, all addresses are reserved!")
+      return alloc, "", errors.New(poolExhaustedError)
     }
     allocatedIp = getIpFromIndex(allocatedIndex, allocSubnet, netSubnet)
     pool.LastIp = allocatedIp
@@ -301,8 +307,29 @@ func CreateAllocationArray(subnet *net.IPNet, routes map[string]string) string {
   return bitArray.Encode()
 }
 
+// GetRouteGateways collects the gateways of both the simple, and the structured IP routes of a network, so they can be reserved in its allocation pool
+func GetRouteGateways(routes map[string]string, ipRoutes []danmtypes.IpRoute) map[string]string {
+  if len(ipRoutes) == 0 {
+    return routes
+  }
+  gateways := make(map[string]string, len(routes))
+  for dst, gw := range routes {
+    gateways[dst] = gw
+  }
+  for _, ipRoute := range ipRoutes {
+    for _, nextHop := range ipRoute.Gateways {
+      gateways[ipRoute.Destination + "_" + nextHop.Gateway] = nextHop.Gateway
+    }
+  }
+  return gateways
+}
+
 func reserveGatewayIps(routes map[string]string, bitArray *bitarray.BitArray, subnet *net.IPNet) {
   for _, gw := range routes {
+    //Onlink gateways, and the gateways of the other IP family are not part of the subnet
+    if !subnet.Contains(net.ParseIP(gw)) {
+      continue
+    }
     gatewayPosition := GetIndexOfIp(net.ParseIP(gw), subnet)
     if gatewayPosition <= bitArray.Len() {
       bitArray.Set(gatewayPosition)
@@ -0,0 +1,252 @@
+// Package logger is the common, levelled, structured logging layer of the DANM CNI binary and daemons
+// Entries are written either in logfmt, or in JSON format, and can be correlated by their fields, e.g. the Pod, or the network they belong to
+// Output of the standard library's log package is also routed through the logger, its "ERROR:", "WARNING:", "INFO:", and "DEBUG:" prefixes are converted to levels
+package logger
+
+import (
+  "encoding/json"
+  "errors"
+  "flag"
+  "io"
+  "log"
+  "os"
+  "sort"
+  "strconv"
+  "strings"
+  "sync"
+  "time"
+)
+
+const (
+  DebugLevel = "debug"
+  InfoLevel = "info"
+  WarningLevel = "warning"
+  ErrorLevel = "error"
+  LogfmtFormat = "logfmt"
+  JsonFormat = "json"
+  PodField = "pod"
+  NamespaceField = "namespace"
+  CidField = "cid"
+  NetworkField = "network"
+  EndpointIdField = "endpointid"
+  IfaceField = "iface"
+  timeFormat = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// Config describes where, in which format, and from which level entries are logged
+// When File is empty entries are written to standard error, otherwise the file is rotated after reaching MaxSize megabytes
+type Config struct {
+  File       string `json:"file,omitempty"`
+  Format     string `json:"format,omitempty"`
+  Level      string `json:"level,omitempty"`
+  MaxSize    int    `json:"maxSize,omitempty"`
+  MaxBackups int    `json:"maxBackups,omitempty"`
+}
+
+// Fields are the key-value pairs attached to log entries to correlate them
+type Fields map[string]string
+
+// Entry is a set of Fields, all the messages logged through it carry the same Fields
+type Entry struct {
+  fields Fields
+}
+
+var (
+  lock sync.Mutex
+  output io.Writer = os.Stderr
+  format = LogfmtFormat
+  minLevel = levels[InfoLevel]
+  defaultFields = Fields{}
+  levels = map[string]int{DebugLevel: 0, InfoLevel: 1, WarningLevel: 2, ErrorLevel: 3}
+  stdPrefixes = map[string]string{"DEBUG:": DebugLevel, "INFO:": InfoLevel, "WARNING:": WarningLevel, "ERROR:": ErrorLevel}
+)
+
+// Configure replaces the output, format, and level of the logger, and routes the standard log package through it
+// Nothing is changed if the configuration is invalid
+func Configure(config Config) error {
+  if config.Format == "" {
+    config.Format = LogfmtFormat
+  }
+  if config.Format != LogfmtFormat && config.Format != JsonFormat {
+    return errors.New("log format:" + config.Format + " is not one of " + LogfmtFormat + ", or " + JsonFormat)
+  }
+  if config.Level == "" {
+    config.Level = InfoLevel
+  }
+  level, ok := levels[config.Level]
+  if !ok {
+    return errors.New("log level:" + config.Level + " is not one of debug, info, warning, or error")
+  }
+  var newOutput io.Writer = os.Stderr
+  if config.File != "" {
+    file, err := OpenRotatingFile(config.File, config.MaxSize, config.MaxBackups)
+    if err != nil {
+      return err
+    }
+    newOutput = file
+  }
+  lock.Lock()
+  if oldFile, ok := output.(*RotatingFile); ok {
+    oldFile.Close()
+  }
+  output = newOutput
+  format = config.Format
+  minLevel = level
+  lock.Unlock()
+  log.SetFlags(0)
+  log.SetPrefix("")
+  log.SetOutput(stdWriter{})
+  return nil
+}
+
+// AddFlags registers the command line flags configuring the logging of the daemons
+func AddFlags(config *Config) {
+  flag.StringVar(&config.File, "logFile", "", "path of the log file, rotated after reaching -logMaxSize megabytes. Logs are written to standard error when empty")
+  flag.StringVar(&config.Format, "logFormat", LogfmtFormat, "format of the log entries, one of logfmt, or json")
+  flag.StringVar(&config.Level, "logLevel", InfoLevel, "minimum level of the logged entries, one of debug, info, warning, or error")
+  flag.IntVar(&config.MaxSize, "logMaxSize", DefaultMaxSize, "size of the log file in megabytes after which it is rotated")
+  flag.IntVar(&config.MaxBackups, "logMaxBackups", DefaultMaxBackups, "number of rotated log files kept")
+}
+
+// SetDefaultFields adds Fields to every entry logged by the process from now on, e.g. the Pod handled by a CNI call
+func SetDefaultFields(fields Fields) {
+  lock.Lock()
+  defer lock.Unlock()
+  for key, value := range fields {
+    defaultFields[key] = value
+  }
+}
+
+// WithFields returns an Entry carrying the given Fields
+func WithFields(fields Fields) *Entry {
+  return &Entry{fields: fields}
+}
+
+// WithFields returns a new Entry carrying the Fields of the original Entry, extended with the given ones
+func (entry *Entry) WithFields(fields Fields) *Entry {
+  newFields := Fields{}
+  for key, value := range entry.fields {
+    newFields[key] = value
+  }
+  for key, value := range fields {
+    newFields[key] = value
+  }
+  return &Entry{fields: newFields}
+}
+
+func (entry *Entry) Debug(msg string) {
+  entry.log(DebugLevel, msg)
+}
+
+func (entry *Entry) Info(msg string) {
+  entry.log(InfoLevel, msg)
+}
+
+func (entry *Entry) Warning(msg string) {
+  entry.log(WarningLevel, msg)
+}
+
+func (entry *Entry) Error(msg string) {
+  entry.log(ErrorLevel, msg)
+}
+
+// Fatal logs the message on error level, then exits the process
+func (entry *Entry) Fatal(msg string) {
+  entry.log(ErrorLevel, msg)
+  os.Exit(1)
+}
+
+func Debug(msg string) {
+  WithFields(nil).Debug(msg)
+}
+
+func Info(msg string) {
+  WithFields(nil).Info(msg)
+}
+
+func Warning(msg string) {
+  WithFields(nil).Warning(msg)
+}
+
+func Error(msg string) {
+  WithFields(nil).Error(msg)
+}
+
+func Fatal(msg string) {
+  WithFields(nil).Fatal(msg)
+}
+
+func (entry *Entry) log(level, msg string) {
+  lock.Lock()
+  defer lock.Unlock()
+  if levels[level] < minLevel {
+    return
+  }
+  fields := Fields{}
+  for key, value := range defaultFields {
+    fields[key] = value
+  }
+  for key, value := range entry.fields {
+    fields[key] = value
+  }
+  _, err := output.Write(formatEntry(time.Now(), level, msg, fields))
+  if err != nil && output != os.Stderr {
+    os.Stderr.WriteString("cannot write log entry:" + err.Error() + "\n")
+  }
+}
+
+//The fixed keys always come first, the Fields follow in alphabetical order, so entries are easy to read, and to diff
+func formatEntry(now time.Time, level, msg string, fields Fields) []byte {
+  keys := make([]string, 0, len(fields))
+  for key := range fields {
+    keys = append(keys, key)
+  }
+  sort.Strings(keys)
+  var line strings.Builder
+  if format == JsonFormat {
+    line.WriteString(`{"time":` + jsonString(now.Format(timeFormat)) + `,"level":` + jsonString(level) + `,"msg":` + jsonString(msg))
+    for _, key := range keys {
+      line.WriteString("," + jsonString(key) + ":" + jsonString(fields[key]))
+    }
+    line.WriteString("}\n")
+    return []byte(line.String())
+  }
+  line.WriteString("time=" + now.Format(timeFormat) + " level=" + level + " msg=" + logfmtString(msg))
+  for _, key := range keys {
+    line.WriteString(" " + key + "=" + logfmtString(fields[key]))
+  }
+  line.WriteString("\n")
+  return []byte(line.String())
+}
+
+func jsonString(value string) string {
+  encoded, _ := json.Marshal(value)
+  return string(encoded)
+}
+
+func logfmtString(value string) string {
+  if value == "" || strings.ContainsAny(value, " =\"\t\n\r") || strconv.Quote(value) != "\"" + value + "\"" {
+    return strconv.Quote(value)
+  }
+  return value
+}
+
+//stdWriter converts the free-form lines of the standard log package into levelled entries
+type stdWriter struct {}
+
+func (writer stdWriter) Write(p []byte) (int, error) {
+  for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+    level, msg := parseStdLine(line)
+    WithFields(nil).log(level, msg)
+  }
+  return len(p), nil
+}
+
+func parseStdLine(line string) (string, string) {
+  for prefix, level := range stdPrefixes {
+    if strings.HasPrefix(line, prefix) {
+      return level, strings.TrimSpace(strings.TrimPrefix(line, prefix))
+    }
+  }
+  return InfoLevel, line
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+  "errors"
+  "os"
+  "strconv"
+  "syscall"
+)
+
+const (
+  DefaultMaxSize = 10
+  DefaultMaxBackups = 5
+  megabyte = 1024 * 1024
+)
+
+// RotatingFile is a log file which is renamed to <path>.1 when it reaches its maximum size, while the older backups are shifted to <path>.2, <path>.3 etc.
+// The same file can be written by multiple processes, e.g. parallel CNI calls: rotations are serialized by a file lock,
+// and every process reopens the file as soon as it notices somebody else has rotated it
+type RotatingFile struct {
+  path string
+  maxSize int64
+  maxBackups int
+  file *os.File
+}
+
+// OpenRotatingFile opens, or creates the log file, with DefaultMaxSize, and DefaultMaxBackups if the limits are not set
+func OpenRotatingFile(path string, maxSize, maxBackups int) (*RotatingFile, error) {
+  if maxSize <= 0 {
+    maxSize = DefaultMaxSize
+  }
+  if maxBackups <= 0 {
+    maxBackups = DefaultMaxBackups
+  }
+  rotatingFile := &RotatingFile{path: path, maxSize: int64(maxSize) * megabyte, maxBackups: maxBackups}
+  err := rotatingFile.open()
+  if err != nil {
+    return nil, err
+  }
+  return rotatingFile, nil
+}
+
+func (rotatingFile *RotatingFile) Write(p []byte) (int, error) {
+  err := rotatingFile.reopenIfRotated()
+  if err != nil {
+    return 0, err
+  }
+  info, err := rotatingFile.file.Stat()
+  if err == nil && info.Size() > 0 && info.Size() + int64(len(p)) > rotatingFile.maxSize {
+    err = rotatingFile.rotate()
+    if err != nil {
+      os.Stderr.WriteString("cannot rotate log file:" + rotatingFile.path + " because:" + err.Error() + "\n")
+    }
+  }
+  return rotatingFile.file.Write(p)
+}
+
+func (rotatingFile *RotatingFile) Close() error {
+  return rotatingFile.file.Close()
+}
+
+func (rotatingFile *RotatingFile) open() error {
+  file, err := os.OpenFile(rotatingFile.path, os.O_WRONLY | os.O_CREATE | os.O_APPEND, 0640)
+  if err != nil {
+    return errors.New("cannot open log file:" + rotatingFile.path + " because:" + err.Error())
+  }
+  rotatingFile.file = file
+  return nil
+}
+
+func (rotatingFile *RotatingFile) reopenIfRotated() error {
+  if !rotatingFile.isRotated() {
+    return nil
+  }
+  oldFile := rotatingFile.file
+  err := rotatingFile.open()
+  if err != nil {
+    return err
+  }
+  oldFile.Close()
+  return nil
+}
+
+func (rotatingFile *RotatingFile) isRotated() bool {
+  pathInfo, err := os.Stat(rotatingFile.path)
+  if err != nil {
+    return true
+  }
+  fileInfo, err := rotatingFile.file.Stat()
+  if err != nil {
+    return true
+  }
+  return !os.SameFile(pathInfo, fileInfo)
+}
+
+func (rotatingFile *RotatingFile) rotate() error {
+  oldFile := rotatingFile.file
+  err := syscall.Flock(int(oldFile.Fd()), syscall.LOCK_EX)
+  if err != nil {
+    return err
+  }
+  defer syscall.Flock(int(oldFile.Fd()), syscall.LOCK_UN)
+  //Another process might have rotated the file while we were waiting for the lock
+  if !rotatingFile.isRotated() {
+    for index := rotatingFile.maxBackups - 1; index > 0; index-- {
+      os.Rename(rotatingFile.backupPath(index), rotatingFile.backupPath(index + 1))
+    }
+    err = os.Rename(rotatingFile.path, rotatingFile.backupPath(1))
+    if err != nil {
+      return err
+    }
+  }
+  err = rotatingFile.open()
+  if err != nil {
+    rotatingFile.file = oldFile
+    return err
+  }
+  defer oldFile.Close()
+  return nil
+}
+
+func (rotatingFile *RotatingFile) backupPath(index int) string {
+  return rotatingFile.path + "." + strconv.Itoa(index)
+}
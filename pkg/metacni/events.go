@@ -0,0 +1,195 @@
+package metacni
+
+import (
+  "hash/fnv"
+  "os"
+  "strconv"
+  "sync"
+  "time"
+  corev1 "k8s.io/api/core/v1"
+  k8serrors "k8s.io/apimachinery/pkg/api/errors"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  "k8s.io/client-go/kubernetes"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  "github.com/nokia/danm/pkg/logger"
+  "github.com/nokia/danm/pkg/netcontrol"
+)
+
+const (
+  eventSourceComponent = "danm"
+  routeFailureReason = "RouteInstallationFailed"
+  invalidAnnotationReason = "InvalidNetworkAnnotation"
+  networkNotFoundReason = "NetworkNotFound"
+  tenantNotAllowedReason = "TenantNotAllowed"
+  ipPoolExhaustedReason = "IpPoolExhausted"
+  ipAllocationFailedReason = "IpAllocationFailed"
+  devicePoolEmptyReason = "DevicePoolEmpty"
+  delegateFailedReason = "DelegateFailed"
+  interfaceSetupFailedReason = "InterfaceSetupFailed"
+  //The same Event is updated at most once in every interval, no matter how many times kubelet retries the sandbox creation
+  eventRateLimitInterval = time.Minute
+  //Events still in-flight after this timeout are dropped, so the CNI result is never delayed more than this
+  eventFlushTimeout = 2 * time.Second
+  eventFlushPollInterval = 10 * time.Millisecond
+  maxInFlightEvents = 10
+  maxEventNameLength = 253
+)
+
+type podEventRecorder struct {
+  client kubernetes.Interface
+  lock sync.Mutex
+  numOfInFlight int
+  lastRecorded map[string]time.Time
+}
+
+var podEvents = &podEventRecorder{lastRecorded: make(map[string]time.Time)}
+
+//Recording the Event is best effort, failing to do so shall never fail, or delay the CNI operation itself
+//Events are posted asynchronously, and are only guaranteed to reach the API server if flushPodEvents is called before the process exits
+func recordPodWarning(pod *corev1.Pod, reason, message string) {
+  if pod == nil {
+    return
+  }
+  client, ok := podEvents.reserve(pod, reason, message)
+  if !ok {
+    return
+  }
+  go func() {
+    defer podEvents.release()
+    err := postPodEvent(client, pod, reason, message)
+    if err != nil {
+      getPodLogger(pod).Error("cannot record Event:" + reason + " because:" + err.Error())
+    }
+  }()
+}
+
+//Identical Events recorded again within the rate limit interval are dropped already before reaching the API server
+func (recorder *podEventRecorder) reserve(pod *corev1.Pod, reason, message string) (kubernetes.Interface, bool) {
+  recorder.lock.Lock()
+  defer recorder.lock.Unlock()
+  now := time.Now()
+  for key, recordTime := range recorder.lastRecorded {
+    if now.Sub(recordTime) >= eventRateLimitInterval {
+      delete(recorder.lastRecorded, key)
+    }
+  }
+  eventKey := getEventName(pod, reason, message)
+  if _, ok := recorder.lastRecorded[eventKey]; ok {
+    return nil, false
+  }
+  if recorder.numOfInFlight >= maxInFlightEvents {
+    getPodLogger(pod).Warning("too many Events are being recorded, dropping Event:" + reason)
+    return nil, false
+  }
+  if recorder.client == nil {
+    k8sClient, err := createK8sClient(DanmConfig.Kubeconfig)
+    if err != nil {
+      getPodLogger(pod).Error("cannot record Event:" + reason + " because K8s REST client could not be created:" + err.Error())
+      return nil, false
+    }
+    recorder.client = k8sClient
+  }
+  recorder.lastRecorded[eventKey] = now
+  recorder.numOfInFlight++
+  return recorder.client, true
+}
+
+func (recorder *podEventRecorder) release() {
+  recorder.lock.Lock()
+  recorder.numOfInFlight--
+  recorder.lock.Unlock()
+}
+
+func (recorder *podEventRecorder) getNumOfInFlight() int {
+  recorder.lock.Lock()
+  defer recorder.lock.Unlock()
+  return recorder.numOfInFlight
+}
+
+func (recorder *podEventRecorder) setClient(client kubernetes.Interface) {
+  recorder.lock.Lock()
+  defer recorder.lock.Unlock()
+  recorder.client = client
+}
+
+//flushPodEvents waits for the in-flight Events, but at most for eventFlushTimeout
+func flushPodEvents() {
+  deadline := time.Now().Add(eventFlushTimeout)
+  for podEvents.getNumOfInFlight() > 0 {
+    if time.Now().After(deadline) {
+      logger.Warning("some Pod Events could not be recorded within:" + eventFlushTimeout.String() + ", they are dropped")
+      return
+    }
+    time.Sleep(eventFlushPollInterval)
+  }
+}
+
+//Events are named deterministically after their Pod, reason, and message, so the same failure repeated by subsequent CNI calls is aggregated into one Event
+//The Count of an existing Event is only increased when it was last seen before the rate limit interval
+func postPodEvent(client kubernetes.Interface, pod *corev1.Pod, reason, message string) error {
+  eventClient := client.CoreV1().Events(pod.ObjectMeta.Namespace)
+  eventName := getEventName(pod, reason, message)
+  now := meta_v1.Now()
+  event, err := eventClient.Get(eventName, meta_v1.GetOptions{})
+  if err == nil {
+    if now.Sub(event.LastTimestamp.Time) < eventRateLimitInterval {
+      return nil
+    }
+    event.Count++
+    event.LastTimestamp = now
+    _, err = eventClient.Update(event)
+    return err
+  }
+  if !k8serrors.IsNotFound(err) {
+    return err
+  }
+  hostName, _ := os.Hostname()
+  event = &corev1.Event {
+    ObjectMeta: meta_v1.ObjectMeta {
+      Name:      eventName,
+      Namespace: pod.ObjectMeta.Namespace,
+    },
+    InvolvedObject: corev1.ObjectReference {
+      APIVersion: "v1",
+      Kind:       "Pod",
+      Name:       pod.ObjectMeta.Name,
+      Namespace:  pod.ObjectMeta.Namespace,
+      UID:        pod.ObjectMeta.UID,
+    },
+    Reason:         reason,
+    Message:        message,
+    Type:           corev1.EventTypeWarning,
+    Source:         corev1.EventSource{Component: eventSourceComponent, Host: hostName},
+    FirstTimestamp: now,
+    LastTimestamp:  now,
+    Count:          1,
+  }
+  _, err = eventClient.Create(event)
+  //Another CNI call of the same Pod was faster, which is fine
+  if k8serrors.IsAlreadyExists(err) {
+    return nil
+  }
+  return err
+}
+
+func getEventName(pod *corev1.Pod, reason, message string) string {
+  hasher := fnv.New64a()
+  hasher.Write([]byte(string(pod.ObjectMeta.UID) + reason + message))
+  suffix := "." + strconv.FormatUint(hasher.Sum64(), 16)
+  podName := pod.ObjectMeta.Name
+  if len(podName) + len(suffix) > maxEventNameLength {
+    podName = podName[:maxEventNameLength-len(suffix)]
+  }
+  return podName + suffix
+}
+
+func getNetworkKind(netInfo *danmtypes.DanmNet) string {
+  if netInfo.TypeMeta.Kind == "" {
+    return netcontrol.DanmNetKind
+  }
+  return netInfo.TypeMeta.Kind
+}
+
+func getPodLogger(pod *corev1.Pod) *logger.Entry {
+  return logger.WithFields(logger.Fields{logger.PodField: pod.ObjectMeta.Name, logger.NamespaceField: pod.ObjectMeta.Namespace})
+}
@@ -0,0 +1,318 @@
+package metacni
+
+import (
+  "errors"
+  "os"
+  "reflect"
+  "strconv"
+  "strings"
+  "time"
+  corev1 "k8s.io/api/core/v1"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
+  "github.com/nokia/danm/pkg/danmep"
+  "github.com/nokia/danm/pkg/datastructs"
+  "github.com/nokia/danm/pkg/logger"
+  "github.com/nokia/danm/pkg/netcontrol"
+  "github.com/nokia/danm/pkg/syncher"
+  checkpoint_utils "github.com/intel/multus-cni/checkpoint"
+)
+
+const (
+  hotplugFailureReason = "InterfaceHotplugFailed"
+  defaultCniBinDir = "/opt/cni/bin"
+)
+
+// HotplugInterfaces reconciles the DANM interfaces of an already running Pod with the change of its network connection annotation
+// Connections only present in the new annotation are created, and the interfaces of connections only present in the old one are deleted,
+// exactly the same way as CNI ADD and DEL would do it, including the reservation and release of DanmEps and IPs
+// The primary connection of the Pod cannot be changed while it is running
+func HotplugInterfaces(oldPod, newPod *corev1.Pod) error {
+  oldIfaces, err := getConnections(oldPod)
+  if err != nil {
+    return err
+  }
+  newIfaces, err := getConnections(newPod)
+  if err != nil {
+    recordPodWarning(newPod, hotplugFailureReason, err.Error())
+    return err
+  }
+  added, removed := diffConnections(oldIfaces, newIfaces)
+  if len(added) == 0 && len(removed) == 0 {
+    return nil
+  }
+  danmClient, err := CreateDanmClient(DanmConfig.Kubeconfig)
+  if err != nil {
+    return errors.New("cannot create DanmEp REST client because:" + err.Error())
+  }
+  eps, err := getPodEps(danmClient, newPod)
+  if err != nil {
+    return err
+  }
+  //The sandbox of the Pod was not set up by DANM, so there is nothing to reconcile with
+  if len(eps) == 0 {
+    return nil
+  }
+  err = validateHotplug(oldIfaces, newIfaces)
+  if err != nil {
+    recordPodWarning(newPod, hotplugFailureReason, err.Error())
+    return err
+  }
+  k8sClient, err := createK8sClient(DanmConfig.Kubeconfig)
+  if err != nil {
+    return errors.New("cannot create K8s REST client because:" + err.Error())
+  }
+  args := &datastructs.CniArgs {
+    Namespace: newPod.ObjectMeta.Namespace,
+    Netns: eps[0].Spec.Netns,
+    PodName: newPod.ObjectMeta.Name,
+    ContainerId: eps[0].Spec.CID,
+    Pod: newPod,
+    K8sClient: k8sClient,
+  }
+  setCniEnv(args)
+  getPodLogger(newPod).WithFields(logger.Fields{logger.CidField: args.ContainerId}).Info("HOTPLUG: Pod requested " + strconv.Itoa(len(added)) +
+              " new, and the removal of " + strconv.Itoa(len(removed)) + " existing network connections")
+  var errMsgs []string
+  err = detachInterfaces(danmClient, args, removed, eps)
+  if err != nil {
+    errMsgs = append(errMsgs, "removing connections failed:" + err.Error())
+  }
+  //The interfaces of changed connections are re-created under the same name, which is only free if their old DanmEp is already gone
+  if len(removed) > 0 {
+    eps, err = getPodEps(danmClient, newPod)
+  }
+  if err == nil {
+    err = attachInterfaces(danmClient, args, added, eps)
+  }
+  if err != nil {
+    errMsgs = append(errMsgs, "adding connections failed:" + err.Error())
+  }
+  if len(errMsgs) > 0 {
+    hotplugErr := strings.Join(errMsgs, "; ")
+    recordPodWarning(newPod, hotplugFailureReason, hotplugErr)
+    return errors.New(hotplugErr)
+  }
+  return nil
+}
+
+//Delegated CNI plugins receive the identity of the sandbox through the environment, exactly as if they were invoked by the container runtime
+//Pods are hot-plugged one by one, so the environment of the process always belongs to the Pod currently handled
+func setCniEnv(args *datastructs.CniArgs) {
+  os.Setenv("CNI_CONTAINERID", args.ContainerId)
+  os.Setenv("CNI_NETNS", args.Netns)
+  os.Setenv("CNI_ARGS", "IgnoreUnknown=1;K8S_POD_NAMESPACE=" + args.Namespace + ";K8S_POD_NAME=" + args.PodName + ";K8S_POD_INFRA_CONTAINER_ID=" + args.ContainerId)
+  if os.Getenv("CNI_PATH") == "" {
+    os.Setenv("CNI_PATH", defaultCniBinDir)
+  }
+}
+
+//Connections are compared as a multiset, so a Pod can still have more than one identical connection to the same network
+//The SequenceId of every returned connection is its position in the annotation it belongs to
+func diffConnections(oldIfaces, newIfaces []datastructs.Interface) ([]datastructs.Interface, []datastructs.Interface) {
+  var added, removed []datastructs.Interface
+  isNewMatched := make([]bool, len(newIfaces))
+  for oldId, oldIface := range oldIfaces {
+    isOldMatched := false
+    for newId, newIface := range newIfaces {
+      if !isNewMatched[newId] && reflect.DeepEqual(oldIface, newIface) {
+        isNewMatched[newId] = true
+        isOldMatched = true
+        break
+      }
+    }
+    if !isOldMatched {
+      oldIface.SequenceId = oldId
+      removed = append(removed, oldIface)
+    }
+  }
+  for newId, newIface := range newIfaces {
+    if !isNewMatched[newId] {
+      newIface.SequenceId = newId
+      added = append(added, newIface)
+    }
+  }
+  return added, removed
+}
+
+//The primary interface provides the IPs Kubelet knows about, and the default network is only used when no connections are defined
+func validateHotplug(oldIfaces, newIfaces []datastructs.Interface) error {
+  if len(oldIfaces) == 0 || len(newIfaces) == 0 {
+    return errors.New("network connections cannot be hot-plugged to, or removed from a Pod connected to the default network only")
+  }
+  if !reflect.DeepEqual(oldIfaces[0], newIfaces[0]) {
+    return errors.New("the primary network connection of a running Pod cannot be changed")
+  }
+  return nil
+}
+
+//Only the DanmEps of the current incarnation of the Pod are considered
+func getPodEps(danmClient danmclientset.Interface, pod *corev1.Pod) ([]danmtypes.DanmEp, error) {
+  eps, err := danmep.FindByPodName(danmClient, pod.ObjectMeta.Name, pod.ObjectMeta.Namespace)
+  if err != nil {
+    return nil, err
+  }
+  var podEps []danmtypes.DanmEp
+  for _, ep := range eps {
+    if ep.Spec.PodUID == pod.ObjectMeta.UID {
+      podEps = append(podEps, ep)
+    }
+  }
+  return podEps, nil
+}
+
+func attachInterfaces(danmClient danmclientset.Interface, args *datastructs.CniArgs, ifaces []datastructs.Interface, eps []danmtypes.DanmEp) error {
+  if len(ifaces) == 0 {
+    return nil
+  }
+  ifNames := make(map[string]bool)
+  usedDevices := make(map[string]bool)
+  for _, ep := range eps {
+    ifNames[ep.Spec.Iface.Name] = true
+    if ep.Spec.Iface.DeviceID != "" {
+      usedDevices[ep.Spec.Iface.DeviceID] = true
+    }
+  }
+  allocatedDevices := make(map[string]*[]string)
+  syncher := syncher.NewSyncher(len(ifaces), time.Duration(DanmConfig.Timeout) * time.Second)
+  for _, nicParams := range ifaces {
+    nicParams.DefaultIfaceName = defaultIfName
+    netInfo, err := netcontrol.GetNetworkFromInterface(danmClient, nicParams, args.Namespace)
+    if err != nil {
+      syncher.PushResult("", nicParams.SequenceId, errors.New("failed to get network object for connection no.:" + strconv.Itoa(nicParams.SequenceId) + " due to:" + err.Error()), nil)
+      continue
+    }
+    ifName := danmep.CalculateIfaceName(DanmConfig.NamingScheme, netInfo, nicParams)
+    if ifNames[ifName] {
+      if isConnectionPresent(eps, nicParams, ifName) {
+        //The connection was already created by CNI ADD, because the annotation changed before the sandbox of the Pod was set up
+        syncher.PushResult(netInfo.ObjectMeta.Name, nicParams.SequenceId, nil, nil)
+        continue
+      }
+      syncher.PushResult(netInfo.ObjectMeta.Name, nicParams.SequenceId, errors.New("interface name:" + ifName + " of connection no.:" + strconv.Itoa(nicParams.SequenceId) +
+                         " collides with an existing interface of the Pod, explicitly set a free ifName for the connection"), nil)
+      continue
+    }
+    ifNames[ifName] = true
+    //Devices allocated to the Pod are only handed over to connections not already using them
+    if _, ok := allocatedDevices[netInfo.Spec.Options.DevicePool]; !ok && netInfo.Spec.Options.DevicePool != "" {
+      freeDevices, err := getFreeDevices(args, netInfo.Spec.Options.DevicePool, usedDevices)
+      if err != nil {
+        syncher.PushResult(netInfo.ObjectMeta.Name, nicParams.SequenceId, err, nil)
+        continue
+      }
+      allocatedDevices[netInfo.Spec.Options.DevicePool] = freeDevices
+    }
+    err = createIface(args, danmClient, netInfo, nicParams, syncher, allocatedDevices)
+    if err != nil {
+      syncher.PushResult(netInfo.ObjectMeta.Name, nicParams.SequenceId, err, nil)
+    }
+  }
+  err := syncher.GetAggregatedResult()
+  if !syncher.WaitForOperations() {
+    getPodLogger(args.Pod).Warning("HOTPLUG: some cancelled interface creations did not finish within the grace period, their resources might leak")
+  }
+  return err
+}
+
+func isConnectionPresent(eps []danmtypes.DanmEp, iface datastructs.Interface, ifName string) bool {
+  netName, netKind := getNetworkOfConnection(iface)
+  for _, ep := range eps {
+    if ep.Spec.Iface.Name == ifName && isEpOfNetwork(ep, netName, netKind) {
+      return true
+    }
+  }
+  return false
+}
+
+func getFreeDevices(args *datastructs.CniArgs, devicePool string, usedDevices map[string]bool) (*[]string, error) {
+  checkpoint, err := checkpoint_utils.GetCheckpoint()
+  if err != nil {
+    return nil, errors.New("failed to instantiate checkpoint object due to:" + err.Error())
+  }
+  devices, err := getAllocatedDevices(args, checkpoint, devicePool)
+  if err != nil {
+    return nil, errors.New("failed to get allocated devices due to:" + err.Error())
+  }
+  var freeDevices []string
+  for _, device := range *devices {
+    if !usedDevices[device] {
+      freeDevices = append(freeDevices, device)
+    }
+  }
+  return &freeDevices, nil
+}
+
+func detachInterfaces(danmClient danmclientset.Interface, args *datastructs.CniArgs, ifaces []datastructs.Interface, eps []danmtypes.DanmEp) error {
+  var removedEps []danmtypes.DanmEp
+  for _, iface := range ifaces {
+    ep, err := findEpOfConnection(eps, iface)
+    if err != nil {
+      return err
+    }
+    //The interface is already gone, e.g. it was never created because the annotation changed before the sandbox of the Pod was set up
+    if ep == nil {
+      continue
+    }
+    removedEps = append(removedEps, *ep)
+  }
+  if len(removedEps) == 0 {
+    return nil
+  }
+  syncher := syncher.NewSyncher(len(removedEps), time.Duration(DanmConfig.Timeout) * time.Second)
+  for _, ep := range removedEps {
+    syncher.StartOperation(ep.Spec.NetworkName)
+    go deleteInterface(danmClient, args, syncher, ep)
+  }
+  err := syncher.GetAggregatedResult()
+  syncher.WaitForOperations()
+  return err
+}
+
+//The DanmEp of a connection is identified by its network, and its interface name
+//Default interface names depend on the position of the connection in the annotation, so if no such interface exists the sole interface of the network is chosen
+func findEpOfConnection(eps []danmtypes.DanmEp, iface datastructs.Interface) (*danmtypes.DanmEp, error) {
+  netName, netKind := getNetworkOfConnection(iface)
+  var candidates []danmtypes.DanmEp
+  for _, ep := range eps {
+    //The primary interface is never removed
+    if ep.Spec.Iface.Name == primaryIfName || !isEpOfNetwork(ep, netName, netKind) {
+      continue
+    }
+    if iface.IfName != "" && ep.Spec.Iface.Name != iface.IfName {
+      continue
+    }
+    candidates = append(candidates, ep)
+  }
+  if len(candidates) == 0 {
+    return nil, nil
+  }
+  if len(candidates) == 1 {
+    return &candidates[0], nil
+  }
+  defaultName := defaultIfName + strconv.Itoa(iface.SequenceId)
+  for index, ep := range candidates {
+    if ep.Spec.Iface.Name == defaultName {
+      return &candidates[index], nil
+    }
+  }
+  return nil, errors.New("Pod has more than one interface connected to " + netKind + ":" + netName + ", set an explicit ifName for the connection to remove the right one")
+}
+
+func getNetworkOfConnection(iface datastructs.Interface) (string, string) {
+  if iface.TenantNetwork != "" {
+    return iface.TenantNetwork, netcontrol.TenantNetworkKind
+  }
+  if iface.ClusterNetwork != "" {
+    return iface.ClusterNetwork, netcontrol.ClusterNetworkKind
+  }
+  return iface.Network, netcontrol.DanmNetKind
+}
+
+func isEpOfNetwork(ep danmtypes.DanmEp, netName, netKind string) bool {
+  epKind := ep.Spec.ApiType
+  if epKind == "" {
+    epKind = netcontrol.DanmNetKind
+  }
+  return ep.Spec.NetworkName == netName && epKind == netKind
+}
@@ -2,20 +2,22 @@ package metacni
 
 import (
   "bytes"
+  "context"
   "errors"
   "fmt"
-  "log"
   "net"
   "os"
   "runtime"
   "strconv"
   "strings"
+  "time"
   "encoding/json"
   "github.com/containernetworking/cni/pkg/skel"
   "github.com/containernetworking/cni/pkg/types"
   "github.com/containernetworking/cni/pkg/types/current"
   "github.com/containernetworking/plugins/pkg/ns"
   "github.com/containernetworking/plugins/pkg/utils/sysctl"
+  corev1 "k8s.io/api/core/v1"
   meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
   "k8s.io/client-go/rest"
   "k8s.io/client-go/tools/clientcmd"
@@ -26,6 +28,7 @@ import (
   "github.com/nokia/danm/pkg/danmep"
   "github.com/nokia/danm/pkg/datastructs"
   "github.com/nokia/danm/pkg/ipam"
+  "github.com/nokia/danm/pkg/logger"
   "github.com/nokia/danm/pkg/netcontrol"
   "github.com/nokia/danm/pkg/syncher"
   checkpoint_utils "github.com/intel/multus-cni/checkpoint"
@@ -38,8 +41,11 @@ const (
   v1Endpoint = "/api/v1/"
   cniVersion = "0.3.1"
   defaultNetworkName = "default"
+  primaryIfName = "eth0"
+  maxIfNameLength = 15
   defaultIfName = "eth"
   DefaultCniDir = "/etc/cni/net.d"
+  DefaultLogFile = "/var/log/danm.log"
 )
 
 var (
@@ -59,33 +65,39 @@ type K8sArgs struct {
 func CreateInterfaces(args *skel.CmdArgs) error {
   cniArgs,err := extractCniArgs(args)
   if err != nil {
-    log.Println("ERROR: ADD: CNI args cannot be loaded with error:" + err.Error())
+    logger.Error("ADD: CNI args cannot be loaded with error:" + err.Error())
     return fmt.Errorf("CNI args cannot be loaded with error: %v", err)
   }
-  log.Println("CNI ADD invoked with: ns:" + cniArgs.Namespace + " for Pod:" + cniArgs.PodName + " CID: " + cniArgs.ContainerId)
-  err = loadNetConf(cniArgs.StdIn)
+  setLogFields(cniArgs)
+  err = LoadNetConf(cniArgs.StdIn)
   if err != nil {
+    logger.Error("ADD: cannot load DANM CNI config due to error:" + err.Error())
     return errors.New("ERROR: ADD: cannot load DANM CNI config due to error:" + err.Error())
   }
+  configureLogging()
+  logger.Info("CNI ADD invoked")
   err = getPod(cniArgs)
   if err != nil {
-    log.Println("ERROR: ADD: Pod manifest could not be parsed with error:" + err.Error())
+    logger.Error("ADD: Pod manifest could not be parsed with error:" + err.Error())
     return fmt.Errorf("Pod manifest could not be parsed with error: %v", err)
   }
+  defer flushPodEvents()
   err = extractConnections(cniArgs)
   if err != nil {
-    log.Println("ERROR: ADD: DANM annotation cannot be parsed:" + err.Error())
+    logger.Error("ADD: DANM annotation cannot be parsed:" + err.Error())
+    recordPodWarning(cniArgs.Pod, invalidAnnotationReason, "DANM annotation cannot be parsed:" + err.Error())
     return fmt.Errorf("DANM annotation cannot be parsed: %v", err)
   }
   if len(cniArgs.Interfaces) == 0 {
     danmClient, err := CreateDanmClient(DanmConfig.Kubeconfig)
     if err != nil {
-      log.Println("ERROR: cannot instantiate K8s client, because:" + err.Error())
+      logger.Error("ADD: cannot instantiate K8s client, because:" + err.Error())
       return fmt.Errorf("ERROR: cannot instantiate K8s client: %v", err)
     }
     defaultNet, err := netcontrol.GetDefaultNetwork(danmClient, defaultNetworkName, cniArgs.Pod.ObjectMeta.Namespace)
     if err != nil {
-      log.Println("ERROR: there are no network connections defined for Pod:" + cniArgs.Pod.ObjectMeta.Name + ", and there is no suitable default network configured in the cluster!")
+      logger.Error("ADD: there are no network connections defined for the Pod, and there is no suitable default network configured in the cluster!")
+      recordPodWarning(cniArgs.Pod, networkNotFoundReason, "there are no network connections defined, and there is no suitable default network configured in the cluster")
       return errors.New("there are no network connections defined, and there is no suitable default network configured in the cluster")
     }
     cniArgs.DefaultNetwork = defaultNet
@@ -95,12 +107,29 @@ func CreateInterfaces(args *skel.CmdArgs) error {
     //Best effort cleanup - not interested in possible errors, anyway could not do anything with them
     os.Setenv("CNI_COMMAND","DEL")
     DeleteInterfaces(args)
-    log.Println("ERROR: ADD: CNI network could not be set up with error:" + err.Error())
+    logger.Error("ADD: CNI network could not be set up with error:" + err.Error())
     return fmt.Errorf("CNI network could not be set up: %v", err)
   }
   return types.PrintResult(cniResult, cniVersion)
 }
 
+//Every entry logged by a CNI call carries the identity of the Pod it was invoked for
+func setLogFields(args *datastructs.CniArgs) {
+  logger.SetDefaultFields(logger.Fields{logger.PodField: args.PodName, logger.NamespaceField: args.Namespace, logger.CidField: args.ContainerId})
+}
+
+//Until the CNI config is loaded the binary logs into DefaultLogFile with the default settings
+func configureLogging() {
+  logConfig := DanmConfig.Logging
+  if logConfig.File == "" {
+    logConfig.File = DefaultLogFile
+  }
+  err := logger.Configure(logConfig)
+  if err != nil {
+    logger.Warning("logging configuration of the DANM CNI config is invalid, keeping the defaults:" + err.Error())
+  }
+}
+
 func CreateDanmClient(kubeConfig string) (danmclientset.Interface,error) {
   config, err := getClientConfig(kubeConfig)
   if err != nil {
@@ -121,7 +150,8 @@ func getClientConfig(kubeConfig string) (*rest.Config, error){
   return config, nil
 }
 
-func loadNetConf(bytes []byte) error {
+// LoadNetConf parses the CNI configuration of DANM into DanmConfig
+func LoadNetConf(bytes []byte) error {
   netconf := &datastructs.NetConf{}
   err := json.Unmarshal(bytes, netconf)
   if err != nil {
@@ -148,6 +178,7 @@ func extractCniArgs(args *skel.CmdArgs) (*datastructs.CniArgs,error) {
                      nil,
                      nil,
                      nil,
+                     nil,
                     }
   return &cmdArgs, nil
 }
@@ -162,6 +193,8 @@ func getPod(args *datastructs.CniArgs) error {
     return errors.New("failed to get Pod info from K8s API server due to:" + err.Error())
   }
   args.Pod = pod
+  args.K8sClient = k8sClient
+  podEvents.setClient(k8sClient)
   return nil
 }
 
@@ -174,27 +207,59 @@ func createK8sClient(kubeconfig string) (kubernetes.Interface, error) {
 }
 
 func extractConnections(args *datastructs.CniArgs) error {
+  ifaces, err := getConnections(args.Pod)
+  if err != nil {
+    return err
+  }
+  args.Interfaces = ifaces
+  return nil
+}
+
+func getConnections(pod *corev1.Pod) ([]datastructs.Interface, error) {
   var ifaces []datastructs.Interface
-  for key, val := range args.Pod.Annotations {
+  for key, val := range pod.Annotations {
     if strings.Contains(key, danmIfDefinitionSyntax) {
       decoder := json.NewDecoder(bytes.NewReader([]byte(val)))
       //We are using Decoder interface, because it can notify us if any unknown fields were put into the object
       decoder.DisallowUnknownFields()
       err := decoder.Decode(&ifaces)
       if err != nil {
-        return errors.New("Can't create network interfaces for Pod: " + args.Pod.ObjectMeta.Name + " due to badly formatted " + danmIfDefinitionSyntax + " definition in Pod annotation:" + err.Error())
+        return nil, errors.New("Can't create network interfaces for Pod: " + pod.ObjectMeta.Name + " due to badly formatted " + danmIfDefinitionSyntax + " definition in Pod annotation:" + err.Error())
       }
       break
     }
   }
   if err := validateAnnotation(ifaces); err!=nil {
-    return errors.New("DANM annotation is invalid for Pod: " + args.Pod.ObjectMeta.Name + ", because:" + err.Error())
+    return nil, errors.New("DANM annotation is invalid for Pod: " + pod.ObjectMeta.Name + ", because:" + err.Error())
   }
-  args.Interfaces = ifaces
-  return nil
+  return movePrimaryToFront(ifaces), nil
 }
 
 func validateAnnotation(ifaces []datastructs.Interface) error {
+  var numOfPrimaries int
+  primaryId := 0
+  for ifaceId, iface := range ifaces {
+    if iface.Primary {
+      numOfPrimaries++
+      if numOfPrimaries > 1 {
+        return errors.New("network connection no.:" + strconv.Itoa(ifaceId)+ " is marked as primary, but only one connection can be primary")
+      }
+      primaryId = ifaceId
+    }
+  }
+  ifNames := make(map[string]int)
+  for ifaceId, iface := range ifaces {
+    if iface.IfName == "" {
+      continue
+    }
+    if err := validateIfName(iface.IfName, ifaceId == primaryId); err != nil {
+      return errors.New("network connection no.:" + strconv.Itoa(ifaceId)+ " contains invalid ifName:" + iface.IfName + " because:" + err.Error())
+    }
+    if otherId, ok := ifNames[iface.IfName]; ok {
+      return errors.New("network connection no.:" + strconv.Itoa(ifaceId)+ " requests the same ifName:" + iface.IfName + " as connection no.:" + strconv.Itoa(otherId))
+    }
+    ifNames[iface.IfName] = ifaceId
+  }
   for ifaceId, iface := range ifaces {
     var definedNetworks int
     if iface.Network        != "" {definedNetworks++}
@@ -203,17 +268,58 @@ func validateAnnotation(ifaces []datastructs.Interface) error {
     if definedNetworks != 1 {
       return errors.New("network connection no.:" + strconv.Itoa(ifaceId)+ " contains invalid number of network references:" + strconv.Itoa(definedNetworks))
     }
+    if err := danmep.ValidateSysctls(iface.Sysctls); err != nil {
+      return errors.New("network connection no.:" + strconv.Itoa(ifaceId)+ " contains invalid sysctls:" + err.Error())
+    }
+    if err := danmep.ValidateBandwidth(iface.Bandwidth); err != nil {
+      return errors.New("network connection no.:" + strconv.Itoa(ifaceId)+ " contains invalid bandwidth limits:" + err.Error())
+    }
+    if iface.Mac != "" {
+      mac, err := net.ParseMAC(iface.Mac)
+      if err != nil || len(mac) != 6 || mac[0] & 0x01 != 0 {
+        return errors.New("network connection no.:" + strconv.Itoa(ifaceId)+ " contains invalid unicast MAC address:" + iface.Mac)
+      }
+    }
+  }
+  return nil
+}
+
+//The primary interface is always named eth0 for the sake of Kubelet, so it can't be renamed, nor can other interfaces take its name
+func validateIfName(ifName string, isPrimary bool) error {
+  if len(ifName) > maxIfNameLength {
+    return errors.New("interface names can be at most " + strconv.Itoa(maxIfNameLength) + " characters long")
+  }
+  if ifName == "." || ifName == ".." || strings.ContainsAny(ifName, "/: \t\n") {
+    return errors.New("interface names cannot be . or .., and cannot contain slashes, colons, or whitespaces")
+  }
+  if isPrimary && ifName != primaryIfName {
+    return errors.New("the primary connection is always named " + primaryIfName)
+  }
+  if !isPrimary && ifName == primaryIfName {
+    return errors.New(primaryIfName + " is reserved for the primary connection")
   }
   return nil
 }
 
+//The primary connection always becomes the first interface (i.e. eth0), and provides the Pod IPs to Kubelet
+//Without an explicitly marked connection the first one is the primary. Otherwise the order of the other connections is kept
+func movePrimaryToFront(ifaces []datastructs.Interface) []datastructs.Interface {
+  for ifaceId, iface := range ifaces {
+    if iface.Primary && ifaceId != 0 {
+      orderedIfaces := append([]datastructs.Interface{iface}, ifaces[:ifaceId]...)
+      return append(orderedIfaces, ifaces[ifaceId+1:]...)
+    }
+  }
+  return ifaces
+}
+
 func setupNetworking(args *datastructs.CniArgs) (*current.Result, error) {
   err := preparePodForIpv6(args)
   if err != nil {
     return nil, errors.New("failed to prepare Pod for IPv6 due to:" + err.Error())
   }
   allocatedDevices := make(map[string]*[]string)
-  syncher := syncher.NewSyncher(len(args.Interfaces))
+  syncher := syncher.NewSyncher(len(args.Interfaces), time.Duration(DanmConfig.Timeout) * time.Second)
   danmClient, err := CreateDanmClient(DanmConfig.Kubeconfig)
   if err != nil {
     return nil, err
@@ -223,25 +329,38 @@ func setupNetworking(args *datastructs.CniArgs) (*current.Result, error) {
     defParam := datastructs.Interface{SequenceId: 0, Ip: "dynamic",}
     err = createIface(args, danmClient, args.DefaultNetwork, defParam, syncher, allocatedDevices)
     if err != nil {
-      syncher.PushResult(args.DefaultNetwork.ObjectMeta.Name, err, nil)
+      syncher.PushResult(args.DefaultNetwork.ObjectMeta.Name, defParam.SequenceId, err, nil)
     }
   }
+  ifNames := make(map[string]int)
   for nicID, nicParams := range args.Interfaces {
     nicParams.SequenceId = nicID
     nicParams.DefaultIfaceName = defaultIfName
     netInfo, err := netcontrol.GetNetworkFromInterface(danmClient, nicParams, args.Pod.ObjectMeta.Namespace)
     if err != nil {
-      syncher.PushResult("", errors.New("failed to get network object for Pod:" + args.Pod.ObjectMeta.Name +
+      recordPodWarning(args.Pod, networkNotFoundReason, "connection no.:" + strconv.Itoa(nicID) + " cannot be set up:" + err.Error())
+      syncher.PushResult("", nicID, errors.New("failed to get network object for Pod:" + args.Pod.ObjectMeta.Name +
                              "'s connection no.:" + strconv.Itoa(nicID) + " due to:" + err.Error()), nil)
       continue
     }
+    //Explicitly requested names can still collide with the names DANM generates for the other connections
+    ifName := danmep.CalculateIfaceName(DanmConfig.NamingScheme, netInfo, nicParams)
+    if otherNicID, ok := ifNames[ifName]; ok {
+      syncher.PushResult(netInfo.ObjectMeta.Name, nicID, errors.New("interface name:" + ifName + " of Pod:" + args.Pod.ObjectMeta.Name +
+                         "'s connection no.:" + strconv.Itoa(nicID) + " collides with connection no.:" + strconv.Itoa(otherNicID)), nil)
+      continue
+    }
+    ifNames[ifName] = nicID
     err = createIface(args, danmClient, netInfo, nicParams, syncher, allocatedDevices)
     if err != nil {
-      syncher.PushResult(netInfo.ObjectMeta.Name, err, nil)
+      syncher.PushResult(netInfo.ObjectMeta.Name, nicParams.SequenceId, err, nil)
       continue
     }
   }
   err = syncher.GetAggregatedResult()
+  if !syncher.WaitForOperations() {
+    logger.Warning("ADD: some cancelled interface creations did not finish within the grace period, their resources might leak")
+  }
   return syncher.MergeCniResults(), err
 }
 
@@ -276,10 +395,19 @@ func preparePodForIpv6(args *datastructs.CniArgs) error {
 
 func createIface(args *datastructs.CniArgs, danmClient danmclientset.Interface, netInfo *danmtypes.DanmNet, nicParams datastructs.Interface, syncher *syncher.Syncher, allocatedDevices map[string]*[]string) error {
   if !isTenantAllowed(args, netInfo) {
+    recordPodWarning(args.Pod, tenantNotAllowedReason, "tenant:" + args.Pod.ObjectMeta.Namespace + " is not allowed on " + getNetworkKind(netInfo) + ":" + netInfo.ObjectMeta.Name)
     return errors.New("Pod:" + args.PodName + "'s namespace:" + args.Namespace + " is not in the AllowedTenants whitelist of network:" + netInfo.ObjectMeta.Name)
   }
-  var err error
-  if cnidel.IsDeviceNeeded(netInfo.Spec.NetworkType) {
+  if nicParams.Mac != "" && !danmep.IsMacConfigurable(netInfo.Spec.NetworkType) {
+    return errors.New("MAC address cannot be requested for network:" + netInfo.ObjectMeta.Name + " with NetworkType:" + netInfo.Spec.NetworkType)
+  }
+  //The backend is resolved only once per interface, as CniTemplates are read from the API server
+  backend, err := cnidel.GetBackendConfig(danmClient, netInfo.Spec.NetworkType)
+  if err != nil {
+    return errors.New("backend of NetworkType:" + netInfo.Spec.NetworkType + " could not be resolved because:" + err.Error())
+  }
+  //OVS networks only need a Device when their Pods are connected via DPDK vhost-user ports
+  if cnidel.IsDeviceNeeded(backend) || (netcontrol.IsOvsNetwork(netInfo) && netInfo.Spec.Options.DevicePool != "") {
     if _, ok := allocatedDevices[netInfo.Spec.Options.DevicePool]; !ok {
       checkpoint, err := checkpoint_utils.GetCheckpoint()
       if err != nil {
@@ -287,15 +415,18 @@ func createIface(args *datastructs.CniArgs, danmClient danmclientset.Interface,
       }
       allocatedDevices[netInfo.Spec.Options.DevicePool], err = getAllocatedDevices(args, checkpoint, netInfo.Spec.Options.DevicePool)
       if err != nil {
+        recordPodWarning(args.Pod, devicePoolEmptyReason, "device pool:" + netInfo.Spec.Options.DevicePool + " of network:" + netInfo.ObjectMeta.Name + " has no Devices allocated to the Pod:" + err.Error())
         return errors.New("failed to get allocated devices due to:" + err.Error())
       }
     }
     nicParams.Device, err = popDevice(netInfo.Spec.Options.DevicePool, allocatedDevices)
     if err != nil {
+      recordPodWarning(args.Pod, devicePoolEmptyReason, "device pool:" + netInfo.Spec.Options.DevicePool + " is empty, no Device left for network:" + netInfo.ObjectMeta.Name)
       return errors.New("failed to pop devices due to:" + err.Error())
     }
   }
-  go createNic(syncher, danmClient, nicParams, netInfo, args)
+  syncher.StartOperation(netInfo.ObjectMeta.Name)
+  go createNic(syncher, danmClient, backend, nicParams, netInfo, args)
   return nil
 }
 
@@ -336,40 +467,110 @@ func popDevice(devicePool string, allocatedDevices map[string]*[]string)(string,
   return device, nil
 }
 
-func createNic(syncher *syncher.Syncher, danmClient danmclientset.Interface, iface datastructs.Interface, netInfo *danmtypes.DanmNet, args *datastructs.CniArgs) {
-  isIpReservationNeeded := cnidel.IsDanmIpamNeededForDelegation(iface, netInfo) || netInfo.Spec.NetworkType == "ipvlan"
-  ep, netInfo, err := danmep.CreateDanmEp(danmClient, DanmConfig.NamingScheme, isIpReservationNeeded, netInfo, iface, args)
+func createNic(syncher *syncher.Syncher, danmClient danmclientset.Interface, backend *datastructs.CniBackendConfig, iface datastructs.Interface, netInfo *danmtypes.DanmNet, args *datastructs.CniArgs) {
+  defer syncher.OperationFinished()
+  isIpReservationNeeded := !cnidel.IsDelegationRequired(netInfo) || cnidel.IsDanmIpamNeededForDelegation(backend, iface, netInfo)
+  //The refreshed network is not returned on every error, so the original one is used for reporting them
+  ep, updatedNetInfo, err := danmep.CreateDanmEp(danmClient, DanmConfig.NamingScheme, isIpReservationNeeded, netInfo, iface, args)
   if err != nil {
+    getPodLogger(args.Pod).WithFields(logger.Fields{logger.NetworkField: netInfo.ObjectMeta.Name}).Error("ADD: DanmEp could not be created:" + err.Error())
+    if ipam.IsPoolExhausted(err) {
+      recordPodWarning(args.Pod, ipPoolExhaustedReason, "IP pool exhausted on network:" + netInfo.ObjectMeta.Name)
+    } else {
+      recordPodWarning(args.Pod, ipAllocationFailedReason, "IP allocation failed on network:" + netInfo.ObjectMeta.Name + ":" + err.Error())
+    }
     if ep != nil {
-      danmep.DeleteDanmEp(danmClient, ep, netInfo)
+      rollbackDanmEp(danmClient, ep, netInfo, danmep.IpamStep, err)
     }
-    syncher.PushResult(netInfo.ObjectMeta.Name, err, nil)
+    syncher.PushResult(netInfo.ObjectMeta.Name, iface.SequenceId, err, nil)
     return
   }
+  netInfo = updatedNetInfo
   var cniResult *current.Result
   if cnidel.IsDelegationRequired(netInfo) {
-    cniResult, err = createDelegatedInterface(danmClient, isIpReservationNeeded, ep, netInfo, args)
+    cniResult, err = createDelegatedInterface(syncher.Context(), danmClient, backend, isIpReservationNeeded, ep, netInfo, args)
   } else {
     cniResult, err = createDanmInterface(danmClient, ep, netInfo, args)
   }
+  if cnidel.IsDelegationRequired(netInfo) && cniResult != nil {
+    rawResult, jsonErr := json.Marshal(cniResult)
+    if jsonErr == nil {
+      ep.Status.CniResult = string(rawResult)
+    }
+  }
+  epLog := danmep.GetEpLogger(ep)
   if err != nil {
-    danmep.DeleteDanmEp(danmClient, ep, netInfo)
-    syncher.PushResult(ep.Spec.NetworkName, err, cniResult)
+    epLog.Error("ADD: interface could not be created:" + err.Error())
+    if cnidel.IsDelegationRequired(netInfo) {
+      recordPodWarning(args.Pod, delegateFailedReason, "delegate " + netInfo.Spec.NetworkType + " failed for network:" + netInfo.ObjectMeta.Name + ":" + err.Error())
+    } else {
+      recordPodWarning(args.Pod, interfaceSetupFailedReason, "interface of network:" + netInfo.ObjectMeta.Name + " could not be created:" + err.Error())
+    }
+    rollbackDanmEp(danmClient, ep, netInfo, danmep.LinkCreationStep, err)
+    syncher.PushResult(ep.Spec.NetworkName, iface.SequenceId, err, cniResult)
     return
   }
   err = danmep.PostProcessInterface(ep, netInfo)
+  if routeErr, ok := err.(*danmep.RouteError); ok && routeErr.Lenient {
+    epLog.Warning("ADD: some IP routes of the interface could not be installed:" + routeErr.Error())
+    recordPodWarning(args.Pod, routeFailureReason, "IP routes of interface:" + ep.Spec.Iface.Name + " of network:" + ep.Spec.NetworkName + " could not be installed:" + routeErr.Error())
+    //The interface is kept, but its status shows why it is only partially configured
+    danmep.SetFailedStep(ep, danmep.PostProcessingStep, routeErr)
+    err = nil
+  }
   if err != nil {
+    epLog.Error("ADD: post-processing of the interface failed:" + err.Error())
+    recordPodWarning(args.Pod, interfaceSetupFailedReason, "post-processing of interface:" + ep.Spec.Iface.Name + " of network:" + ep.Spec.NetworkName + " failed:" + err.Error())
+    danmep.DeleteIfaceRoutes(ep)
+    rollbackDanmEp(danmClient, ep, netInfo, danmep.PostProcessingStep, err)
+    syncher.PushResult(ep.Spec.NetworkName, iface.SequenceId, errors.New("Post-processing failed for interface:" + ep.Spec.Iface.Name + " because:" + err.Error()), nil)
+    return
+  }
+  if len(ep.Spec.Iface.Rules) > 0 || len(ep.Spec.Iface.Routes) > 0 {
+    err = danmep.UpdateDanmEp(danmClient, ep)
+    if err != nil {
+      epLog.Warning("ADD: installed IP rules and routes of the interface could not be recorded, they won't be removed on DEL:" + err.Error())
+    }
+  }
+  err = danmep.SetIfaceStatus(ep)
+  if err != nil {
+    epLog.Warning("ADD: operational state of the interface could not be collected:" + err.Error())
+  }
+  ep.Status.InstallTime = meta_v1.Now()
+  recordEpStatus(danmClient, ep)
+  epLog.Info("ADD: interface is created")
+  if !syncher.PushResult(ep.Spec.NetworkName, iface.SequenceId, nil, cniResult) {
+    //CNI ADD already returned with a time-out error, so nobody will ever clean-up this interface if we don't
+    epLog.Info("ADD: interface was created after the deadline, rolling it back")
+    danmep.DeleteIfaceRoutes(ep)
+    deleteNic(context.Background(), danmClient, args.K8sClient, netInfo, ep)
     danmep.DeleteDanmEp(danmClient, ep, netInfo)
-    syncher.PushResult(ep.Spec.NetworkName, errors.New("Post-processing failed for interface:" + ep.Spec.Iface.Name + " because:" + err.Error()), nil)
+  }
+}
+
+//The failure is only recorded into the status when the DanmEp of the failed interface is left behind, otherwise nobody could ever see it
+func rollbackDanmEp(danmClient danmclientset.Interface, ep *danmtypes.DanmEp, netInfo *danmtypes.DanmNet, failedStep string, stepErr error) {
+  err := danmep.DeleteDanmEp(danmClient, ep, netInfo)
+  if err == nil {
     return
   }
-  syncher.PushResult(ep.Spec.NetworkName, nil, cniResult)
+  danmep.GetEpLogger(ep).Warning("ADD: DanmEp of the failed interface could not be deleted:" + err.Error())
+  danmep.SetFailedStep(ep, failedStep, stepErr)
+  recordEpStatus(danmClient, ep)
 }
 
-func createDelegatedInterface(danmClient danmclientset.Interface, wasIpReservedByDanmIpam bool, ep *danmtypes.DanmEp, netInfo *danmtypes.DanmNet, args *datastructs.CniArgs) (*current.Result,error) {
+//Status is best effort, failing to record it shall never fail the CNI operation itself
+func recordEpStatus(danmClient danmclientset.Interface, ep *danmtypes.DanmEp) {
+  err := danmep.UpdateDanmEpStatus(danmClient, ep)
+  if err != nil {
+    danmep.GetEpLogger(ep).Warning("ADD: status of the interface could not be recorded:" + err.Error())
+  }
+}
+
+func createDelegatedInterface(ctx context.Context, danmClient danmclientset.Interface, backend *datastructs.CniBackendConfig, wasIpReservedByDanmIpam bool, ep *danmtypes.DanmEp, netInfo *danmtypes.DanmNet, args *datastructs.CniArgs) (*current.Result,error) {
   origV4Address := ep.Spec.Iface.Address
   origV6Address := ep.Spec.Iface.AddressIPv6
-  delegatedResult,err := cnidel.DelegateInterfaceSetup(DanmConfig, wasIpReservedByDanmIpam, netInfo, ep)
+  delegatedResult,err := cnidel.DelegateInterfaceSetup(ctx, DanmConfig, backend, args.K8sClient, wasIpReservedByDanmIpam, netInfo, ep)
   if err != nil {
     //TODO: is this -basically only host-ipam related- stuff really needed, or is just legacy residue?
     cnidel.FreeDelegatedIps(netInfo, ep.Spec.Iface.Address, ep.Spec.Iface.AddressIPv6)
@@ -389,9 +590,26 @@ func createDelegatedInterface(danmClient danmclientset.Interface, wasIpReservedB
 }
 
 func createDanmInterface(danmClient danmclientset.Interface, ep *danmtypes.DanmEp, netInfo *danmtypes.DanmNet, args *datastructs.CniArgs) (*current.Result,error) {
-  err := danmep.AddIpvlanInterface(netInfo, ep)
-  if err != nil {
-    return nil, errors.New("IPVLAN interface could not be created due to error:" + err.Error())
+  if netcontrol.IsBridgeNetwork(netInfo) {
+    err := danmep.AddBridgeInterface(netInfo, ep)
+    if err != nil {
+      return nil, errors.New("bridged veth interface could not be created due to error:" + err.Error())
+    }
+  } else if netcontrol.IsRoutedNetwork(netInfo) {
+    err := danmep.AddRoutedInterface(netInfo, ep)
+    if err != nil {
+      return nil, errors.New("routed veth interface could not be created due to error:" + err.Error())
+    }
+  } else if netcontrol.IsOvsNetwork(netInfo) {
+    err := danmep.AddOvsInterface(netInfo, ep)
+    if err != nil {
+      return nil, errors.New("OVS port could not be created due to error:" + err.Error())
+    }
+  } else {
+    err := danmep.AddIpvlanInterface(netInfo, ep)
+    if err != nil {
+      return nil, errors.New("IPVLAN interface could not be created due to error:" + err.Error())
+    }
   }
   danmResult := &current.Result{}
   AddIfaceToResult(ep.Spec.EndpointID, args.ContainerId, danmResult)
@@ -421,39 +639,50 @@ func AddIpToResult(ip string, version string, cniResult *current.Result) {
 
 func DeleteInterfaces(args *skel.CmdArgs) error {
   cniArgs,err := extractCniArgs(args)
-  log.Println("CNI DEL invoked with: ns:" + cniArgs.Namespace + " for Pod:" + cniArgs.PodName + " CID: " + cniArgs.ContainerId)
   if err != nil {
-    log.Println("INFO: DEL: CNI args could not be loaded because" + err.Error())
+    logger.Info("DEL: CNI args could not be loaded because:" + err.Error())
     return nil
   }
-  err = loadNetConf(cniArgs.StdIn)
+  setLogFields(cniArgs)
+  err = LoadNetConf(cniArgs.StdIn)
   if err != nil {
-    log.Println("INFO: DEL: cannot load DANM CNI config due to error:" + err.Error())
+    logger.Info("DEL: cannot load DANM CNI config due to error:" + err.Error())
     return nil
   }
+  configureLogging()
+  logger.Info("CNI DEL invoked")
   danmClient, err := CreateDanmClient(DanmConfig.Kubeconfig)
   if err != nil {
-    log.Println("INFO: DEL: DanmEp REST client could not be created because" + err.Error())
+    logger.Info("DEL: DanmEp REST client could not be created because:" + err.Error())
     return nil
   }
   eplist, err := danmep.FindByCid(danmClient, cniArgs.ContainerId)
   if err != nil {
-    log.Println("INFO: DEL: Could not interrogate DanmEps from K8s API server because" + err.Error())
+    logger.Info("DEL: Could not interrogate DanmEps from K8s API server because:" + err.Error())
     return nil
   }
-  syncher := syncher.NewSyncher(len(eplist))
+  //Static CNI configs can be still read from the node-local cache without a K8s client
+  cniArgs.K8sClient, err = createK8sClient(DanmConfig.Kubeconfig)
+  if err != nil {
+    logger.Info("DEL: K8s REST client could not be created because:" + err.Error())
+    cniArgs.K8sClient = nil
+  }
+  syncher := syncher.NewSyncher(len(eplist), time.Duration(DanmConfig.Timeout) * time.Second)
   //Note to self: NEVER change this to pass-by-pointer. It totally breaks CNI DEL for all but one interface
   for _, ep := range eplist {
+    syncher.StartOperation(ep.Spec.NetworkName)
     go deleteInterface(danmClient, cniArgs, syncher, ep)
   }
   deleteErrors := syncher.GetAggregatedResult()
+  syncher.WaitForOperations()
   if deleteErrors != nil {
-    log.Println("INFO: DEL: Following errors happened during interface deletion:" + deleteErrors.Error())
+    logger.Info("DEL: Following errors happened during interface deletion:" + deleteErrors.Error())
   }
   return nil
 }
 
 func deleteInterface(danmClient danmclientset.Interface, args *datastructs.CniArgs, syncher *syncher.Syncher, ep danmtypes.DanmEp) {
+  defer syncher.OperationFinished()
   //During delete we are not that interested in errors, but we also can't just return yet.
   //We need to try and clean-up as many remaining resources as possible
   var aggregatedError string
@@ -461,8 +690,16 @@ func deleteInterface(danmClient danmclientset.Interface, args *datastructs.CniAr
   if err != nil {
     aggregatedError += "failed to get network:"+ err.Error() + "; "
   }
+  err = danmep.DeleteBandwidthLimits(&ep)
+  if err != nil {
+    aggregatedError += "failed to remove traffic shaping:" + err.Error() + "; "
+  }
+  err = danmep.DeleteIfaceRoutes(&ep)
+  if err != nil {
+    aggregatedError += "failed to remove IP rules and routes:" + err.Error() + "; "
+  }
   if netInfo != nil {
-    err = deleteNic(netInfo, &ep)
+    err = deleteNic(syncher.Context(), danmClient, args.K8sClient, netInfo, &ep)
     if err != nil {
       aggregatedError += "failed to delete container NIC:" + err.Error() + "; "
     }
@@ -472,18 +709,25 @@ func deleteInterface(danmClient danmclientset.Interface, args *datastructs.CniAr
     aggregatedError += "failed to delete DanmEp:" + err.Error() + "; "
   }
   if aggregatedError != "" {
-    syncher.PushResult(ep.Spec.NetworkName, errors.New(aggregatedError), nil)
+    danmep.GetEpLogger(&ep).Info("DEL: interface could not be completely removed:" + aggregatedError)
+    syncher.PushResult(ep.Spec.NetworkName, 0, errors.New(aggregatedError), nil)
   } else {
-    syncher.PushResult(ep.Spec.NetworkName, nil, nil)
+    syncher.PushResult(ep.Spec.NetworkName, 0, nil, nil)
   }
 }
 
-func deleteNic(netInfo *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
+func deleteNic(ctx context.Context, danmClient danmclientset.Interface, k8sClient kubernetes.Interface, netInfo *danmtypes.DanmNet, ep *danmtypes.DanmEp) error {
   var err error
-  if ep.Spec.NetworkType != "ipvlan" {
-    err = cnidel.DelegateInterfaceDelete(DanmConfig, netInfo, ep)
-  } else {
+  if ep.Spec.NetworkType == "ipvlan" {
     err = danmep.DeleteIpvlanInterface(ep)
+  } else if strings.ToLower(ep.Spec.NetworkType) == "bridge" {
+    err = danmep.DeleteBridgeInterface(ep)
+  } else if strings.ToLower(ep.Spec.NetworkType) == "routed" {
+    err = danmep.DeleteRoutedInterface(ep)
+  } else if strings.ToLower(ep.Spec.NetworkType) == "ovs" {
+    err = danmep.DeleteOvsInterface(ep)
+  } else {
+    err = cnidel.DelegateInterfaceDelete(ctx, DanmConfig, danmClient, k8sClient, netInfo, ep)
   }
   return err
 }
@@ -4,6 +4,7 @@ import (
   "errors"
   "net"
   "strconv"
+  "strings"
   "syscall"
   "github.com/apparentlymart/go-cidr/cidr"
   "github.com/vishvananda/netlink"
@@ -15,6 +16,7 @@ const (
   ip6MulticastCidr = "ff02::0/16"
   maxVlanId = 4094
   maxVxlanId = 16777214
+  BridgePrefix = "br_"
 )
 
 // LinkInfo is an absract struct to represent a host NIC of a special type: either VLAN, or VxLAN
@@ -24,16 +26,26 @@ type LinkInfo struct {
   link netlink.Link
 }
 
-func deleteNetworks(dnet *danmtypes.DanmNet) error {
-  if dnet.Spec.Options.Device == "" {
+//The host bridge of the network is kept when isBridgeKept is set, i.e. when an updated network still uses it
+func deleteNetworks(dnet *danmtypes.DanmNet, isBridgeKept bool) error {
+  var combinedErrorMessage string
+  if IsBridgeNetwork(dnet) && !isBridgeKept {
+    tempErr := deleteHostBridge(GetBridgeName(dnet))
+    if tempErr != nil {
+      combinedErrorMessage = tempErr.Error() + "\n"
+    }
+  }
+  if dnet.Spec.Options.Device == "" || IsOvsNetwork(dnet) {
+    if combinedErrorMessage != "" {
+      return errors.New(combinedErrorMessage)
+    }
     return nil
   }
-  var combinedErrorMessage string
   vxlanId := dnet.Spec.Options.Vxlan
   netId := dnet.Spec.NetworkID
   tempErr := deleteHostInterface(vxlanId, "vx_" + netId)
   if tempErr != nil {
-    combinedErrorMessage = tempErr.Error() + "\n"
+    combinedErrorMessage += tempErr.Error() + "\n"
   }
   vlanId := dnet.Spec.Options.Vlan
   tempErr = deleteHostInterface(vlanId, determineVlanHdev(vlanId, netId, dnet.Spec.Options.Device))
@@ -61,8 +73,32 @@ func deleteHostInterface(ifId int, ifName string) error {
   return nil
 }
 
+func deleteHostBridge(brName string) error {
+  bridge, err := netlink.LinkByName(brName)
+  if err != nil {
+    return nil
+  }
+  err = netlink.LinkDel(bridge)
+  if err != nil {
+    return errors.New("Deletion of bridge:" + brName + " failed with error:" + err.Error())
+  }
+  return nil
+}
+
 func setupHost(dnet *danmtypes.DanmNet) error {
-  if dnet.Spec.Options.Device == "" {
+  err := setupHostLinks(dnet)
+  if err != nil {
+    return err
+  }
+  if !IsBridgeNetwork(dnet) {
+    return nil
+  }
+  return setupBridge(dnet)
+}
+
+func setupHostLinks(dnet *danmtypes.DanmNet) error {
+  //The host_device of OVS networks is an OVS bridge, and their VLAN is set as the access tag of the Pod ports instead
+  if dnet.Spec.Options.Device == "" || IsOvsNetwork(dnet) {
     return nil
   }
   netId := dnet.Spec.NetworkID
@@ -185,6 +221,101 @@ func setupVxlan(vxlanId int, netId, hdev string) error {
   return nil
 }
 
+// IsBridgeNetwork returns true if the Pods of the network are connected to a DANM managed Linux bridge on the host
+func IsBridgeNetwork(dnet *danmtypes.DanmNet) bool {
+  return strings.ToLower(dnet.Spec.NetworkType) == "bridge"
+}
+
+// IsRoutedNetwork returns true if the Pods of the network are connected to the host via veth pairs, and are reachable via host routes
+func IsRoutedNetwork(dnet *danmtypes.DanmNet) bool {
+  return strings.ToLower(dnet.Spec.NetworkType) == "routed"
+}
+
+// IsOvsNetwork returns true if the Pods of the network are connected to the Open vSwitch bridge set in the host_device attribute of the network
+func IsOvsNetwork(dnet *danmtypes.DanmNet) bool {
+  return strings.ToLower(dnet.Spec.NetworkType) == "ovs"
+}
+
+// GetBridgeName returns the name of the host bridge belonging to a bridge type network
+func GetBridgeName(dnet *danmtypes.DanmNet) string {
+  return BridgePrefix + dnet.Spec.NetworkID
+}
+
+// setupBridge creates the host bridge of the network if it does not exist yet, and brings its configuration in line with the network manifest:
+// sets its MAC address, enslaves the VLAN, or VxLAN host interface of the network, and refreshes the hairpin mode of its veth ports
+func setupBridge(dnet *danmtypes.DanmNet) error {
+  brName := GetBridgeName(dnet)
+  bridge, err := netlink.LinkByName(brName)
+  if err != nil {
+    err = addLink(&netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: brName}})
+    if err != nil {
+      return errors.New("cannot add bridge:" + brName + " to the host due to:" + err.Error())
+    }
+    bridge, err = netlink.LinkByName(brName)
+    if err != nil {
+      return errors.New("cannot find freshly created bridge:" + brName + " because:" + err.Error())
+    }
+  }
+  if dnet.Spec.Options.BridgeMac != "" {
+    mac, err := net.ParseMAC(dnet.Spec.Options.BridgeMac)
+    if err != nil {
+      return errors.New("cannot parse bridge_mac:" + dnet.Spec.Options.BridgeMac + " because:" + err.Error())
+    }
+    if bridge.Attrs().HardwareAddr.String() != mac.String() {
+      err = netlink.LinkSetHardwareAddr(bridge, mac)
+      if err != nil {
+        return errors.New("cannot set MAC address of bridge:" + brName + " because:" + err.Error())
+      }
+    }
+  }
+  err = enslaveUplink(dnet, bridge)
+  if err != nil {
+    return err
+  }
+  return setHairpinOnBridgePorts(bridge, dnet.Spec.Options.Hairpin)
+}
+
+// Only VLAN, and VxLAN host interfaces are connected to the bridge.
+// Plain host_devices are never enslaved, as it would take away their IPs from the host.
+func enslaveUplink(dnet *danmtypes.DanmNet, bridge netlink.Link) error {
+  if dnet.Spec.Options.Device == "" || (dnet.Spec.Options.Vlan == 0 && dnet.Spec.Options.Vxlan == 0) {
+    return nil
+  }
+  uplinkName := "vx_" + dnet.Spec.NetworkID
+  if dnet.Spec.Options.Vlan != 0 {
+    uplinkName = determineVlanHdev(dnet.Spec.Options.Vlan, dnet.Spec.NetworkID, dnet.Spec.Options.Device)
+  }
+  uplink, err := netlink.LinkByName(uplinkName)
+  if err != nil {
+    return errors.New("cannot find host interface:" + uplinkName + " to be connected to bridge:" + bridge.Attrs().Name)
+  }
+  if uplink.Attrs().MasterIndex == bridge.Attrs().Index {
+    return nil
+  }
+  err = netlink.LinkSetMaster(uplink, bridge)
+  if err != nil {
+    return errors.New("cannot connect host interface:" + uplinkName + " to bridge:" + bridge.Attrs().Name + " because:" + err.Error())
+  }
+  return nil
+}
+
+func setHairpinOnBridgePorts(bridge netlink.Link, hairpin bool) error {
+  links, err := netlink.LinkList()
+  if err != nil {
+    return errors.New("cannot list host interfaces because:" + err.Error())
+  }
+  for _, link := range links {
+    if link.Attrs().MasterIndex != bridge.Attrs().Index || link.Type() != "veth" {
+      continue
+    }
+    err = netlink.LinkSetHairpin(link, hairpin)
+    if err != nil {
+      return errors.New("cannot set hairpin mode of bridge port:" + link.Attrs().Name + " because:" + err.Error())
+    }
+  }
+  return nil
+}
+
 func getMulticastIp(ipFamily int, vxlanId string ) (net.IP, error) {
   vxlanIdInt, err := strconv.Atoi(vxlanId)
   if err != nil {
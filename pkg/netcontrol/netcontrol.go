@@ -2,7 +2,6 @@ package netcontrol
 
 import (
   "errors"
-  "log"
   "strconv"
   "strings"
   "time"
@@ -10,6 +9,7 @@ import (
   danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
   danminformers "github.com/nokia/danm/crd/client/informers/externalversions"
   "github.com/nokia/danm/pkg/datastructs"
+  "github.com/nokia/danm/pkg/logger"
   meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
   "k8s.io/client-go/rest"
   "k8s.io/client-go/tools/cache"
@@ -64,10 +64,20 @@ func NewWatcher(cfg *rest.Config) (*NetWatcher,error) {
   if err == nil {
     netWatcher.createCnetInformer(cnetClient)
   }
-  log.Println("Number of watcher's started for recognized APIs:" + strconv.Itoa(len(netWatcher.Controllers)))
+  logger.Info("Number of watcher's started for recognized APIs:" + strconv.Itoa(len(netWatcher.Controllers)))
   if len(netWatcher.Controllers) == 0 {
     return nil, errors.New("no network management APIs are installed in the cluster, netwatcher cannot start!")
   }
+  if RouteExportTable != 0 {
+    epClient, err := danmclientset.NewForConfig(cfg)
+    if err != nil {
+      return nil, err
+    }
+    _, err = epClient.DanmV1().DanmEps("").List(meta_v1.ListOptions{})
+    if err == nil {
+      netWatcher.createEpInformer(epClient)
+    }
+  }
   return netWatcher, nil
 }
 
@@ -120,34 +130,34 @@ func (netWatcher *NetWatcher) createCnetInformer(cnetClient danmclientset.Interf
 func AddDanmNet(obj interface{}) {
   dn, isNetwork := obj.(*danmtypes.DanmNet)
   if !isNetwork {
-    log.Println("ERROR: Can't create interfaces for DanmNet, 'cause we have received an invalid object from the K8s API server")
+    logger.Error("Can't create interfaces for DanmNet, 'cause we have received an invalid object from the K8s API server")
     return
   }
   err := setupHost(dn)
   if err != nil {
-    log.Println("INFO: Creating host interfaces for DanmNet:" + dn.ObjectMeta.Name + " failed with error:" + err.Error())
+    getNetLogger(dn).Info("Creating host interfaces for DanmNet:" + dn.ObjectMeta.Name + " failed with error:" + err.Error())
   }
 }
 
 func UpdateDanmNet(oldObj, newObj interface{}) {
   oldDn, isNetwork := oldObj.(*danmtypes.DanmNet)
   if !isNetwork {
-    log.Println("ERROR: Can't update interfaces for DanmNet change, 'cause we have received an invalid old object from the K8s API server")
+    logger.Error("Can't update interfaces for DanmNet change, 'cause we have received an invalid old object from the K8s API server")
     return
   }
   newdDn, isNetwork := newObj.(*danmtypes.DanmNet)
   if !isNetwork {
-    log.Println("ERROR: Can't update interfaces for DanmNet change, 'cause we have received an invalid new object from the K8s API server")
+    logger.Error("Can't update interfaces for DanmNet change, 'cause we have received an invalid new object from the K8s API server")
     return
   }
   zeroVnis(oldDn,newdDn)
-  err := deleteNetworks(oldDn)
+  err := deleteNetworks(oldDn, isBridgeShared(oldDn,newdDn))
   if err != nil {
-    log.Println("INFO: Deletion of old host interfaces for DanmNet:" + oldDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
+    getNetLogger(oldDn).Info("Deletion of old host interfaces for DanmNet:" + oldDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
   }
   err = setupHost(newdDn)
   if err != nil {
-    log.Println("INFO: Creating host interfaces for new DanmNet:" + newdDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
+    getNetLogger(newdDn).Info("Creating host interfaces for new DanmNet:" + newdDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
   }
 }
 
@@ -156,56 +166,56 @@ func DeleteDanmNet(obj interface{}) {
   if !isNetwork {
     tombStone, objIsTombstone := obj.(cache.DeletedFinalStateUnknown)
       if !objIsTombstone {
-        log.Println("ERROR: Can't delete interfaces for DanmNet, 'cause we have received an invalid object from the K8s API server")
+        logger.Error("Can't delete interfaces for DanmNet, 'cause we have received an invalid object from the K8s API server")
         return
     }
     var isObjectInTombStoneNetwork bool
     dn, isObjectInTombStoneNetwork = tombStone.Obj.(*danmtypes.DanmNet)
     if !isObjectInTombStoneNetwork {
-      log.Println("ERROR: Can't delete interfaces for DanmNet, 'cause we have received an invalid object from the K8s API server in the Event tombstone")
+      logger.Error("Can't delete interfaces for DanmNet, 'cause we have received an invalid object from the K8s API server in the Event tombstone")
       return
     }
   }
-  err := deleteNetworks(dn)
+  err := deleteNetworks(dn, false)
   if err != nil {
-    log.Println("INFO: Deletion of host interfaces for DanmNet:" + dn.ObjectMeta.Name + " failed with error:" + err.Error())
+    getNetLogger(dn).Info("Deletion of host interfaces for DanmNet:" + dn.ObjectMeta.Name + " failed with error:" + err.Error())
   }
 }
 
 func AddTenantNetwork(obj interface{}) {
   tn, isNetwork := obj.(*danmtypes.TenantNetwork)
   if !isNetwork {
-    log.Println("ERROR: Can't create interfaces for TenantNetwork, 'cause we have received an invalid object from the K8s API server")
+    logger.Error("Can't create interfaces for TenantNetwork, 'cause we have received an invalid object from the K8s API server")
     return
   }
   dnet := ConvertTnetToDnet(tn)
   err := setupHost(dnet)
   if err != nil {
-    log.Println("INFO: Creating host interfaces for TenantNetwork:" + dnet.ObjectMeta.Name + " failed with error:" + err.Error())
+    getNetLogger(dnet).Info("Creating host interfaces for TenantNetwork:" + dnet.ObjectMeta.Name + " failed with error:" + err.Error())
   }
 }
 
 func UpdateTenantNetwork(oldObj, newObj interface{}) {
   oldTn, isNetwork := oldObj.(*danmtypes.TenantNetwork)
   if !isNetwork {
-    log.Println("ERROR: Can't update interfaces for TenantNetwork change, 'cause we have received an invalid old object from the K8s API server")
+    logger.Error("Can't update interfaces for TenantNetwork change, 'cause we have received an invalid old object from the K8s API server")
     return
   }
   newTn, isNetwork := newObj.(*danmtypes.TenantNetwork)
   if !isNetwork {
-    log.Println("ERROR: Can't update interfaces for TenantNetwork change, 'cause we have received an invalid new object from the K8s API server")
+    logger.Error("Can't update interfaces for TenantNetwork change, 'cause we have received an invalid new object from the K8s API server")
     return
   }
   oldDn := ConvertTnetToDnet(oldTn)
   newdDn := ConvertTnetToDnet(newTn)
   zeroVnis(oldDn,newdDn)
-  err := deleteNetworks(oldDn)
+  err := deleteNetworks(oldDn, isBridgeShared(oldDn,newdDn))
   if err != nil {
-    log.Println("INFO: Deletion of old host interfaces for TenantNetwork:" + oldDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
+    getNetLogger(oldDn).Info("Deletion of old host interfaces for TenantNetwork:" + oldDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
   }
   err = setupHost(newdDn)
   if err != nil {
-    log.Println("INFO: Creating host interfaces for new TenantNetwork:" + newdDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
+    getNetLogger(newdDn).Info("Creating host interfaces for new TenantNetwork:" + newdDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
   }
 }
 
@@ -214,57 +224,57 @@ func DeleteTenantNetwork(obj interface{}) {
   if !isNetwork {
     tombStone, objIsTombstone := obj.(cache.DeletedFinalStateUnknown)
       if !objIsTombstone {
-        log.Println("ERROR: Can't delete interfaces for TenantNetwork, 'cause we have received an invalid object from the K8s API server")
+        logger.Error("Can't delete interfaces for TenantNetwork, 'cause we have received an invalid object from the K8s API server")
         return
     }
     var isObjectInTombStoneNetwork bool
     tn, isObjectInTombStoneNetwork = tombStone.Obj.(*danmtypes.TenantNetwork)
     if !isObjectInTombStoneNetwork {
-      log.Println("ERROR: Can't delete interfaces for TenantNetwork, 'cause we have received an invalid object from the K8s API server in the Event tombstone")
+      logger.Error("Can't delete interfaces for TenantNetwork, 'cause we have received an invalid object from the K8s API server in the Event tombstone")
       return
     }
   }
   dn := ConvertTnetToDnet(tn)
-  err := deleteNetworks(dn)
+  err := deleteNetworks(dn, false)
   if err != nil {
-    log.Println("INFO: Deletion of host interfaces for TenantNetwork:" + dn.ObjectMeta.Name + " failed with error:" + err.Error())
+    getNetLogger(dn).Info("Deletion of host interfaces for TenantNetwork:" + dn.ObjectMeta.Name + " failed with error:" + err.Error())
   }
 }
 
 func AddClusterNetwork(obj interface{}) {
   cn, isNetwork := obj.(*danmtypes.ClusterNetwork)
   if !isNetwork {
-    log.Println("ERROR: Can't create interfaces for ClusterNetwork, 'cause we have received an invalid object from the K8s API server")
+    logger.Error("Can't create interfaces for ClusterNetwork, 'cause we have received an invalid object from the K8s API server")
     return
   }
   dnet := ConvertCnetToDnet(cn)
   err := setupHost(dnet)
   if err != nil {
-    log.Println("INFO: Creating host interfaces for ClusterNetwork:" + dnet.ObjectMeta.Name + " failed with error:" + err.Error())
+    getNetLogger(dnet).Info("Creating host interfaces for ClusterNetwork:" + dnet.ObjectMeta.Name + " failed with error:" + err.Error())
   }
 }
 
 func UpdateClusterNetwork(oldObj, newObj interface{}) {
   oldCn, isNetwork := oldObj.(*danmtypes.ClusterNetwork)
   if !isNetwork {
-    log.Println("ERROR: Can't update interfaces for ClusterNetwork change, 'cause we have received an invalid old object from the K8s API server")
+    logger.Error("Can't update interfaces for ClusterNetwork change, 'cause we have received an invalid old object from the K8s API server")
     return
   }
   newCn, isNetwork := newObj.(*danmtypes.ClusterNetwork)
   if !isNetwork {
-    log.Println("ERROR: Can't update interfaces for ClusterNetwork change, 'cause we have received an invalid new object from the K8s API server")
+    logger.Error("Can't update interfaces for ClusterNetwork change, 'cause we have received an invalid new object from the K8s API server")
     return
   }
   oldDn := ConvertCnetToDnet(oldCn)
   newdDn := ConvertCnetToDnet(newCn)
   zeroVnis(oldDn,newdDn)
-  err := deleteNetworks(oldDn)
+  err := deleteNetworks(oldDn, isBridgeShared(oldDn,newdDn))
   if err != nil {
-    log.Println("INFO: Deletion of old host interfaces for ClusterNetwork:" + oldDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
+    getNetLogger(oldDn).Info("Deletion of old host interfaces for ClusterNetwork:" + oldDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
   }
   err = setupHost(newdDn)
   if err != nil {
-    log.Println("INFO: Creating host interfaces for new ClusterNetwork:" + newdDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
+    getNetLogger(newdDn).Info("Creating host interfaces for new ClusterNetwork:" + newdDn.ObjectMeta.Name + " after update failed with error:" + err.Error())
   }
 }
 
@@ -273,20 +283,20 @@ func DeleteClusterNetwork(obj interface{}) {
   if !isNetwork {
     tombStone, objIsTombstone := obj.(cache.DeletedFinalStateUnknown)
       if !objIsTombstone {
-        log.Println("ERROR: Can't delete interfaces for ClusterNetwork, 'cause we have received an invalid object from the K8s API server")
+        logger.Error("Can't delete interfaces for ClusterNetwork, 'cause we have received an invalid object from the K8s API server")
         return
     }
     var isObjectInTombStoneNetwork bool
     cn, isObjectInTombStoneNetwork = tombStone.Obj.(*danmtypes.ClusterNetwork)
     if !isObjectInTombStoneNetwork {
-      log.Println("ERROR: Can't delete interfaces for ClusterNetwork, 'cause we have received an invalid object from the K8s API server in the Event tombstone")
+      logger.Error("Can't delete interfaces for ClusterNetwork, 'cause we have received an invalid object from the K8s API server in the Event tombstone")
       return
     }
   }
   dn := ConvertCnetToDnet(cn)
-  err := deleteNetworks(dn)
+  err := deleteNetworks(dn, false)
   if err != nil {
-    log.Println("INFO: Deletion of host interfaces for ClusterNetwork:" + dn.ObjectMeta.Name + " failed with error:" + err.Error())
+    getNetLogger(dn).Info("Deletion of host interfaces for ClusterNetwork:" + dn.ObjectMeta.Name + " failed with error:" + err.Error())
   }
 }
 
@@ -415,6 +425,14 @@ func RefreshNetwork(danmClient danmclientset.Interface, netInfo danmtypes.DanmNe
   return GetNetworkFromInterface(danmClient, dummyIface, netInfo.ObjectMeta.Namespace)
 }
 
+func getNetLogger(dnet *danmtypes.DanmNet) *logger.Entry {
+  fields := logger.Fields{logger.NetworkField: dnet.ObjectMeta.Name}
+  if dnet.ObjectMeta.Namespace != "" {
+    fields[logger.NamespaceField] = dnet.ObjectMeta.Namespace
+  }
+  return logger.WithFields(fields)
+}
+
 //Little trickery: if there was no change in the VNI+host_device combo during the update we set it to 0 in the manifests.
 //Thus we avoid unnecessarily recreating host interfaces.
 func zeroVnis(oldDn, newDn *danmtypes.DanmNet) {
@@ -426,4 +444,9 @@ func zeroVnis(oldDn, newDn *danmtypes.DanmNet) {
     oldDn.Spec.Options.Vxlan = 0
     newDn.Spec.Options.Vxlan = 0
   }
+}
+
+//Same goes for host bridges: the bridge of the old network shall be only deleted if the new one is not going to use it anymore
+func isBridgeShared(oldDn, newDn *danmtypes.DanmNet) bool {
+  return IsBridgeNetwork(oldDn) && IsBridgeNetwork(newDn) && GetBridgeName(oldDn) == GetBridgeName(newDn)
 }
\ No newline at end of file
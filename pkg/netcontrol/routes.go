@@ -0,0 +1,187 @@
+package netcontrol
+
+import (
+  "errors"
+  "net"
+  "os"
+  "strconv"
+  "strings"
+  "syscall"
+  "time"
+  "github.com/vishvananda/netlink"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
+  danminformers "github.com/nokia/danm/crd/client/informers/externalversions"
+  "github.com/nokia/danm/pkg/logger"
+  "k8s.io/client-go/tools/cache"
+)
+
+const (
+  DanmEpKind = "DanmEp"
+  DefaultRouteExportTable = 0
+  // RouteExportProtocol marks the routes published by netwatcher, so only these are ever flushed from the export table
+  RouteExportProtocol = 210
+)
+
+var (
+  // RouteExportTable is the host routing table into which netwatcher publishes the routes of the locally running Pods connected to routed networks.
+  // The table is not used for forwarding, it only exists so external routing daemons (e.g. BGP speakers) can redistribute its content.
+  // The feature is disabled by default, i.e. when it is set to 0.
+  RouteExportTable = DefaultRouteExportTable
+)
+
+func (netWatcher *NetWatcher) createEpInformer(epClient danmclientset.Interface) {
+  err := flushExportedRoutes()
+  if err != nil {
+    logger.WithFields(logger.Fields{"table": strconv.Itoa(RouteExportTable)}).Warning("stale Pod routes could not be flushed from export table:" + err.Error())
+  }
+  netWatcher.Clients[DanmEpKind] = epClient
+  epInformerFactory := danminformers.NewSharedInformerFactory(epClient, time.Minute*10)
+  netWatcher.Factories[DanmEpKind] = epInformerFactory
+  epController := epInformerFactory.Danm().V1().DanmEps().Informer()
+  epController.AddEventHandler(cache.ResourceEventHandlerFuncs{
+      AddFunc: AddDanmEp,
+      UpdateFunc: UpdateDanmEp,
+      DeleteFunc: DeleteDanmEp,
+  })
+  netWatcher.Controllers[DanmEpKind] = epController
+}
+
+func AddDanmEp(obj interface{}) {
+  ep, isEp := obj.(*danmtypes.DanmEp)
+  if !isEp {
+    logger.Error("Can't export Pod routes for DanmEp, 'cause we have received an invalid object from the K8s API server")
+    return
+  }
+  err := exportEpRoutes(ep)
+  if err != nil {
+    getEpLogger(ep).Info("Exporting Pod routes of DanmEp:" + ep.ObjectMeta.Name + " failed with error:" + err.Error())
+  }
+}
+
+func UpdateDanmEp(oldObj, newObj interface{}) {
+  oldEp, isEp := oldObj.(*danmtypes.DanmEp)
+  if !isEp {
+    logger.Error("Can't update Pod routes for DanmEp change, 'cause we have received an invalid old object from the K8s API server")
+    return
+  }
+  newEp, isEp := newObj.(*danmtypes.DanmEp)
+  if !isEp {
+    logger.Error("Can't update Pod routes for DanmEp change, 'cause we have received an invalid new object from the K8s API server")
+    return
+  }
+  if oldEp.Spec.Iface.Address != newEp.Spec.Iface.Address || oldEp.Spec.Iface.AddressIPv6 != newEp.Spec.Iface.AddressIPv6 {
+    err := withdrawEpRoutes(oldEp)
+    if err != nil {
+      getEpLogger(oldEp).Info("Withdrawing old Pod routes of DanmEp:" + oldEp.ObjectMeta.Name + " after update failed with error:" + err.Error())
+    }
+  }
+  err := exportEpRoutes(newEp)
+  if err != nil {
+    getEpLogger(newEp).Info("Exporting Pod routes of DanmEp:" + newEp.ObjectMeta.Name + " after update failed with error:" + err.Error())
+  }
+}
+
+func DeleteDanmEp(obj interface{}) {
+  ep, isEp := obj.(*danmtypes.DanmEp)
+  if !isEp {
+    tombStone, objIsTombstone := obj.(cache.DeletedFinalStateUnknown)
+    if !objIsTombstone {
+      logger.Error("Can't withdraw Pod routes of DanmEp, 'cause we have received an invalid object from the K8s API server")
+      return
+    }
+    var isObjectInTombStoneEp bool
+    ep, isObjectInTombStoneEp = tombStone.Obj.(*danmtypes.DanmEp)
+    if !isObjectInTombStoneEp {
+      logger.Error("Can't withdraw Pod routes of DanmEp, 'cause we have received an invalid object from the K8s API server in the Event tombstone")
+      return
+    }
+  }
+  err := withdrawEpRoutes(ep)
+  if err != nil {
+    getEpLogger(ep).Info("Withdrawing Pod routes of DanmEp:" + ep.ObjectMeta.Name + " failed with error:" + err.Error())
+  }
+}
+
+//Only the DanmEps of the routed networks belonging to the Pods of this very host are exported
+func isEpExported(ep *danmtypes.DanmEp) bool {
+  if RouteExportTable == 0 || strings.ToLower(ep.Spec.NetworkType) != "routed" {
+    return false
+  }
+  host, err := os.Hostname()
+  if err != nil {
+    return false
+  }
+  return ep.Spec.Host == host
+}
+
+func exportEpRoutes(ep *danmtypes.DanmEp) error {
+  if !isEpExported(ep) {
+    return nil
+  }
+  for _, route := range getExportedRoutes(ep) {
+    err := netlink.RouteReplace(route)
+    if err != nil {
+      return errors.New("cannot add route:" + route.Dst.String() + " to export table because:" + err.Error())
+    }
+  }
+  return nil
+}
+
+func withdrawEpRoutes(ep *danmtypes.DanmEp) error {
+  if !isEpExported(ep) {
+    return nil
+  }
+  for _, route := range getExportedRoutes(ep) {
+    err := netlink.RouteDel(route)
+    if err != nil && err != syscall.ESRCH {
+      return errors.New("cannot delete route:" + route.Dst.String() + " from export table because:" + err.Error())
+    }
+  }
+  return nil
+}
+
+//Exported routes are blackhole routes on purpose: they only advertise the destinations, forwarding is done based on the main routing table
+func getExportedRoutes(ep *danmtypes.DanmEp) []*netlink.Route {
+  var routes []*netlink.Route
+  for _, cidr := range []string{ep.Spec.Iface.Address, ep.Spec.Iface.AddressIPv6} {
+    ip, _, err := net.ParseCIDR(cidr)
+    if err != nil {
+      continue
+    }
+    maskLength := net.IPv6len*8
+    if ip.To4() != nil {
+      ip = ip.To4()
+      maskLength = net.IPv4len*8
+    }
+    routes = append(routes, &netlink.Route {
+      Dst:   &net.IPNet{IP: ip, Mask: net.CIDRMask(maskLength, maskLength)},
+      Table: RouteExportTable,
+      Type:  syscall.RTN_BLACKHOLE,
+      Protocol: RouteExportProtocol,
+    })
+  }
+  return routes
+}
+
+//Routes of the export table not published by netwatcher are left intact
+func flushExportedRoutes() error {
+  if RouteExportTable == 0 {
+    return nil
+  }
+  routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: RouteExportTable, Protocol: RouteExportProtocol}, netlink.RT_FILTER_TABLE|netlink.RT_FILTER_PROTOCOL)
+  if err != nil {
+    return err
+  }
+  for _, route := range routes {
+    err = netlink.RouteDel(&route)
+    if err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+func getEpLogger(ep *danmtypes.DanmEp) *logger.Entry {
+  return logger.WithFields(logger.Fields{logger.PodField: ep.Spec.Pod, logger.NamespaceField: ep.ObjectMeta.Namespace, logger.NetworkField: ep.Spec.NetworkName, logger.EndpointIdField: ep.Spec.EndpointID})
+}
@@ -2,7 +2,6 @@ package svccontrol
 
 import (
 	"fmt"
-	"github.com/golang/glog"
 	corev1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -24,6 +23,7 @@ import (
 	danmlisters "github.com/nokia/danm/crd/client/listers/danm/v1"
   "github.com/nokia/danm/pkg/datastructs"
   "github.com/nokia/danm/pkg/ipam"
+  "github.com/nokia/danm/pkg/logger"
 )
 
 const (
@@ -54,7 +54,7 @@ func NewController(
 	danmepInformer danminformers.DanmEpInformer) *Controller {
 
 	danmscheme.AddToScheme(scheme.Scheme)
-	glog.Info("Creating event broadcaster")
+	logger.Info("Creating event broadcaster")
 
 	controller := &Controller{
 		kubeclient:    kubeclient,
@@ -70,7 +70,7 @@ func NewController(
 		workqueue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Endpoints"),
 	}
 
-	glog.Info("Setting up event handlers")
+	logger.Info("Setting up event handlers")
 
 	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		UpdateFunc: controller.updatePod,
@@ -94,21 +94,21 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	defer runtime.HandleCrash()
 	defer c.workqueue.ShutDown()
 
-	glog.Info("Starting svcwatcher controller")
+	logger.Info("Starting svcwatcher controller")
 
-	glog.Info("Waiting for informer caches to sync")
+	logger.Info("Waiting for informer caches to sync")
 	if ok := cache.WaitForCacheSync(stopCh, c.serviceSynced, c.epsSynced, c.podSynced, c.danmepSynced); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
-	glog.Info("Starting workers")
+	logger.Info("Starting workers")
 	for i := 0; i < threadiness; i++ {
 		go wait.Until(c.runWorker, time.Second, stopCh)
 	}
 
-	glog.Info("Started workers")
+	logger.Info("Started workers")
 	<-stopCh
-	glog.Info("Shutting down workers")
+	logger.Info("Shutting down workers")
 
 	return nil
 }
@@ -138,7 +138,7 @@ func (c *Controller) processNextWorkItem() bool {
 			return fmt.Errorf("error syncing '%s': %s", key, err.Error())
 		}
 		c.workqueue.Forget(obj)
-		glog.V(5).Infof("Successfully synced '%s'", key)
+		logger.Debug(fmt.Sprintf("Successfully synced '%s'", key))
 		return nil
 	}(obj)
 
@@ -153,11 +153,10 @@ func (c *Controller) processNextWorkItem() bool {
 func (c *Controller) syncHandler(key string) error {
 	ns, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		fmt.Println("!!! ERROR !!!")
+		logger.Error(fmt.Sprintf("invalid resource key: %s", key))
 		return nil
 	}
-	fmt.Printf("!!! ns, name: %s %s\n", ns, name)
-	fmt.Printf("!!! key: %s\n", key)
+	logger.WithFields(logger.Fields{logger.NamespaceField: ns, "name": name}).Debug("resource is synced")
 	return nil
 }
 
@@ -253,7 +252,7 @@ func (c* Controller) UpdatePodStatusInEps(epsList []*corev1.Endpoints, pod *core
 	for _, eps := range epsList {
 		svc, err := c.serviceLister.Services(eps.Namespace).Get(eps.Name)
 		if err != nil {
-			glog.Errorf("pod update: get svc %s", err)
+			logger.Error(fmt.Sprintf("pod update: get svc %s", err))
 			continue
 		}
 		if eps.Subsets == nil {
@@ -317,7 +316,7 @@ func (c *Controller) MakeNewEps(svc *corev1.Service, des []*danmv1.DanmEp) (core
 	for _, de := range des {
 		pod, err := c.podLister.Pods(de.Namespace).Get(de.Spec.Pod)
 		if err != nil {
-			glog.Errorf("makeneweps: get pod %s", err)
+			logger.Error(fmt.Sprintf("makeneweps: get pod %s", err))
 			continue
 		}
 		targetRef := &corev1.ObjectReference{
@@ -362,13 +361,13 @@ func (c *Controller) addDanmep(obj interface{}) {
 	if !c.podSynced() || !c.serviceSynced() || !c.epsSynced() || !c.danmepSynced() {
 		return
 	}
-	glog.V(5).Infof("addDanmep is called: %s %s", obj.(*danmv1.DanmEp).GetName(), obj.(*danmv1.DanmEp).GetNamespace())
+	logger.Debug(fmt.Sprintf("addDanmep is called: %s %s", obj.(*danmv1.DanmEp).GetName(), obj.(*danmv1.DanmEp).GetNamespace()))
 	de := obj.(*danmv1.DanmEp)
   ipAddr, ip6Addr := getIpsFromDanmEp(de)
 	svcNamespaceLister := c.serviceLister.Services(de.ObjectMeta.Namespace)
   svcList, err := svcNamespaceLister.List(labels.Everything())
 	if err != nil {
-		glog.Errorf("addDanmEp: get services: %s", err)
+		logger.Error(fmt.Sprintf("addDanmEp: get services: %s", err))
 		return
 	}
 	matchedSvcList := MatchExistingSvc(de, svcList)
@@ -376,13 +375,13 @@ func (c *Controller) addDanmep(obj interface{}) {
 		for _, svc := range matchedSvcList {
 			pod, err := c.podLister.Pods(de.ObjectMeta.Namespace).Get(de.Spec.Pod)
 			if err != nil {
-				glog.Errorf("addDanmEp: get pod %s", err)
+				logger.Error(fmt.Sprintf("addDanmEp: get pod %s", err))
 				continue
 			}
       for i := 0; i < MaxUpdateRetry; i++ {
 			  eps, err := c.epsLister.Endpoints(svc.ObjectMeta.Namespace).Get(svc.ObjectMeta.Name)
 			  if err != nil && !errors.IsNotFound(err) {
-				  glog.Errorf("addDanmEp: get ep %s", err)
+				  logger.Error(fmt.Sprintf("addDanmEp: get ep %s", err))
 				  break
 			  }
 			  if eps != nil && eps.Subsets != nil {
@@ -393,7 +392,7 @@ func (c *Controller) addDanmep(obj interface{}) {
               time.Sleep(RetryInterval * time.Millisecond)
               continue
             } else {
-              glog.Errorf("Endpoint update for new DanmEp:%s failed with error:%s", de.ObjectMeta.Name, err)
+              logger.Error(fmt.Sprintf("Endpoint update for new DanmEp:%s failed with error:%s", de.ObjectMeta.Name, err))
             }
           }
         } else {
@@ -404,7 +403,7 @@ func (c *Controller) addDanmep(obj interface{}) {
               time.Sleep(RetryInterval * time.Millisecond)
               continue
             } else {
-              glog.Errorf("Endpoint creation for new DanmEp:%s failed with error:%s", de.ObjectMeta.Name, err)
+              logger.Error(fmt.Sprintf("Endpoint creation for new DanmEp:%s failed with error:%s", de.ObjectMeta.Name, err))
             }
           }
         }
@@ -415,7 +414,7 @@ func (c *Controller) addDanmep(obj interface{}) {
 }
 
 func (c *Controller) updateDanmep(old, new interface{}) {
-	glog.V(5).Infof("updateDanmep is called: %s %s", new.(*danmv1.DanmEp).GetName(), new.(*danmv1.DanmEp).GetNamespace())
+	logger.Debug(fmt.Sprintf("updateDanmep is called: %s %s", new.(*danmv1.DanmEp).GetName(), new.(*danmv1.DanmEp).GetNamespace()))
 	oldDanmEp := old.(*danmv1.DanmEp)
 	newDanmEp := new.(*danmv1.DanmEp)
 	if oldDanmEp.ResourceVersion == newDanmEp.ResourceVersion {
@@ -426,7 +425,7 @@ func (c *Controller) updateDanmep(old, new interface{}) {
 }
 
 func (c *Controller) delDanmep(obj interface{}) {
-	glog.V(5).Infof("delDanmep is called: %s %s", obj.(*danmv1.DanmEp).GetName(), obj.(*danmv1.DanmEp).GetNamespace())
+	logger.Debug(fmt.Sprintf("delDanmep is called: %s %s", obj.(*danmv1.DanmEp).GetName(), obj.(*danmv1.DanmEp).GetNamespace()))
 	de := obj.(*danmv1.DanmEp)
   ipAddr, ip6Addr := getIpsFromDanmEp(de)
 	var epList []*corev1.Endpoints
@@ -434,7 +433,7 @@ func (c *Controller) delDanmep(obj interface{}) {
   	epNamespaceLister := c.epsLister.Endpoints(de.ObjectMeta.Namespace)
 	  epsList, err := epNamespaceLister.List(labels.Everything())
 	  if err != nil {
-		  glog.Errorf("delDanmep: get epslist: %s", err)
+		  logger.Error(fmt.Sprintf("delDanmep: get epslist: %s", err))
 		  return
 	  }
 	  for _, ep := range epsList {
@@ -445,7 +444,7 @@ func (c *Controller) delDanmep(obj interface{}) {
 		  annotations := epNew.GetAnnotations()
 		  selectorMap, svcNets, err := GetDanmSvcAnnotations(annotations)
 		  if err != nil {
-			  glog.Errorf("delDanmEp: selector %s", err)
+			  logger.Error(fmt.Sprintf("delDanmEp: selector %s", err))
 			  return
 		  }
 		  if len(selectorMap) == 0 || !isDepSelectedBySvc(de, svcNets) || epNew.Namespace != de.ObjectMeta.Namespace {
@@ -470,7 +469,7 @@ func (c *Controller) delDanmep(obj interface{}) {
           time.Sleep(RetryInterval * time.Millisecond)
           continue
         } else {
-          glog.Errorf("delete DanmEp event could not be processed for V4 address: %s and V6 address: %s because of error:%v", ipAddr, ip6Addr, err)
+          logger.Error(fmt.Sprintf("delete DanmEp event could not be processed for V4 address: %s and V6 address: %s because of error:%v", ipAddr, ip6Addr, err))
         }
       }
     }
@@ -484,7 +483,7 @@ func (c *Controller) delDanmep(obj interface{}) {
 //                       //
 ///////////////////////////
 func (c *Controller) updatePod(old, new interface{}) {
-	glog.V(5).Infof("updatePod is called: %s %s", new.(*corev1.Pod).GetName(), new.(*corev1.Pod).GetNamespace())
+	logger.Debug(fmt.Sprintf("updatePod is called: %s %s", new.(*corev1.Pod).GetName(), new.(*corev1.Pod).GetNamespace()))
 	oldPod := old.(*corev1.Pod)
 	newPod := new.(*corev1.Pod)
 	if oldPod.ResourceVersion == newPod.ResourceVersion || newPod.ObjectMeta.DeletionTimestamp != nil {
@@ -499,7 +498,7 @@ func (c *Controller) updatePod(old, new interface{}) {
   	  epNamespaceLister := c.epsLister.Endpoints(newPod.ObjectMeta.Namespace)
 	    epsList, err := epNamespaceLister.List(labels.Everything())
 	    if err != nil {
-		    glog.Errorf("updatePod: get eps %s", err)
+		    logger.Error(fmt.Sprintf("updatePod: get eps %s", err))
 		    return
 	    }
       epList := c.UpdatePodRvInEps(epsList, newPod)
@@ -510,7 +509,7 @@ func (c *Controller) updatePod(old, new interface{}) {
             time.Sleep(RetryInterval * time.Millisecond)
             continue
           } else {
-            glog.Errorf("Endpoint update for changed Pod:%s failed with error:%s", newPod.ObjectMeta.Name, err)
+            logger.Error(fmt.Sprintf("Endpoint update for changed Pod:%s failed with error:%s", newPod.ObjectMeta.Name, err))
           }
         }
       }
@@ -524,7 +523,7 @@ func (c *Controller) updatePod(old, new interface{}) {
   	  epNamespaceLister := c.epsLister.Endpoints(newPod.ObjectMeta.Namespace)
 	    epsList, err := epNamespaceLister.List(labels.Everything())
 	    if err != nil {
-		    glog.Errorf("updatePod: get eps %s", err)
+		    logger.Error(fmt.Sprintf("updatePod: get eps %s", err))
 		    return
 	    }
 		  epList := c.UpdatePodStatusInEps(epsList, newPod, oldReady, newReady)
@@ -535,7 +534,7 @@ func (c *Controller) updatePod(old, new interface{}) {
             time.Sleep(RetryInterval * time.Millisecond)
             continue
           } else {
-            glog.Errorf("Endpoint update for changed Pod:%s failed with error:%s", newPod.ObjectMeta.Name, err)
+            logger.Error(fmt.Sprintf("Endpoint update for changed Pod:%s failed with error:%s", newPod.ObjectMeta.Name, err))
           }
         }
       }
@@ -550,7 +549,7 @@ func (c *Controller) updatePod(old, new interface{}) {
   	depNamespaceLister := c.danmepLister.DanmEps(newPod.ObjectMeta.Namespace)
 	  desList, err := depNamespaceLister.List(labels.Everything())
 		if err != nil {
-			glog.Errorf("updatePod: get danmep %s", err)
+			logger.Error(fmt.Sprintf("updatePod: get danmep %s", err))
 			return
 		}
 		for _, de := range desList {
@@ -560,7 +559,7 @@ func (c *Controller) updatePod(old, new interface{}) {
 				deNew.SetLabels(deLabels)
 				_, err = c.danmclient.DanmV1().DanmEps(deNew.Namespace).Update(deNew)
         if err != nil {
-          glog.Errorf("DanmEp:%s label update for changed Pod:%s failed with error:%s", deNew.ObjectMeta.Name, newPod.ObjectMeta.Name, err)
+          logger.Error(fmt.Sprintf("DanmEp:%s label update for changed Pod:%s failed with error:%s", deNew.ObjectMeta.Name, newPod.ObjectMeta.Name, err))
         }
       }
 		}
@@ -576,14 +575,14 @@ func (c *Controller) addSvc(obj interface{}) {
 	if !c.podSynced() || !c.serviceSynced() || !c.epsSynced() || !c.danmepSynced() {
 		return
 	}
-	glog.V(5).Infof("addSvc is called: %s %s", obj.(*corev1.Service).GetName(), obj.(*corev1.Service).GetNamespace())
+	logger.Debug(fmt.Sprintf("addSvc is called: %s %s", obj.(*corev1.Service).GetName(), obj.(*corev1.Service).GetNamespace()))
 	svc := obj.(*corev1.Service)
 	svcNs := svc.Namespace
 	svcName := svc.Name
 	annotations := svc.Annotations
 	selectorMap, svcNets, err := GetDanmSvcAnnotations(annotations)
 	if err != nil {
-		glog.Errorf("addSvc: get anno %s", err)
+		logger.Error(fmt.Sprintf("addSvc: get anno %s", err))
 		return
 	}
 	if len(selectorMap) > 0 && len(svcNets) > 0 {
@@ -591,14 +590,14 @@ func (c *Controller) addSvc(obj interface{}) {
   	  depNamespaceLister := c.danmepLister.DanmEps(svcNs)
 	    desList, err := depNamespaceLister.List(labels.Everything())
 		  if err != nil {
-			  glog.Errorf("addSvc: get danmep %s", err)
+			  logger.Error(fmt.Sprintf("addSvc: get danmep %s", err))
 			  return
 		  }
 		  matchingDesList := SelectDesMatchLabels(desList, selectorMap, svcNets, svcNs)
   	  epNamespaceLister := c.epsLister.Endpoints(svcNs)
 	    epsList, err := epNamespaceLister.List(labels.Everything())
 		  if err != nil {
-			  glog.Errorf("addSvc: get eps %s", err)
+			  logger.Error(fmt.Sprintf("addSvc: get eps %s", err))
 			  return
 		  }
 		  epFound := FindEpsForSvc(epsList, svcName, svcNs)
@@ -608,7 +607,7 @@ func (c *Controller) addSvc(obj interface{}) {
           time.Sleep(RetryInterval * time.Millisecond)
           continue
         } else {
-          glog.Errorf("Endpoint creation for new Service:%s failed with error:%s", svcName, err)
+          logger.Error(fmt.Sprintf("Endpoint creation for new Service:%s failed with error:%s", svcName, err))
         }
       }
       break
@@ -617,7 +616,7 @@ func (c *Controller) addSvc(obj interface{}) {
 }
 
 func (c *Controller) updateSvc(old, new interface{}) {
-	glog.V(5).Infof("updateSvc is called: %s %s", new.(*corev1.Service).GetName(), new.(*corev1.Service).GetNamespace())
+	logger.Debug(fmt.Sprintf("updateSvc is called: %s %s", new.(*corev1.Service).GetName(), new.(*corev1.Service).GetNamespace()))
 	oldSvc := old.(*corev1.Service)
 	newSvc := new.(*corev1.Service)
 	if oldSvc.ResourceVersion == newSvc.ResourceVersion || !SvcChanged(oldSvc, newSvc) {
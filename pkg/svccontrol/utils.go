@@ -2,9 +2,10 @@ package svccontrol
 
 import (
 	"encoding/json"
-	"github.com/golang/glog"
+	"fmt"
 	corev1 "k8s.io/api/core/v1"
 	danmv1 "github.com/nokia/danm/crd/apis/danm/v1"
+	"github.com/nokia/danm/pkg/logger"
 	"github.com/nokia/danm/pkg/netcontrol"
 	"reflect"
 )
@@ -41,7 +42,7 @@ func GetDanmSvcAnnotations(annotations map[string]string) (map[string]string, ma
 		if danmSel != "" {
 			err := json.Unmarshal([]byte(danmSel), &selectorMap)
 			if err != nil {
-				glog.Errorf("utils: json error: %s", err)
+				logger.Error(fmt.Sprintf("utils: json error: %s", err))
 				return selectorMap, netSelectors, err
 			}
 		}
@@ -1,9 +1,10 @@
 package syncher
 
 import (
+  "context"
   "errors"
   "fmt"
-  "strconv"
+  "sort"
   "strings"
   "sync"
   "time"
@@ -11,51 +12,153 @@ import (
 )
 
 const (
-  MaximumAllowedTime = 3000
+  DefaultTimeout = 30 * time.Second
+  CancelGracePeriod = 5 * time.Second
 )
 
 type cniOpResult struct {
   CniName string
+  SequenceId int
   OpResult error
   CniResult *current.Result
 }
 
+// Syncher aggregates the results of CNI operations executed in parallel goroutines
+// Operations not finishing before the deadline of the Syncher are cancelled via its Context, and their results are rejected
 type Syncher struct {
   ExpectedNumOfResults int
   CniResults []cniOpResult
   mux sync.Mutex
+  timeout time.Duration
+  ctx context.Context
+  cancel context.CancelFunc
+  resultArrived chan struct{}
+  pendingOps map[string]int
+  runningOps sync.WaitGroup
 }
 
-func NewSyncher(numOfResults int) *Syncher {
-  syncher := Syncher{}
-  syncher.ExpectedNumOfResults = numOfResults
+// NewSyncher creates a Syncher expecting the given number of results within the given timeout
+// DefaultTimeout is used when timeout is not positive
+func NewSyncher(numOfResults int, timeout time.Duration) *Syncher {
+  if timeout <= 0 {
+    timeout = DefaultTimeout
+  }
+  ctx, cancel := context.WithTimeout(context.Background(), timeout)
+  syncher := Syncher {
+    ExpectedNumOfResults: numOfResults,
+    timeout: timeout,
+    ctx: ctx,
+    cancel: cancel,
+    resultArrived: make(chan struct{}, 1),
+    pendingOps: make(map[string]int),
+  }
   return &syncher
 }
 
-func (synch *Syncher) PushResult(cniName string, opRes error, cniRes *current.Result) {
+// Context returns the Context of the Syncher, which is cancelled when the results are aggregated, or the deadline is exceeded
+// Long running CNI operations shall be bound to it
+func (synch *Syncher) Context() context.Context {
+  return synch.ctx
+}
+
+// StartOperation registers a CNI operation as pending, so it can be reported in case it does not finish in time
+// Every started operation shall call OperationFinished when it returns, including the clean-up of its rejected results
+func (synch *Syncher) StartOperation(cniName string) {
+  synch.mux.Lock()
+  defer synch.mux.Unlock()
+  synch.pendingOps[cniName]++
+  synch.runningOps.Add(1)
+}
+
+// OperationFinished signals that a started CNI operation returned
+func (synch *Syncher) OperationFinished() {
+  synch.runningOps.Done()
+}
+
+// WaitForOperations blocks until all the started operations return, but at most for CancelGracePeriod
+// Used to give the cancelled operations a chance to clean-up after themselves before the process exits
+// Returns false if some operations were still running when the grace period expired
+func (synch *Syncher) WaitForOperations() bool {
+  allFinished := make(chan struct{})
+  go func() {
+    synch.runningOps.Wait()
+    close(allFinished)
+  }()
+  select {
+  case <-allFinished:
+    return true
+  case <-time.After(CancelGracePeriod):
+    return false
+  }
+}
+
+// PushResult records the result of a CNI operation
+// sequenceId is the position of the interface in the network connection annotation of the Pod, which determines its place in the merged CNI result
+// Returns false if the result was rejected because the Syncher already timed-out, in which case the caller shall clean-up whatever it has created
+func (synch *Syncher) PushResult(cniName string, sequenceId int, opRes error, cniRes *current.Result) bool {
   synch.mux.Lock()
   defer synch.mux.Unlock()
+  if synch.ctx.Err() == context.DeadlineExceeded {
+    return false
+  }
   cniOpResult := cniOpResult {
     CniName: cniName,
+    SequenceId: sequenceId,
     OpResult: opRes,
     CniResult: cniRes,
   }
   synch.CniResults = append(synch.CniResults, cniOpResult)
+  if synch.pendingOps[cniName] > 0 {
+    synch.pendingOps[cniName]--
+  }
+  select {
+  case synch.resultArrived <- struct{}{}:
+  default:
+  }
+  return true
 }
 
+// GetAggregatedResult blocks until all the expected results arrive, or the deadline of the Syncher is exceeded
+// Returns the merged errors of all the failed operations, or a time-out error naming the still pending ones
 func (synch *Syncher) GetAggregatedResult() error {
-  //Time-out Pod creation if plugins did not provide results within the configured timeframe
-  for i := 0; i < MaximumAllowedTime; i++ {
-    if synch.ExpectedNumOfResults > len(synch.CniResults) {
-      time.Sleep(10 * time.Millisecond)
-      continue
+  defer synch.cancel()
+  for !synch.areAllResultsReceived() {
+    select {
+    case <-synch.resultArrived:
+    case <-synch.ctx.Done():
+      //The last result might have arrived right before the deadline
+      if !synch.areAllResultsReceived() {
+        return synch.createTimeoutError()
+      }
     }
-    if synch.wasAnyOperationErroneous() {
-      return synch.mergeErrorMessages()
+  }
+  if synch.WasAnyOperationErroneous() {
+    return synch.mergeErrorMessages()
+  }
+  return nil
+}
+
+func (synch *Syncher) areAllResultsReceived() bool {
+  synch.mux.Lock()
+  defer synch.mux.Unlock()
+  return len(synch.CniResults) >= synch.ExpectedNumOfResults
+}
+
+func (synch *Syncher) createTimeoutError() error {
+  synch.mux.Lock()
+  defer synch.mux.Unlock()
+  var pendingOps []string
+  for cniName, numOfOps := range synch.pendingOps {
+    if numOfOps > 0 {
+      pendingOps = append(pendingOps, cniName)
     }
-    return nil
   }
-  return errors.New("CNI operation timed-out after " + strconv.Itoa(MaximumAllowedTime) + " seconds")
+  sort.Strings(pendingOps)
+  errorMessage := "CNI operation timed-out after " + synch.timeout.String()
+  if len(pendingOps) > 0 {
+    errorMessage += ", operations for the following networks did not finish in time:" + strings.Join(pendingOps, ",")
+  }
+  return errors.New(errorMessage)
 }
 
 func (synch *Syncher) wasAnyOperationErroneous() bool {
@@ -68,6 +171,8 @@ func (synch *Syncher) wasAnyOperationErroneous() bool {
 }
 
 func (synch *Syncher) mergeErrorMessages() error {
+  synch.mux.Lock()
+  defer synch.mux.Unlock()
   var aggregatedErrors []string
   for _, cniRes := range synch.CniResults {
     if cniRes.OpResult != nil {
@@ -77,19 +182,46 @@ func (synch *Syncher) mergeErrorMessages() error {
   return fmt.Errorf(strings.Join(aggregatedErrors, "\n"))
 }
 
+// MergeCniResults merges the results of all the CNI operations into one CNI result
+// Results are ordered by their sequence ID independently from the order the operations finished in, so the IPs of the first interface (i.e. eth0) always come first.
+// Kubelet relies on this when it chooses the Pod IP
 func (synch *Syncher) MergeCniResults() *current.Result {
+  synch.mux.Lock()
+  defer synch.mux.Unlock()
+  orderedResults := make([]cniOpResult, len(synch.CniResults))
+  copy(orderedResults, synch.CniResults)
+  sort.SliceStable(orderedResults, func(i, j int) bool {
+    return orderedResults[i].SequenceId < orderedResults[j].SequenceId
+  })
   aggregatedCniRes := current.Result{}
-  for _, cniRes := range synch.CniResults {
+  for _, cniRes := range orderedResults {
     if cniRes.CniResult != nil {
-      aggregatedCniRes.Interfaces = append(aggregatedCniRes.Interfaces, cniRes.CniResult.Interfaces...)
-      aggregatedCniRes.IPs = append(aggregatedCniRes.IPs, cniRes.CniResult.IPs...)
-      aggregatedCniRes.Routes = append(aggregatedCniRes.Routes, cniRes.CniResult.Routes...)
+      mergeCniResult(&aggregatedCniRes, cniRes.CniResult)
     }
   }
   return &aggregatedCniRes
 }
 
+//IP entries reference their interface by its index in the result of their own CNI operation, which needs to be shifted after merging
+//Entries without a reference are assigned to the last interface of their result, as that is the container side interface in the results of all well-behaving plugins
+func mergeCniResult(aggregatedCniRes, cniRes *current.Result) {
+  ifaceOffset := len(aggregatedCniRes.Interfaces)
+  aggregatedCniRes.Interfaces = append(aggregatedCniRes.Interfaces, cniRes.Interfaces...)
+  for _, ip := range cniRes.IPs {
+    mergedIp := *ip
+    if mergedIp.Interface != nil {
+      mergedIp.Interface = current.Int(*mergedIp.Interface + ifaceOffset)
+    } else if len(cniRes.Interfaces) > 0 {
+      mergedIp.Interface = current.Int(ifaceOffset + len(cniRes.Interfaces) - 1)
+    }
+    aggregatedCniRes.IPs = append(aggregatedCniRes.IPs, &mergedIp)
+  }
+  aggregatedCniRes.Routes = append(aggregatedCniRes.Routes, cniRes.Routes...)
+}
+
 func (synch *Syncher) WasAnyOperationErroneous() bool {
+  synch.mux.Lock()
+  defer synch.mux.Unlock()
   if len(synch.CniResults) == 0 {
     return false
   }
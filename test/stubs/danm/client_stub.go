@@ -30,12 +30,16 @@ func (client *ClientStub) TenantConfigs() client.TenantConfigInterface {
   return client.TconfClient
 }
 
+func (client *ClientStub) CniTemplates() client.CniTemplateInterface {
+  return newTemplateClientStub(client.Objects.TestTemplates)
+}
+
 func (client *ClientStub) TenantNetworks(namespace string) client.TenantNetworkInterface {
-  return nil
+  return newTnetClientStub(client.Objects.TestTnets)
 }
 
 func (client *ClientStub) ClusterNetworks() client.ClusterNetworkInterface {
-  return nil
+  return newCnetClientStub(client.Objects.TestCnets)
 }
 
 func (c *ClientStub) RESTClient() rest.Interface {
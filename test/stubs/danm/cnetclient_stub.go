@@ -0,0 +1,54 @@
+package danm
+
+import (
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  types "k8s.io/apimachinery/pkg/types"
+  watch "k8s.io/apimachinery/pkg/watch"
+)
+
+type ClusterNetworkClientStub struct{
+  TestNets []danmtypes.ClusterNetwork
+}
+
+func newCnetClientStub(nets []danmtypes.ClusterNetwork) ClusterNetworkClientStub {
+  return ClusterNetworkClientStub{TestNets: nets}
+}
+
+func (netClient ClusterNetworkClientStub) Create(obj *danmtypes.ClusterNetwork) (*danmtypes.ClusterNetwork, error) {
+  return nil, nil
+}
+
+func (netClient ClusterNetworkClientStub) Update(obj *danmtypes.ClusterNetwork) (*danmtypes.ClusterNetwork, error) {
+  return nil, nil
+}
+
+func (netClient ClusterNetworkClientStub) Delete(name string, options *meta_v1.DeleteOptions) error {
+  return nil
+}
+
+func (netClient ClusterNetworkClientStub) DeleteCollection(options *meta_v1.DeleteOptions, listOptions meta_v1.ListOptions) error {
+  return nil
+}
+
+func (netClient ClusterNetworkClientStub) Get(netName string, options meta_v1.GetOptions) (*danmtypes.ClusterNetwork, error) {
+  for _, net := range netClient.TestNets {
+    if net.ObjectMeta.Name == netName {
+      return &net, nil
+    }
+  }
+  return nil, nil
+}
+
+func (netClient ClusterNetworkClientStub) Watch(opts meta_v1.ListOptions) (watch.Interface, error) {
+  watch := watch.NewEmptyWatch()
+  return watch, nil
+}
+
+func (netClient ClusterNetworkClientStub) List(opts meta_v1.ListOptions) (*danmtypes.ClusterNetworkList, error) {
+  return &danmtypes.ClusterNetworkList{Items: netClient.TestNets}, nil
+}
+
+func (netClient ClusterNetworkClientStub) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *danmtypes.ClusterNetwork, err error) {
+  return nil, nil
+}
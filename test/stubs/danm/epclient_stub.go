@@ -25,6 +25,10 @@ func (epClient EpClientStub) Update(obj *danmtypes.DanmEp) (*danmtypes.DanmEp, e
   return nil, nil
 }
 
+func (epClient EpClientStub) UpdateStatus(obj *danmtypes.DanmEp) (*danmtypes.DanmEp, error) {
+  return nil, nil
+}
+
 func (epClient EpClientStub) Delete(name string, options *meta_v1.DeleteOptions) error {
   return nil
 }
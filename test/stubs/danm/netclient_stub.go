@@ -103,7 +103,7 @@ func (netClient *NetClientStub) Watch(opts meta_v1.ListOptions) (watch.Interface
 }
 
 func (netClient *NetClientStub) List(opts meta_v1.ListOptions) (*danmtypes.DanmNetList, error) {
-  return nil, nil
+  return &danmtypes.DanmNetList{Items: netClient.TestNets}, nil
 }
 
 func (netClient *NetClientStub) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *danmtypes.DanmNet, err error) {
@@ -0,0 +1,60 @@
+package danm
+
+import (
+  "errors"
+  "strings"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  types "k8s.io/apimachinery/pkg/types"
+  watch "k8s.io/apimachinery/pkg/watch"
+)
+
+type TemplateClientStub struct{
+  TestTemplates []danmtypes.CniTemplate
+}
+
+func newTemplateClientStub(templates []danmtypes.CniTemplate) TemplateClientStub {
+  return TemplateClientStub{TestTemplates: templates}
+}
+
+func (templateClient TemplateClientStub) Create(obj *danmtypes.CniTemplate) (*danmtypes.CniTemplate, error) {
+  return nil, nil
+}
+
+func (templateClient TemplateClientStub) Update(obj *danmtypes.CniTemplate) (*danmtypes.CniTemplate, error) {
+  return nil, nil
+}
+
+func (templateClient TemplateClientStub) Delete(name string, options *meta_v1.DeleteOptions) error {
+  return nil
+}
+
+func (templateClient TemplateClientStub) DeleteCollection(options *meta_v1.DeleteOptions, listOptions meta_v1.ListOptions) error {
+  return nil
+}
+
+func (templateClient TemplateClientStub) Get(templateName string, options meta_v1.GetOptions) (*danmtypes.CniTemplate, error) {
+  if strings.HasPrefix(templateName,"error") {
+    return nil, errors.New("here you go")
+  }
+  for _, template := range templateClient.TestTemplates {
+    if template.ObjectMeta.Name == templateName {
+      return &template, nil
+    }
+  }
+  return nil, nil
+}
+
+func (templateClient TemplateClientStub) Watch(opts meta_v1.ListOptions) (watch.Interface, error) {
+  watch := watch.NewEmptyWatch()
+  return watch, nil
+}
+
+func (templateClient TemplateClientStub) List(opts meta_v1.ListOptions) (*danmtypes.CniTemplateList, error) {
+  templateList := danmtypes.CniTemplateList{Items: templateClient.TestTemplates}
+  return &templateList, nil
+}
+
+func (templateClient TemplateClientStub) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *danmtypes.CniTemplate, err error) {
+  return nil, nil
+}
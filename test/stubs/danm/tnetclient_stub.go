@@ -0,0 +1,54 @@
+package danm
+
+import (
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  types "k8s.io/apimachinery/pkg/types"
+  watch "k8s.io/apimachinery/pkg/watch"
+)
+
+type TenantNetworkClientStub struct{
+  TestNets []danmtypes.TenantNetwork
+}
+
+func newTnetClientStub(nets []danmtypes.TenantNetwork) TenantNetworkClientStub {
+  return TenantNetworkClientStub{TestNets: nets}
+}
+
+func (netClient TenantNetworkClientStub) Create(obj *danmtypes.TenantNetwork) (*danmtypes.TenantNetwork, error) {
+  return nil, nil
+}
+
+func (netClient TenantNetworkClientStub) Update(obj *danmtypes.TenantNetwork) (*danmtypes.TenantNetwork, error) {
+  return nil, nil
+}
+
+func (netClient TenantNetworkClientStub) Delete(name string, options *meta_v1.DeleteOptions) error {
+  return nil
+}
+
+func (netClient TenantNetworkClientStub) DeleteCollection(options *meta_v1.DeleteOptions, listOptions meta_v1.ListOptions) error {
+  return nil
+}
+
+func (netClient TenantNetworkClientStub) Get(netName string, options meta_v1.GetOptions) (*danmtypes.TenantNetwork, error) {
+  for _, net := range netClient.TestNets {
+    if net.ObjectMeta.Name == netName {
+      return &net, nil
+    }
+  }
+  return nil, nil
+}
+
+func (netClient TenantNetworkClientStub) Watch(opts meta_v1.ListOptions) (watch.Interface, error) {
+  watch := watch.NewEmptyWatch()
+  return watch, nil
+}
+
+func (netClient TenantNetworkClientStub) List(opts meta_v1.ListOptions) (*danmtypes.TenantNetworkList, error) {
+  return &danmtypes.TenantNetworkList{Items: netClient.TestNets}, nil
+}
+
+func (netClient TenantNetworkClientStub) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *danmtypes.TenantNetwork, err error) {
+  return nil, nil
+}
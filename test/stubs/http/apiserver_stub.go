@@ -0,0 +1,286 @@
+package http
+
+import (
+  "encoding/json"
+  "errors"
+  "io/ioutil"
+  "net/http"
+  "net/http/httptest"
+  "sort"
+  "strconv"
+  "strings"
+  "sync"
+)
+
+var resourceKinds = map[string]string {
+  "pods": "Pod",
+  "events": "Event",
+  "configmaps": "ConfigMap",
+  "danmnets": "DanmNet",
+  "tenantnetworks": "TenantNetwork",
+  "clusternetworks": "ClusterNetwork",
+  "danmeps": "DanmEp",
+  "cnitemplates": "CniTemplate",
+  "tenantconfigs": "TenantConfig",
+}
+
+// ApiServerStub is an in-memory K8s API server storing the core, and DANM objects created through it
+// Objects are identified by their URL path, e.g. /apis/danm.k8s.io/v1/namespaces/default/danmeps/ep1, and every request is recorded
+type ApiServerStub struct {
+  Server *httptest.Server
+  lock sync.Mutex
+  objects map[string]map[string]interface{}
+  requests []ApiRequest
+  resourceVersion int
+}
+
+type ApiRequest struct {
+  Method string
+  Resource string
+  Name string
+}
+
+type apiPath struct {
+  prefix string
+  apiVersion string
+  namespace string
+  resource string
+  name string
+}
+
+func NewApiServerStub() *ApiServerStub {
+  stub := ApiServerStub{objects: make(map[string]map[string]interface{})}
+  stub.Server = httptest.NewServer(http.HandlerFunc(stub.serve))
+  return &stub
+}
+
+func (stub *ApiServerStub) Close() {
+  stub.Server.Close()
+}
+
+// WriteKubeconfig writes a kubeconfig file pointing to the stub
+func (stub *ApiServerStub) WriteKubeconfig(fileName string) error {
+  kubeconfig := "apiVersion: v1\nkind: Config\nclusters:\n- name: stub\n  cluster:\n    server: " + stub.Server.URL + "\n" +
+                "contexts:\n- name: stub\n  context:\n    cluster: stub\ncurrent-context: stub\n"
+  return ioutil.WriteFile(fileName, []byte(kubeconfig), 0600)
+}
+
+// AddObject stores the object under the given path, as if it was created through the API
+func (stub *ApiServerStub) AddObject(path string, obj interface{}) error {
+  parsedPath, err := parsePath(path)
+  if err != nil || parsedPath.name == "" {
+    return errors.New("invalid object path:" + path)
+  }
+  rawObj, err := json.Marshal(obj)
+  if err != nil {
+    return err
+  }
+  stub.lock.Lock()
+  defer stub.lock.Unlock()
+  _, err = stub.store(parsedPath, rawObj)
+  return err
+}
+
+// GetObject reads the object stored under the given path into obj
+func (stub *ApiServerStub) GetObject(path string, obj interface{}) error {
+  stub.lock.Lock()
+  defer stub.lock.Unlock()
+  storedObj, ok := stub.objects[path]
+  if !ok {
+    return errors.New("object:" + path + " does not exist")
+  }
+  rawObj, _ := json.Marshal(storedObj)
+  return json.Unmarshal(rawObj, obj)
+}
+
+// GetObjectNames returns the names of the stored objects of a resource type, in alphabetical order
+func (stub *ApiServerStub) GetObjectNames(resource string) []string {
+  stub.lock.Lock()
+  defer stub.lock.Unlock()
+  var names []string
+  for path := range stub.objects {
+    parsedPath, _ := parsePath(path)
+    if parsedPath.resource == resource {
+      names = append(names, parsedPath.name)
+    }
+  }
+  sort.Strings(names)
+  return names
+}
+
+// GetRequestedNames returns the names of the objects of the given resource types the requests with the given method were sent to, in the order of the requests
+// Requests sent to whole collections are not included
+func (stub *ApiServerStub) GetRequestedNames(method string, resources ...string) []string {
+  stub.lock.Lock()
+  defer stub.lock.Unlock()
+  isRequested := make(map[string]bool)
+  for _, resource := range resources {
+    isRequested[resource] = true
+  }
+  var names []string
+  for _, request := range stub.requests {
+    if request.Method == method && isRequested[request.Resource] && request.Name != "" {
+      names = append(names, request.Name)
+    }
+  }
+  return names
+}
+
+func (stub *ApiServerStub) ResetRequests() {
+  stub.lock.Lock()
+  defer stub.lock.Unlock()
+  stub.requests = nil
+}
+
+func (stub *ApiServerStub) serve(writer http.ResponseWriter, request *http.Request) {
+  parsedPath, err := parsePath(request.URL.Path)
+  if err != nil {
+    writeStatus(writer, http.StatusNotFound, "NotFound", err.Error())
+    return
+  }
+  stub.lock.Lock()
+  defer stub.lock.Unlock()
+  stub.requests = append(stub.requests, ApiRequest{Method: request.Method, Resource: parsedPath.resource, Name: parsedPath.name})
+  storedObj, exists := stub.objects[parsedPath.getKey()]
+  switch {
+  case request.Method == http.MethodGet && parsedPath.name == "":
+    writeObject(writer, http.StatusOK, stub.list(parsedPath))
+  case request.Method == http.MethodGet && exists:
+    writeObject(writer, http.StatusOK, storedObj)
+  case request.Method == http.MethodPost && parsedPath.name == "":
+    rawObj, _ := ioutil.ReadAll(request.Body)
+    var newObj map[string]interface{}
+    err = json.Unmarshal(rawObj, &newObj)
+    if err != nil {
+      writeStatus(writer, http.StatusBadRequest, "BadRequest", err.Error())
+      return
+    }
+    parsedPath.name = getName(newObj)
+    if _, exists = stub.objects[parsedPath.getKey()]; exists {
+      writeStatus(writer, http.StatusConflict, "AlreadyExists", parsedPath.resource + " " + parsedPath.name + " already exists")
+      return
+    }
+    createdObj, _ := stub.store(parsedPath, rawObj)
+    writeObject(writer, http.StatusCreated, createdObj)
+  case request.Method == http.MethodPut && exists:
+    rawObj, _ := ioutil.ReadAll(request.Body)
+    updatedObj, err := stub.store(parsedPath, rawObj)
+    if err != nil {
+      writeStatus(writer, http.StatusBadRequest, "BadRequest", err.Error())
+      return
+    }
+    writeObject(writer, http.StatusOK, updatedObj)
+  case request.Method == http.MethodDelete && exists:
+    delete(stub.objects, parsedPath.getKey())
+    writeStatus(writer, http.StatusOK, "", "")
+  default:
+    writeStatus(writer, http.StatusNotFound, "NotFound", parsedPath.resource + " " + parsedPath.name + " not found")
+  }
+}
+
+func (stub *ApiServerStub) store(parsedPath apiPath, rawObj []byte) (map[string]interface{}, error) {
+  var obj map[string]interface{}
+  err := json.Unmarshal(rawObj, &obj)
+  if err != nil {
+    return nil, err
+  }
+  stub.resourceVersion++
+  obj["apiVersion"] = parsedPath.apiVersion
+  obj["kind"] = resourceKinds[parsedPath.resource]
+  metadata, _ := obj["metadata"].(map[string]interface{})
+  if metadata == nil {
+    metadata = make(map[string]interface{})
+    obj["metadata"] = metadata
+  }
+  metadata["name"] = parsedPath.name
+  if parsedPath.namespace != "" {
+    metadata["namespace"] = parsedPath.namespace
+  }
+  metadata["resourceVersion"] = strconv.Itoa(stub.resourceVersion)
+  stub.objects[parsedPath.getKey()] = obj
+  return obj, nil
+}
+
+//Collections of namespaced resources can be listed in one, or in all namespaces
+func (stub *ApiServerStub) list(parsedPath apiPath) map[string]interface{} {
+  var paths []string
+  for path := range stub.objects {
+    objPath, _ := parsePath(path)
+    if objPath.prefix == parsedPath.prefix && objPath.resource == parsedPath.resource && (parsedPath.namespace == "" || objPath.namespace == parsedPath.namespace) {
+      paths = append(paths, path)
+    }
+  }
+  sort.Strings(paths)
+  items := make([]interface{}, 0, len(paths))
+  for _, path := range paths {
+    items = append(items, stub.objects[path])
+  }
+  return map[string]interface{} {
+    "apiVersion": parsedPath.apiVersion,
+    "kind": resourceKinds[parsedPath.resource] + "List",
+    "metadata": map[string]interface{}{"resourceVersion": strconv.Itoa(stub.resourceVersion)},
+    "items": items,
+  }
+}
+
+//Paths are either /api/v1/..., or /apis/<group>/<version>/..., optionally followed by namespaces/<namespace>/, then the resource, the name of the object, and its subresource
+//Subresources, e.g. status, are handled as the object itself
+func parsePath(path string) (apiPath, error) {
+  segments := strings.Split(strings.Trim(path, "/"), "/")
+  var parsedPath apiPath
+  if len(segments) >= 2 && segments[0] == "api" {
+    parsedPath.prefix, parsedPath.apiVersion = "/api/" + segments[1], segments[1]
+    segments = segments[2:]
+  } else if len(segments) >= 3 && segments[0] == "apis" {
+    parsedPath.prefix, parsedPath.apiVersion = "/apis/" + segments[1] + "/" + segments[2], segments[1] + "/" + segments[2]
+    segments = segments[3:]
+  } else {
+    return parsedPath, errors.New("unknown API path:" + path)
+  }
+  if len(segments) >= 3 && segments[0] == "namespaces" {
+    parsedPath.namespace = segments[1]
+    segments = segments[2:]
+  }
+  if len(segments) == 0 {
+    return parsedPath, errors.New("resource is missing from API path:" + path)
+  }
+  if _, ok := resourceKinds[segments[0]]; !ok {
+    return parsedPath, errors.New("unknown resource in API path:" + path)
+  }
+  parsedPath.resource = segments[0]
+  if len(segments) > 1 {
+    parsedPath.name = segments[1]
+  }
+  return parsedPath, nil
+}
+
+func (parsedPath apiPath) getKey() string {
+  key := parsedPath.prefix
+  if parsedPath.namespace != "" {
+    key += "/namespaces/" + parsedPath.namespace
+  }
+  return key + "/" + parsedPath.resource + "/" + parsedPath.name
+}
+
+func getName(obj map[string]interface{}) string {
+  metadata, _ := obj["metadata"].(map[string]interface{})
+  name, _ := metadata["name"].(string)
+  return name
+}
+
+func writeObject(writer http.ResponseWriter, statusCode int, obj interface{}) {
+  rawObj, _ := json.Marshal(obj)
+  writer.Header().Set("Content-Type", "application/json")
+  writer.WriteHeader(statusCode)
+  writer.Write(rawObj)
+}
+
+func writeStatus(writer http.ResponseWriter, statusCode int, reason, message string) {
+  status := map[string]interface{}{"apiVersion": "v1", "kind": "Status", "metadata": map[string]interface{}{}, "code": statusCode}
+  if reason == "" {
+    status["status"] = "Success"
+  } else {
+    status["status"], status["reason"], status["message"] = "Failure", reason, message
+  }
+  writeObject(writer, statusCode, status)
+}
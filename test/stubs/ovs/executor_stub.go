@@ -0,0 +1,24 @@
+package ovs
+
+import (
+  "errors"
+  "strings"
+)
+
+type ExecutorStub struct {
+  Commands []string
+  ErrorOnCommand string
+}
+
+func NewExecutorStub(errorOnCommand string) *ExecutorStub {
+  return &ExecutorStub{ErrorOnCommand: errorOnCommand}
+}
+
+func (executor *ExecutorStub) Vsctl(args ...string) error {
+  command := strings.Join(args, " ")
+  executor.Commands = append(executor.Commands, command)
+  if executor.ErrorOnCommand != "" && strings.Contains(command, executor.ErrorOnCommand) {
+    return errors.New("error reply was explicitly requested for command:" + command)
+  }
+  return nil
+}
@@ -29,6 +29,9 @@ type TestArtifacts struct {
   TestTconfs []danmtypes.TenantConfig
   ReservedVnis []ReservedVnisList
   ExhaustAllocs []int
+  TestTemplates []danmtypes.CniTemplate
+  TestTnets []danmtypes.TenantNetwork
+  TestCnets []danmtypes.ClusterNetwork
 }
 
 type ReservedIpsList struct {
@@ -135,6 +135,30 @@ var (
         "flannel": "flannel",
        },
     },
+    danmtypes.TenantConfig {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "rt-table-range-reserved"},TypeMeta: meta_v1.TypeMeta {Kind: "TenantConfig"},
+      RtTableRange: "200-300",
+    },
+    danmtypes.TenantConfig {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "rt-table-range-too-big"},TypeMeta: meta_v1.TypeMeta {Kind: "TenantConfig"},
+      RtTableRange: "1000-5001",
+    },
+    danmtypes.TenantConfig {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "rt-table-range-with-alloc"},TypeMeta: meta_v1.TypeMeta {Kind: "TenantConfig"},
+      RtTableRange: "100-200",
+      RtTableAlloc: utils.AllocFor5k,
+    },
+    danmtypes.TenantConfig {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "rt-table-alloc-without-range"},TypeMeta: meta_v1.TypeMeta {Kind: "TenantConfig"},
+      NetworkIds: map[string]string {
+        "flannel": "flannel",
+       },
+      RtTableAlloc: utils.AllocFor5k,
+    },
+    danmtypes.TenantConfig {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "rt-table-range"},TypeMeta: meta_v1.TypeMeta {Kind: "TenantConfig"},
+      RtTableRange: "100-200,300-400",
+    },
   }
 )
 
@@ -166,12 +190,20 @@ var validateTconfTcs = []struct {
   {"longNidWithDynamicNeType", "", "longnid-sriov", "", true, nil},
   {"okayNids", "", "shortnid", "", false, nil},
   {"noChangeInIfaces", "old-iface", "new-iface", v1beta1.Update, false, nil},
+  {"rtTableRangeWithReservedTable", "", "rt-table-range-reserved", "", true, nil},
+  {"rtTableRangeOverMaximum", "", "rt-table-range-too-big", "", true, nil},
+  {"rtTableRangeWithSetAlloc", "", "rt-table-range-with-alloc", v1beta1.Create, true, nil},
+  {"rtTableAllocWithoutRange", "", "rt-table-alloc-without-range", "", true, nil},
+  {"validRtTableRange", "", "rt-table-range", v1beta1.Create, false, rtTableAllocPatch},
 }
 
 var (
   expectedPatch = []admit.Patch {
     admit.Patch {Path: "/hostDevices"},
   }
+  rtTableAllocPatch = []admit.Patch {
+    admit.Patch {Path: "/rtTableAlloc"},
+  }
 )
 
 func TestValidateTenantConfig(t *testing.T) {
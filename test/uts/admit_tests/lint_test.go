@@ -0,0 +1,173 @@
+package admit_tests
+
+import (
+  "io/ioutil"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmfake "github.com/nokia/danm/crd/client/clientset/versioned/fake"
+  "github.com/nokia/danm/pkg/admit"
+  "k8s.io/api/admission/v1beta1"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+  lintTconf = `apiVersion: danm.k8s.io/v1
+kind: TenantConfig
+metadata:
+  name: tconf
+hostDevices:
+- name: ens4
+  vniType: vlan
+  vniRange: 100-101
+rtTableRange: 100-101
+`
+  lintUnrelated = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+`
+  lintTnetTemplate = `apiVersion: danm.k8s.io/v1
+kind: TenantNetwork
+metadata:
+  name: NAME
+  namespace: app
+spec:
+  NetworkID: NAME
+  NetworkType: ipvlan
+  Options:
+    cidr: 10.0.0.0/24
+`
+  lintDnets = `apiVersion: danm.k8s.io/v1
+kind: DanmNet
+metadata:
+  name: first
+spec:
+  NetworkID: first
+  Options:
+    host_device: ens3
+    rt_tables: 200
+---
+apiVersion: danm.k8s.io/v1
+kind: DanmNet
+metadata:
+  name: second
+spec:
+  NetworkID: second
+  Options:
+    host_device: ens3
+    rt_tables: 200
+---
+`
+  lintOverlappingDnets = `apiVersion: danm.k8s.io/v1
+kind: DanmNet
+metadata:
+  name: first
+spec:
+  NetworkID: first
+  Options:
+    host_device: ens3
+    vlan: 100
+    cidr: 10.0.0.0/24
+---
+apiVersion: danm.k8s.io/v1
+kind: DanmNet
+metadata:
+  name: second
+spec:
+  NetworkID: second
+  Options:
+    host_device: ens3
+    vlan: 100
+    cidr: 10.0.0.128/25
+`
+  lintInvalidCidr = `{"apiVersion": "danm.k8s.io/v1", "kind": "ClusterNetwork", "metadata": {"name": "invalid"}, "spec": {"NetworkID": "invalid", "Options": {"cidr": "10.0.0.0"}}}`
+  lintNoName = `apiVersion: danm.k8s.io/v1
+kind: DanmNet
+spec:
+  NetworkID: noname
+`
+)
+
+var lintTcs = []struct {
+  tcName string
+  files map[string]string
+  expectedResults map[string]bool
+}{
+  {"noDanmObjects", map[string]string{"cm.yaml": lintUnrelated, "README.md": "not a manifest"}, map[string]bool{}},
+  {"tenantNetworkWithoutTenantConfig", map[string]string{"tnet.yaml": tnetManifest("first")}, map[string]bool{"tnet.yaml#1": false}},
+  {"tenantNetworksWithTenantConfig", map[string]string{"tconf.yaml": lintTconf + "---\n" + lintUnrelated, "tnets/first.yaml": tnetManifest("first"), "tnets/second.yml": tnetManifest("second")},
+    map[string]bool{"tconf.yaml#1": true, "tnets/first.yaml#1": true, "tnets/second.yml#1": true}},
+  {"vniRangeExhausted", map[string]string{"tconf.yaml": lintTconf, "first.yaml": tnetManifest("first"), "second.yaml": tnetManifest("second"), "third.yaml": tnetManifest("third")},
+    map[string]bool{"tconf.yaml#1": true, "first.yaml#1": true, "second.yaml#1": true, "third.yaml#1": false}},
+  {"sharedRoutingTable", map[string]string{"dnets.yaml": lintDnets}, map[string]bool{"dnets.yaml#1": false, "dnets.yaml#2": false}},
+  {"overlappingSubnets", map[string]string{"dnets.yaml": lintOverlappingDnets}, map[string]bool{"dnets.yaml#1": false, "dnets.yaml#2": false}},
+  {"duplicatedNetwork", map[string]string{"dnets.yaml": lintDnets, "copy.yaml": lintDnets}, map[string]bool{"copy.yaml#1": false, "copy.yaml#2": false, "dnets.yaml#1": false, "dnets.yaml#2": false}},
+  {"invalidJsonNetwork", map[string]string{"cnet.json": lintInvalidCidr}, map[string]bool{"cnet.json#1": false}},
+  {"missingName", map[string]string{"noname.yaml": lintNoName}, map[string]bool{"noname.yaml#1": false}},
+}
+
+func TestLintManifests(t *testing.T) {
+  for _, tc := range lintTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      tmpDir, err := ioutil.TempDir("", "lint_test")
+      if err != nil {
+        t.Fatalf("Temporary directory could not be created:%v", err)
+      }
+      defer os.RemoveAll(tmpDir)
+      for fileName, content := range tc.files {
+        filePath := filepath.Join(tmpDir, fileName)
+        os.MkdirAll(filepath.Dir(filePath), 0700)
+        err = ioutil.WriteFile(filePath, []byte(content), 0600)
+        if err != nil {
+          t.Fatalf("Manifest file could not be written:%v", err)
+        }
+      }
+      manifests, err := admit.ReadManifests(tmpDir)
+      if err != nil {
+        t.Fatalf("Manifests could not be read:%v", err)
+      }
+      results := admit.LintManifests(manifests)
+      if len(results) != len(tc.expectedResults) {
+        t.Fatalf("Number of linted manifests:%d does not match with the expected:%d", len(results), len(tc.expectedResults))
+      }
+      for _, result := range results {
+        source, _ := filepath.Rel(tmpDir, result.Manifest.Source)
+        isValid, ok := tc.expectedResults[source]
+        if !ok {
+          t.Errorf("Manifest:%s was not expected to be linted", source)
+          continue
+        }
+        if isValid != (result.Err == nil) {
+          t.Errorf("Lint result:%v of manifest:%s does not match with expectation", result.Err, source)
+        }
+      }
+    })
+  }
+}
+
+func TestValidateNetworkManifest(t *testing.T) {
+  dnet := &danmtypes.DanmNet{
+    TypeMeta: meta_v1.TypeMeta{Kind: "DanmNet"},
+    ObjectMeta: meta_v1.ObjectMeta{Name: "valid"},
+    Spec: danmtypes.DanmNetSpec{NetworkID: "valid", Options: danmtypes.DanmNetOption{Cidr: "10.0.0.0/24"}},
+  }
+  err := admit.ValidateNetworkManifest(nil, dnet, v1beta1.Create, danmfake.NewSimpleClientset())
+  if err != nil {
+    t.Errorf("Valid network was rejected because:%v", err)
+  }
+  if dnet.Spec.Options.Alloc != "" || dnet.Spec.Options.Pool.Start != "" {
+    t.Errorf("Validated manifest was mutated")
+  }
+  dnet.Spec.Options.Cidr = "10.0.0.0"
+  err = admit.ValidateNetworkManifest(nil, dnet, v1beta1.Create, danmfake.NewSimpleClientset())
+  if err == nil {
+    t.Errorf("Network with invalid CIDR was accepted")
+  }
+}
+
+func tnetManifest(name string) string {
+  return strings.Replace(lintTnetTemplate, "NAME", name, -1)
+}
@@ -48,6 +48,9 @@ var validateNetworkTcs = []struct {
   {"Ipv4GwOutsideCidrDNet", "", "gw-outside-cidr", DnetType, "", nil, nil, true, nil, 0},
   {"Ipv4GwOutsideCidrTNet", "", "gw-outside-cidr", TnetType, "", nil, nil, true, nil, 0},
   {"Ipv4GwOutsideCidrCNet", "", "gw-outside-cidr", CnetType, "", nil, nil, true, nil, 0},
+  {"Ipv4InvalidRouteDstDNet", "", "invalid-route-dst", DnetType, "", nil, nil, true, nil, 0},
+  {"Ipv4RouteDstOfOtherFamilyCNet", "", "route-dst-other-family", CnetType, "", nil, nil, true, nil, 0},
+  {"Ipv4InvalidGwTNet", "", "invalid-route-gw", TnetType, "", nil, nil, true, nil, 0},
   {"Ipv6RouteWithoutCidrDNet", "", "no-net6", DnetType, "", nil, nil, true, nil, 0},
   {"Ipv6RouteWithoutCidrTNet", "", "no-net6", TnetType, "", nil, nil, true, nil, 0},
   {"Ipv6RouteWithoutCidrCNet", "", "no-net6", CnetType, "", nil, nil, true, nil, 0},
@@ -150,6 +153,82 @@ var validateNetworkTcs = []struct {
   {"Pool6CidrBiggerThanNet6", "", "pool6-cidr-outside-net6", DnetType, "", nil, nil, true, nil, 0},
   {"InvalidPool6StartAddress", "", "invalid-pool6-start", DnetType, "", nil, nil, true, nil, 0},
   {"Pool6StartAddressMatchesEnd", "", "pool6-end-equals-start", DnetType, "", nil, nil, true, nil, 0},
+  {"TooLongNidForBridgeDNet", "", "bridge-long-nid", DnetType, "", nil, nil, true, nil, 0},
+  {"TooLongNidForBridgeCNet", "", "bridge-long-nid", CnetType, "", nil, nil, true, nil, 0},
+  {"InvalidBridgeMacDNet", "", "bridge-invalid-mac", DnetType, "", nil, nil, true, nil, 0},
+  {"MulticastBridgeMacCNet", "", "bridge-mcast-mac", CnetType, "", nil, nil, true, nil, 0},
+  {"BridgeWithDevicePoolTNet", "", "bridge-with-dp", TnetType, "", nil, nil, true, nil, 0},
+  {"HairpinForIpvlanDNet", "", "ipvlan-with-hairpin", DnetType, "", nil, nil, true, nil, 0},
+  {"BridgeMacForMacvlanCNet", "", "macvlan-with-bridge-mac", CnetType, "", nil, nil, true, nil, 0},
+  {"BridgeWithVlanSuccessDNet", "", "bridge-with-vlan", DnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"BridgeWithVlanSuccessCNet", "", "bridge-with-vlan", CnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"RoutedWithDeviceDNet", "", "routed-with-device", DnetType, "", nil, nil, true, nil, 0},
+  {"RoutedWithVxlanCNet", "", "routed-with-vxlan", CnetType, "", nil, nil, true, nil, 0},
+  {"RoutedWithDevicePoolTNet", "", "routed-with-dp", TnetType, "", nil, nil, true, nil, 0},
+  {"RoutedSuccessDNet", "", "routed", DnetType, v1beta1.Create, nil, nil, false, v6Allocs, 0},
+  {"RoutedSuccessTNet", "", "routed", TnetType, v1beta1.Create, nidMappings, nil, false, v6Allocs, 0},
+  {"InvalidSpoofChkDNet", "", "sriov-invalid-spoofchk", DnetType, "", nil, nil, true, nil, 0},
+  {"InvalidTrustCNet", "", "sriov-invalid-trust", CnetType, "", nil, nil, true, nil, 0},
+  {"InvalidLinkStateDNet", "", "sriov-invalid-link-state", DnetType, "", nil, nil, true, nil, 0},
+  {"NegativeTxRateCNet", "", "sriov-negative-rate", CnetType, "", nil, nil, true, nil, 0},
+  {"MinTxRateOverMaxDNet", "", "sriov-min-over-max", DnetType, "", nil, nil, true, nil, 0},
+  {"VlanQosOutOfRangeDNet", "", "sriov-qos-out-of-range", DnetType, "", nil, nil, true, nil, 0},
+  {"VlanQosWithoutVlanCNet", "", "sriov-qos-without-vlan", CnetType, "", nil, nil, true, nil, 0},
+  {"TrustForMacvlanDNet", "", "macvlan-with-trust", DnetType, "", nil, nil, true, nil, 0},
+  {"SriovVfOptionsSuccessDNet", "", "sriov-vf-options", DnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"SriovVfOptionsSuccessCNet", "", "sriov-vf-options", CnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"UppercaseSriovVfOptionsSuccessDNet", "", "sriov-uppercase-vf-options", DnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"ConfigMapForDynamicBackendDNet", "", "ipvlan-with-cm", DnetType, "", nil, nil, true, nil, 0},
+  {"ConfigMapInvalidFormatDNet", "", "static-invalid-cm", DnetType, "", nil, nil, true, nil, 0},
+  {"ConfigMapWithoutNamespaceCNet", "", "static-cm-without-ns", CnetType, "", nil, nil, true, nil, 0},
+  {"ConfigMapWithoutNamespaceDNet", "", "static-cm-without-ns", DnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"ConfigMapWithNamespaceCNet", "", "static-cm-with-ns", CnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"HostDeviceWithoutDevicePoolDNet", "", "host-device-without-dp", DnetType, "", nil, nil, true, nil, 0},
+  {"HostDeviceWithHostDeviceCNet", "", "host-device-with-device", CnetType, "", nil, nil, true, nil, 0},
+  {"HostDeviceSuccessDNet", "", "host-device", DnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"OvsWithoutBridgeDNet", "", "ovs-without-bridge", DnetType, "", nil, nil, true, nil, 0},
+  {"OvsWithVxlanCNet", "", "ovs-with-vxlan", CnetType, "", nil, nil, true, nil, 0},
+  {"OvsDpdkSuccessDNet", "", "ovs-dpdk", DnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"OvsDpdkSuccessCNet", "", "ovs-dpdk", CnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"MacFromIpForIpvlanDNet", "", "ipvlan-mac-from-ip", DnetType, "", nil, nil, true, nil, 0},
+  {"MacFromIpWithoutSubnetDNet", "", "macvlan-mac-from-ip-without-subnet", DnetType, "", nil, nil, true, nil, 0},
+  {"MacFromIpSuccessCNet", "", "macvlan-mac-from-ip", CnetType, v1beta1.Create, nil, nil, false, v6Allocs, 0},
+  {"DisallowedSysctlDNet", "", "sysctl-not-allowed", DnetType, "", nil, nil, true, nil, 0},
+  {"InvalidSysctlValueDNet", "", "sysctl-invalid-value", DnetType, "", nil, nil, true, nil, 0},
+  {"SysctlsSuccessCNet", "", "sysctls", CnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"BandwidthWithoutBurstDNet", "", "bandwidth-without-burst", DnetType, "", nil, nil, true, nil, 0},
+  {"BandwidthSuccessDNet", "", "bandwidth", DnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"IpRouteWithoutCidrDNet", "", "ip-route-without-cidr", DnetType, "", nil, nil, true, nil, 0},
+  {"IpRouteInvalidDestinationDNet", "", "ip-route-invalid-dst", DnetType, "", nil, nil, true, nil, 0},
+  {"IpRouteGwOutsideCidrDNet", "", "ip-route-gw-outside-cidr", DnetType, "", nil, nil, true, nil, 0},
+  {"IpRouteGwOfOtherFamilyDNet", "", "ip-route-gw-other-family", DnetType, "", nil, nil, true, nil, 0},
+  {"IpRouteInvalidWeightDNet", "", "ip-route-invalid-weight", DnetType, "", nil, nil, true, nil, 0},
+  {"IpRouteNegativeWeightDNet", "", "ip-route-negative-weight", DnetType, "", nil, nil, true, nil, 0},
+  {"IpRouteLinkScopeWithGwDNet", "", "ip-route-link-scope-with-gw", DnetType, "", nil, nil, true, nil, 0},
+  {"IpRouteOnlinkWithoutGwDNet", "", "ip-route-onlink-without-gw", DnetType, "", nil, nil, true, nil, 0},
+  {"IpRoutesSuccessCNet", "", "ip-routes", CnetType, v1beta1.Create, nil, nil, false, v4Allocs, 0},
+  {"ReservedRtTableDNet", "", "rt-table-reserved", DnetType, "", nil, nil, true, nil, 0},
+  {"RtTableCollisionDNet", "", "rt-table-taken", DnetType, v1beta1.Create, nil, nil, true, nil, 0},
+  {"UnchangedRtTableCollisionDNet", "rt-table-taken", "rt-table-taken", DnetType, v1beta1.Update, nil, nil, false, nil, 0},
+  {"UniqueRtTableDNet", "", "rt-table-unique", DnetType, v1beta1.Create, rtTableRange, nil, false, nil, 0},
+  {"RtTableAllocatedDNet", "", "rt-table-auto", DnetType, v1beta1.Create, rtTableRange, nil, false, onlyRtTables, 1},
+  {"RtTableNotAllocatedWithoutRangeDNet", "", "rt-table-auto", DnetType, v1beta1.Create, twoDevs, nil, false, nil, 0},
+  {"VniFreedWhenRtTableCannotBeAllocatedTNet", "", "tnet-random", TnetType, v1beta1.Create, exhaustedRtTables, nil, true, nil, 2},
+  {"OverlappingCidrInL2DomainDNet", "", "l2-overlap", DnetType, v1beta1.Create, nil, nil, true, nil, 0},
+  {"OverlappingNet6InL2DomainDNet", "", "l2-overlap6", DnetType, v1beta1.Create, nil, nil, true, nil, 0},
+  {"UnchangedOverlappingCidrDNet", "l2-overlap", "l2-overlap", DnetType, v1beta1.Update, nil, nil, false, v4Allocs, 0},
+  {"OverlappingCidrInOtherNamespaceDNet", "", "l2-overlap-other-ns", DnetType, v1beta1.Create, nil, nil, false, v4Allocs, 0},
+  {"OverlappingCidrOfClusterNetworkCNet", "", "l2-overlap", CnetType, v1beta1.Create, nil, nil, false, v4Allocs, 0},
+  {"DisjointCidrInL2DomainDNet", "", "l2-disjoint", DnetType, v1beta1.Create, nil, nil, false, v4Allocs, 0},
+  {"OverlappingCidrInOtherL2DomainDNet", "", "l2-other-vlan", DnetType, v1beta1.Create, nil, nil, false, v4Allocs, 0},
+  {"SharedHostLinkInOtherL2DomainDNet", "", "l2-nid-collision", DnetType, v1beta1.Create, nil, nil, true, nil, 0},
+  {"SharedHostLinkInOtherL2DomainCNet", "", "l2-nid-collision", CnetType, v1beta1.Create, nil, nil, true, nil, 0},
+  {"SharedHostLinkInSameL2DomainDNet", "", "l2-nid-shared", DnetType, v1beta1.Create, nil, nil, false, v4Allocs, 0},
+  {"SharedBridgeInOtherL2DomainDNet", "", "l2-bridge-collision", DnetType, v1beta1.Create, nil, nil, true, nil, 0},
+  {"TemplatedDeviceBasedWithoutDevicePoolDNet", "", "template-device-without-dp", DnetType, "", nil, nil, true, nil, 0},
+  {"TemplatedDeviceBasedSuccessCNet", "", "template-device", CnetType, v1beta1.Create, nil, nil, false, nil, 0},
+  {"TemplatedWithCniConfigMapDNet", "", "template-with-cm", DnetType, "", nil, nil, true, nil, 0},
+  {"TemplatedDynamicTnetSuccess", "", "template-tnet", TnetType, v1beta1.Create, randomDev, nil, false, allocAndVxlanAndDevice, 1},
 }
 
 var (
@@ -177,6 +256,18 @@ var (
       ObjectMeta: meta_v1.ObjectMeta {Name: "gw-outside-cidr"},
       Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Cidr: "10.20.1.0/24", Routes: map[string]string{"10.20.20.0/24": "10.20.1.1", "10.20.30.0/24": "10.20.0.1"}}},
     },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "invalid-route-dst"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Cidr: "10.20.1.0/24", Routes: map[string]string{"10.20.20.0/a4": "10.20.1.1"}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "route-dst-other-family"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Cidr: "10.20.1.0/24", Routes: map[string]string{"2a00:8a00:a000:1193::/64": "10.20.1.1"}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "invalid-route-gw"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Cidr: "10.20.1.0/24", Routes: map[string]string{"10.20.20.0/24": "10.20.1.a"}}},
+    },
     danmtypes.DanmNet {
       ObjectMeta: meta_v1.ObjectMeta {Name: "no-net6"},
       Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Routes6: map[string]string{"2a00:8a00:a000:1193::/64": "2a00:8a00:a000:1192::"}}},
@@ -307,23 +398,23 @@ var (
     },
     danmtypes.DanmNet {
       ObjectMeta: meta_v1.ObjectMeta {Name: "vniOld", Namespace: "vni-test"},
-      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Device: "ens4", Vlan: 50}},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "vnichange", Options: danmtypes.DanmNetOption{Device: "ens4", Vlan: 50}},
     },
     danmtypes.DanmNet {
       ObjectMeta: meta_v1.ObjectMeta {Name: "vniNew", Namespace: "vni-test"},
-      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Device: "ens4", Vlan: 51}},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "vnichange", Options: danmtypes.DanmNetOption{Device: "ens4", Vlan: 51}},
     },
     danmtypes.DanmNet {
       ObjectMeta: meta_v1.ObjectMeta {Name: "vxlanOld", Namespace: "vni-test"},
-      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Device: "ens4", Vxlan: 50}},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "vnichange", Options: danmtypes.DanmNetOption{Device: "ens4", Vxlan: 50}},
     },
     danmtypes.DanmNet {
       ObjectMeta: meta_v1.ObjectMeta {Name: "vxlanNew", Namespace: "vni-test"},
-      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Device: "ens4", Vxlan: 51}},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "vnichange", Options: danmtypes.DanmNetOption{Device: "ens4", Vxlan: 51}},
     },
     danmtypes.DanmNet {
       ObjectMeta: meta_v1.ObjectMeta {Name: "deviceNew", Namespace: "vni-test"},
-      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Device: "ens5", Vlan: 50}},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "vnichange", Options: danmtypes.DanmNetOption{Device: "ens5", Vlan: 50}},
     },
     danmtypes.DanmNet {
       ObjectMeta: meta_v1.ObjectMeta {Name: "nidNew", Namespace: "vni-test"},
@@ -373,6 +464,295 @@ var (
       ObjectMeta: meta_v1.ObjectMeta {Name: "pool6-end-equals-start"},
       Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Net6: "2001:db8:85a3::8a2e:370:7334/108", Pool6: danmtypes.IpPoolV6{Cidr: "2001:db8:85a3::8a2e:370:7334/109", IpPool: danmtypes.IpPool{Start: "2001:db8:85a3::8a2e:370:7340", End: "2001:db8:85a3::8a2e:370:7340"}}}},
     },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "bridge-long-nid"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "abcdeftgasdf"},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "bridge-invalid-mac"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{BridgeMac: "02:00:00:00:00:zz"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "bridge-mcast-mac"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{BridgeMac: "01:00:5e:00:00:01"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "bridge-with-dp"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/sriov_ens1f0"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ipvlan-with-hairpin"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Device: "ens3", Hairpin: true}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "macvlan-with-bridge-mac"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "macvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Device: "ens3", BridgeMac: "02:00:00:00:00:01"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "bridge-with-vlan"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "brvlan", Options: danmtypes.DanmNetOption{Device: "ens3", Vlan: 50, BridgeMac: "02:00:00:00:00:01", Hairpin: true}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "routed-with-device"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "routed", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Device: "ens3"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "routed-with-vxlan"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "routed", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Vxlan: 50}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "routed-with-dp"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "routed", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/sriov_ens1f0"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "routed"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "routed", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Net6: "2001:db8:85a3::8a2e:370:7334/120"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sriov-invalid-spoofchk"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "sriov", NetworkID: "e2", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/sriov_ens1f0", SpoofChk: "true"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sriov-invalid-trust"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "sriov", NetworkID: "e2", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/sriov_ens1f0", Trust: "yes"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sriov-invalid-link-state"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "sriov", NetworkID: "e2", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/sriov_ens1f0", LinkState: "up"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sriov-negative-rate"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "sriov", NetworkID: "e2", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/sriov_ens1f0", MinTxRate: -100}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sriov-min-over-max"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "sriov", NetworkID: "e2", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/sriov_ens1f0", MinTxRate: 1000, MaxTxRate: 100}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sriov-qos-out-of-range"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "sriov", NetworkID: "e2", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/sriov_ens1f0", Vlan: 500, VlanQos: 8}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sriov-qos-without-vlan"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "sriov", NetworkID: "e2", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/sriov_ens1f0", VlanQos: 3}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "macvlan-with-trust"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "macvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Device: "ens3", Trust: "on"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ipvlan-with-cm"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "nanomsg", Options: danmtypes.DanmNetOption{Device: "ens3", CniConfigMap: "cni-configs"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "static-invalid-cm"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "flannel", NetworkID: "flannel", Options: danmtypes.DanmNetOption{CniConfigMap: "kube-system/cni/configs"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "static-cm-without-ns"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "flannel", NetworkID: "flannel", Options: danmtypes.DanmNetOption{CniConfigMap: "cni-configs"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "static-cm-with-ns"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "flannel", NetworkID: "flannel", Options: danmtypes.DanmNetOption{CniConfigMap: "kube-system/cni-configs"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "host-device-without-dp"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "host-device", NetworkID: "hd"},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "host-device-with-device"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "host-device", NetworkID: "hd", Options: danmtypes.DanmNetOption{Device: "ens3", DevicePool: "nokia.k8s.io/nics"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "host-device"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "host-device", NetworkID: "hd", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/nics"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ovs-without-bridge"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ovs", NetworkID: "ovs", Options: danmtypes.DanmNetOption{Vlan: 200}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ovs-with-vxlan"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ovs", NetworkID: "ovs", Options: danmtypes.DanmNetOption{Device: "br-int", Vxlan: 200}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ovs-dpdk"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ovs", NetworkID: "ovs", Options: danmtypes.DanmNetOption{Device: "br-dpdk", DevicePool: "nokia.k8s.io/vhostuser", Vlan: 200}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ipvlan-mac-from-ip"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Cidr: "10.0.0.0/24", MacFromIp: true}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "macvlan-mac-from-ip-without-subnet"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "macvlan", NetworkID: "macvl", Options: danmtypes.DanmNetOption{Device: "ens3", MacFromIp: true}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "macvlan-mac-from-ip"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "macvlan", NetworkID: "macvl", Options: danmtypes.DanmNetOption{Device: "ens3", Net6: "2001:db8:85a3::8a2e:370:7334/120", MacFromIp: true}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sysctl-not-allowed"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Sysctls: map[string]string{"ipv4.rp_filter": "2", "ipv4.tcp_syncookies": "1"}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sysctl-invalid-value"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Sysctls: map[string]string{"ipv4.arp_ignore": "yes"}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sysctls"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Sysctls: map[string]string{"ipv4.rp_filter": "2", "ipv4.arp_ignore": "1", "ipv6.accept_ra": "0"}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "bandwidth-without-burst"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Bandwidth: danmtypes.BandwidthLimits{IngressRate: 100000000}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "bandwidth"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Bandwidth: danmtypes.BandwidthLimits{IngressRate: 100000000, IngressBurst: 1000000, EgressRate: 50000000, EgressBurst: 500000}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ip-route-without-cidr"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", IpRoutes: []danmtypes.IpRoute{{Destination: "10.30.0.0/24", Gateways: []danmtypes.RouteNextHop{{Gateway: "10.20.1.1"}}}}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ip-route-invalid-dst"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Cidr: "10.20.1.0/24", IpRoutes: []danmtypes.IpRoute{{Destination: "10.30.0.0/a4", Gateways: []danmtypes.RouteNextHop{{Gateway: "10.20.1.1"}}}}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ip-route-gw-outside-cidr"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Cidr: "10.20.1.0/24", IpRoutes: []danmtypes.IpRoute{{Destination: "10.30.0.0/24", Gateways: []danmtypes.RouteNextHop{{Gateway: "10.20.1.1"}, {Gateway: "10.20.2.1"}}}}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ip-route-gw-other-family"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Net6: "2a00:8a00:a000:1193::/64", IpRoutes: []danmtypes.IpRoute{{Destination: "2a00:8a00:a000:1194::/64", Gateways: []danmtypes.RouteNextHop{{Gateway: "10.20.1.1"}}, Onlink: true}}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ip-route-invalid-weight"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Cidr: "10.20.1.0/24", IpRoutes: []danmtypes.IpRoute{{Destination: "10.30.0.0/24", Gateways: []danmtypes.RouteNextHop{{Gateway: "10.20.1.1", Weight: 300}}}}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ip-route-negative-weight"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Cidr: "10.20.1.0/24", IpRoutes: []danmtypes.IpRoute{{Destination: "10.30.0.0/24", Gateways: []danmtypes.RouteNextHop{{Gateway: "10.20.1.1", Weight: -1}}}}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ip-route-link-scope-with-gw"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Cidr: "10.20.1.0/24", IpRoutes: []danmtypes.IpRoute{{Destination: "10.30.0.0/24", Gateways: []danmtypes.RouteNextHop{{Gateway: "10.20.1.1"}}, Scope: "link"}}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ip-route-onlink-without-gw"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Cidr: "10.20.1.0/24", IpRoutes: []danmtypes.IpRoute{{Destination: "10.30.0.0/24", Onlink: true}}}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "ip-routes"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", Cidr: "10.20.1.0/24", IpRoutes: []danmtypes.IpRoute{
+        {Destination: "10.30.0.0/16", Gateways: []danmtypes.RouteNextHop{{Gateway: "10.20.1.1", Weight: 2}, {Gateway: "10.20.1.2", Weight: 1}}, Metric: 100, Source: "10.20.1.10", Mtu: 1400},
+        {Destination: "10.40.0.0/16", Gateways: []danmtypes.RouteNextHop{{Gateway: "192.168.0.1"}}, Onlink: true},
+        {Destination: "10.50.0.0/16", Scope: "link"},
+      }}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "rt-table-owner"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", RTables: 201}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "rt-table-taken"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", RTables: 201}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "rt-table-reserved"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", RTables: 254}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "rt-table-unique"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3", RTables: 210}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "rt-table-auto"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "ipvl", Options: danmtypes.DanmNetOption{Device: "ens3"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sriov-vf-options"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "sriov", NetworkID: "e2", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/sriov_ens1f0", Vlan: 500, VlanQos: 3, SpoofChk: "off", Trust: "on", MinTxRate: 100, MaxTxRate: 1000, LinkState: "enable"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "sriov-uppercase-vf-options"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "SRIOV", NetworkID: "e2", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/sriov_ens1f0", Vlan: 500, SpoofChk: "off", Trust: "on"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "template-device-without-dp"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "vfio", NetworkID: "vfio", Options: danmtypes.DanmNetOption{Device: "ens3"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "template-device"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "vfio", NetworkID: "vfio", Options: danmtypes.DanmNetOption{DevicePool: "nokia.k8s.io/vfio"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "template-with-cm"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "vlan", NetworkID: "vlan", Options: danmtypes.DanmNetOption{CniConfigMap: "kube-system/cni-configs"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "template-tnet"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "vlan", NetworkID: "tmpl", Options: danmtypes.DanmNetOption{Pool: danmtypes.IpPool{Start: "192.168.1.65",End: "192.168.1.126"}, Cidr: "192.168.1.64/26"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "l2-existing", Namespace: "l2-test"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "l2ex", Options: danmtypes.DanmNetOption{Device: "ens6", Vlan: 70, Cidr: "10.20.0.0/24", Net6: "2a00:8a00:a000:1200::/64"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "l2-existing-vxlan", Namespace: "l2-test"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "l2vx", Options: danmtypes.DanmNetOption{Device: "ens6", Vxlan: 80}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "l2-existing-bridge", Namespace: "l2-test"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "l2br"},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "l2-overlap", Namespace: "l2-test"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "l2new", Options: danmtypes.DanmNetOption{Device: "ens6", Vlan: 70, Cidr: "10.20.0.128/25"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "l2-overlap6", Namespace: "l2-test"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "l2new", Options: danmtypes.DanmNetOption{Device: "ens6", Vlan: 70, Net6: "2a00:8a00:a000:1200::/56"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "l2-overlap-other-ns", Namespace: "l2-other"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "l2new", Options: danmtypes.DanmNetOption{Device: "ens6", Vlan: 70, Cidr: "10.20.0.0/24"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "l2-disjoint", Namespace: "l2-test"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "l2new", Options: danmtypes.DanmNetOption{Device: "ens6", Vlan: 70, Cidr: "10.20.1.0/24"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "l2-other-vlan", Namespace: "l2-test"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "l2new", Options: danmtypes.DanmNetOption{Device: "ens6", Vlan: 71, Cidr: "10.20.0.0/24"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "l2-nid-collision", Namespace: "l2-test"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", NetworkID: "l2vx", Options: danmtypes.DanmNetOption{Device: "ens7", Vxlan: 80}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "l2-nid-shared", Namespace: "l2-test"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "macvlan", NetworkID: "l2ex", Options: danmtypes.DanmNetOption{Device: "ens6", Vlan: 70, Cidr: "10.20.2.0/24"}},
+    },
+    danmtypes.DanmNet {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "l2-bridge-collision", Namespace: "l2-test"},
+      Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "l2br", Options: danmtypes.DanmNetOption{Device: "ens6", Vlan: 72}},
+    },
+  }
+)
+
+var (
+  valTemplates = []danmtypes.CniTemplate {
+    danmtypes.CniTemplate {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "vlan"},
+      Spec: danmtypes.CniTemplateSpec{IpamNeeded: true, Template: `{"cniVersion":"{{.CniVersion}}","name":"{{.NetworkID}}","type":"vlan","master":"{{.Device}}","vlanId":{{.Vlan}},"ipam":{{.Ipam}}}`},
+    },
+    danmtypes.CniTemplate {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "vfio"},
+      Spec: danmtypes.CniTemplateSpec{DeviceNeeded: true, Template: `{"cniVersion":"{{.CniVersion}}","name":"{{.NetworkID}}","type":"vfio","deviceID":"{{.DeviceID}}"}`},
+    },
   }
 )
 
@@ -406,6 +786,13 @@ var (
     admit.Patch {Path: "/spec/Options/host_device"},
     admit.Patch {Path: "/spec/Options/vxlan"},
   }
+  onlyRtTables = []admit.Patch {
+    admit.Patch {Path: "/spec/Options/rt_tables"},
+  }
+  v4Allocs = []admit.Patch {
+    admit.Patch {Path: "/spec/Options/alloc"},
+    admit.Patch {Path: "/spec/Options/allocation_pool"},
+  }
   v6Allocs = []admit.Patch {
     admit.Patch {Path: "/spec/Options/alloc6"},
     admit.Patch {Path: "/spec/Options/allocation_pool_v6"},
@@ -419,6 +806,23 @@ var (
 )
 
 var (
+  rtTableRange = []danmtypes.TenantConfig {
+    danmtypes.TenantConfig {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "tconf"},TypeMeta: meta_v1.TypeMeta {Kind: "TenantConfig"},
+      RtTableRange: "201-203",
+      RtTableAlloc: utils.AllocFor5k,
+    },
+  }
+  exhaustedRtTables = []danmtypes.TenantConfig {
+    danmtypes.TenantConfig {
+      ObjectMeta: meta_v1.ObjectMeta {Name: "tconf"},TypeMeta: meta_v1.TypeMeta {Kind: "TenantConfig"},
+      HostDevices: []danmtypes.IfaceProfile {
+        danmtypes.IfaceProfile{Name: "ens4", VniType: "vxlan", VniRange: "900-4999,5000", Alloc: utils.AllocFor5k},
+       },
+      RtTableRange: "201-203",
+      RtTableAlloc: utils.ExhaustedAllocFor5k,
+    },
+  }
   oneDev = []danmtypes.TenantConfig {
     danmtypes.TenantConfig {
       ObjectMeta: meta_v1.ObjectMeta {Name: "tconf"},TypeMeta: meta_v1.TypeMeta {Kind: "TenantConfig"},
@@ -541,7 +945,7 @@ func TestValidateNetwork(t *testing.T) {
         t.Errorf("Could not create test HTTP Request object, because:%v", err)
         return
       }
-      testArtifacts := utils.TestArtifacts{TestNets: valNets, TestEps: tc.eps}
+      testArtifacts := utils.TestArtifacts{TestNets: valNets, TestEps: tc.eps, TestTemplates: valTemplates}
       if tc.tconf != nil {
         testArtifacts.TestTconfs = tc.tconf
       }
@@ -1,15 +1,20 @@
 package cnidel_test
 
 import (
+  "context"
   "os"
   "strings"
   "testing"
   "io/ioutil"
   "path/filepath"
   sriov_utils "github.com/intel/sriov-cni/pkg/utils"
+  corev1 "k8s.io/api/core/v1"
+  "k8s.io/client-go/kubernetes"
+  "k8s.io/client-go/kubernetes/fake"
   danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
   "github.com/nokia/danm/pkg/cnidel"
   "github.com/nokia/danm/pkg/datastructs"
+  stubs "github.com/nokia/danm/test/stubs/danm"
   "github.com/nokia/danm/test/utils"
   meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -23,7 +28,8 @@ var (
   cniTesterDir = cniTestConfigDir
   defaultDataDir = "/var/lib/cni/networks"
   flannelBridge = "cbr0"
-  cniConf = datastructs.NetConf{CniConfigDir: "/etc/cni/net.d"}
+  cniConfigCacheDir = "/tmp/danm-cniconf-cache"
+  cniConf = datastructs.NetConf{CniConfigDir: "/etc/cni/net.d", CniConfigCacheDir: cniConfigCacheDir}
 )
 
 type CniConf struct {
@@ -44,6 +50,14 @@ var testNets = []danmtypes.DanmNet {
     ObjectMeta: meta_v1.ObjectMeta {Name: "IPVLAN-UPPER"},
     Spec: danmtypes.DanmNetSpec{NetworkID: "IPVLAN-UPPER", NetworkType: "IPVLAN"},
   },
+  danmtypes.DanmNet{
+    ObjectMeta: meta_v1.ObjectMeta {Name: "bridge"},
+    Spec: danmtypes.DanmNetSpec{NetworkID: "bridge", NetworkType: "bridge"},
+  },
+  danmtypes.DanmNet{
+    ObjectMeta: meta_v1.ObjectMeta {Name: "routed"},
+    Spec: danmtypes.DanmNetSpec{NetworkID: "routed", NetworkType: "routed"},
+  },
   danmtypes.DanmNet{
     ObjectMeta: meta_v1.ObjectMeta {Name: "sriov"},
     Spec: danmtypes.DanmNetSpec{NetworkID: "sriov", NetworkType: "sriov"},
@@ -88,6 +102,14 @@ var testNets = []danmtypes.DanmNet {
     ObjectMeta: meta_v1.ObjectMeta {Name: "sriov-test"},
     Spec: danmtypes.DanmNetSpec{NetworkType: "sriov", NetworkID: "sriov-test", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26", Vlan: 500}},
   },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "host-device-test"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "host-device", NetworkID: "hd_net", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26"}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "sriov-vf-options"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "sriov", NetworkID: "sriov-test", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26", Vlan: 500, VlanQos: 3, SpoofChk: "off", Trust: "on", MinTxRate: 100, MaxTxRate: 1000, LinkState: "enable"}},
+  },
   danmtypes.DanmNet {
     ObjectMeta: meta_v1.ObjectMeta {Name: "full-macvlan"},
     Spec: danmtypes.DanmNetSpec{NetworkType: "macvlan", NetworkID: "full", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26", Device: "ens1f0"}},
@@ -120,12 +142,74 @@ var testNets = []danmtypes.DanmNet {
     ObjectMeta: meta_v1.ObjectMeta {Name: "bridge-ipam-ds"},
     Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "bridge_l3", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26", Net6: "2a00:8a00:a000:1193::/64"}},
   },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "vlan-template"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "vlan", NetworkID: "vlan_net", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26", Device: "ens1f0", Vlan: 200}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "invalid-json-template"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "invalidjson", NetworkID: "vlan_net", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26"}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "invalid-placeholder-template"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "invalidplaceholder", NetworkID: "vlan_net", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26"}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "error-template"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "errortemplate", NetworkID: "vlan_net", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26"}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "configmap-static", Namespace: "default"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "ptp", NetworkID: "ptp_net", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26", CniConfigMap: "cni-configs"}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "configmap-missing-key", Namespace: "default"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "ptp", NetworkID: "nokey", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26", CniConfigMap: "default/cni-configs"}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "configmap-without-ns"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "ptp", NetworkID: "ptp_net", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26", CniConfigMap: "cni-configs"}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "configmap-uncached", Namespace: "default"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "ptp", NetworkID: "ptp_net", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26", CniConfigMap: "other-configs"}},
+  },
   danmtypes.DanmNet {
     ObjectMeta: meta_v1.ObjectMeta {Name: "full-bridge"},
     Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "bridge_l2", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26"}},
   },
 }
 
+var testTemplates = []danmtypes.CniTemplate {
+  danmtypes.CniTemplate {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "vlan"},
+    Spec: danmtypes.CniTemplateSpec{IpamNeeded: true, Template: `{"cniVersion":"{{.CniVersion}}","name":"{{.NetworkID}}","type":"vlan","master":"{{.Device}}","vlanId":{{.Vlan}},"mtu":{{.Mtu}},"ipam":{{.Ipam}}}`},
+  },
+  danmtypes.CniTemplate {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "vfio"},
+    Spec: danmtypes.CniTemplateSpec{IpamNeeded: true, DeviceNeeded: true, Template: `{"cniVersion":"{{.CniVersion}}","name":"{{.NetworkID}}","type":"vfio","deviceID":"{{.DeviceID}}"}`},
+  },
+  danmtypes.CniTemplate {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "invalidjson"},
+    Spec: danmtypes.CniTemplateSpec{IpamNeeded: true, Template: `{"cniVersion":"{{.CniVersion}}","name":{{.NetworkID}}}`},
+  },
+  danmtypes.CniTemplate {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "invalidplaceholder"},
+    Spec: danmtypes.CniTemplateSpec{IpamNeeded: true, Template: `{"cniVersion":"{{.CniVersion}}","name":"{{.NetworkName}}","type":"vlan","master":"{{.HostDevice}}"}`},
+  },
+}
+
+var testConfigMaps = []corev1.ConfigMap {
+  corev1.ConfigMap {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "cni-configs", Namespace: "default"},
+    Data: map[string]string{"ptp_net.conf": `{"cniVersion":"0.3.1","name":"ptp_net","type":"ptp","ipMasq":true}`},
+  },
+  corev1.ConfigMap {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "other-configs", Namespace: "default"},
+    Data: map[string]string{"ptp_net.conf": `{"cniVersion":"0.3.1","name":"ptp_net","type":"ptp","ipMasq":false}`},
+  },
+}
+
 var expectedCniConfigs = []CniConf {
   {"flannel", []byte(`{"cniexp":{"cnitype":"flannel"},"cniconf":{"cniVersion":"0.3.1","name":"cbr0","type":"flannel","delegate":{"hairpinMode":true,"isDefaultGateway":true}}}`)},
   {"flannel-ip", []byte(`{"cniexp":{"cnitype":"flannel","ip":"10.244.10.30/24","env":{"CNI_COMMAND":"ADD","CNI_IFNAME":"eth0"}},"cniconf":{"cniVersion":"0.3.1","name":"cbr0","type":"flannel","delegate":{"hairpinMode":true,"isDefaultGateway":true}}}`)},
@@ -135,7 +219,15 @@ var expectedCniConfigs = []CniConf {
   {"macvlan-ip4-type020", []byte(`{"cniexp":{"cnitype":"macvlan","ip":"192.168.1.65/26","env":{"CNI_COMMAND":"ADD","CNI_IFNAME":"ens1f0"},"return":"020"},"cniconf":{"cniVersion":"0.3.1","name":"macvlan-v4","master":"ens1f0","mode":"bridge","mtu":1500,"ipam":{"type":"fakeipam","ips":[{"ipcidr":"192.168.1.65/26","version":4}]}}}`)},
   {"macvlan-ip6-type020", []byte(`{"cniexp":{"cnitype":"macvlan","ip6":"2a00:8a00:a000:1193::/64","env":{"CNI_COMMAND":"ADD","CNI_IFNAME":"ens1f1"},"return":"020"},"cniconf":{"cniVersion":"0.3.1","name":"macvlan-v6","master":"ens1f1","mode":"bridge","mtu":1500,"ipam":{"type":"fakeipam"}}}`)},
   {"sriov-l3", []byte(`{"cniexp":{"cnitype":"sriov","ip":"192.168.1.65/26","env":{"CNI_COMMAND":"ADD","CNI_IFNAME":"eth0"}},"cniconf":{"cniVersion":"0.3.1","name":"sriov-test","type":"sriov","master":"enp175s0f1","vlan":500,"deviceID":"0000:af:06.0","ipam":{"type":"fakeipam","ips":[{"ipcidr":"192.168.1.65/26","version":4}]}}}`)},
+  {"sriov-vf-options", []byte(`{"cniexp":{"cnitype":"sriov","ip":"192.168.1.65/26","env":{"CNI_COMMAND":"ADD","CNI_IFNAME":"eth0"}},"cniconf":{"cniVersion":"0.3.1","name":"sriov-test","type":"sriov","master":"enp175s0f1","vlan":500,"deviceID":"0000:af:06.0","mac":"02:00:c0:a8:01:41","vlanQoS":3,"spoofchk":"off","trust":"on","link_state":"enable","min_tx_rate":100,"max_tx_rate":1000,"ipam":{"type":"fakeipam","ips":[{"ipcidr":"192.168.1.65/26","version":4}]}}}`)},
+  {"host-device-l3", []byte(`{"cniexp":{"cnitype":"host-device","ip":"192.168.1.65/26","env":{"CNI_COMMAND":"ADD","CNI_IFNAME":"eth0"}},"cniconf":{"cniVersion":"0.3.1","name":"hd_net","type":"host-device","dns":{},"pciBusID":"0000:3b:00.0","ipam":{"type":"fakeipam","ips":[{"ipcidr":"192.168.1.65/26","version":4}]}}}`)},
+  {"deletehostdevice", []byte(`{"cniexp":{"cnitype":"host-device","env":{"CNI_COMMAND":"DEL","CNI_IFNAME":"eth0"}},"cniconf":{"cniVersion":"0.3.1","name":"hd_net","type":"host-device","dns":{},"pciBusID":"0000:3b:00.0","ipam":{"type":"fakeipam","ips":[{"ipcidr":"192.168.1.65/26","version":4}]}}}`)},
   {"sriov-l2", []byte(`{"cniexp":{"cnitype":"sriov","env":{"CNI_COMMAND":"ADD","CNI_IFNAME":"eth0"}},"cniconf":{"cniVersion":"0.3.1","name":"sriov-test","type":"sriov","master":"enp175s0f1","vlan":500,"deviceID":"0000:af:06.0"}}`)},
+  {"vlan-template", []byte(`{"cniexp":{"cnitype":"vlan","ip":"192.168.1.65/26","env":{"CNI_COMMAND":"ADD","CNI_IFNAME":"ens1f0"}},"cniconf":{"cniVersion":"0.3.1","name":"vlan_net","type":"vlan","master":"ens1f0","vlanId":200,"mtu":1500,"ipam":{"type":"fakeipam","ips":[{"ipcidr":"192.168.1.65/26","version":4}]}}}`)},
+  {"vlan-template-l2", []byte(`{"cniexp":{"cnitype":"vlan","env":{"CNI_COMMAND":"ADD","CNI_IFNAME":"eth0"}},"cniconf":{"cniVersion":"0.3.1","name":"vlan_net","type":"vlan","master":"ens1f0","vlanId":200,"mtu":1500,"ipam":{}}}`)},
+  {"deletevlan", []byte(`{"cniexp":{"cnitype":"vlan","env":{"CNI_COMMAND":"DEL","CNI_IFNAME":"ens1f0"}},"cniconf":{"cniVersion":"0.3.1","name":"vlan_net","type":"vlan","master":"ens1f0","vlanId":200,"mtu":1500,"ipam":{"type":"fakeipam","ips":[{"ipcidr":"192.168.1.65/26","version":4}]}}}`)},
+  {"ptp-configmap", []byte(`{"cniexp":{"cnitype":"ptp","ip":"192.168.1.65/26","env":{"CNI_COMMAND":"ADD","CNI_IFNAME":"eth0"}},"cniconf":{"cniVersion":"0.3.1","name":"ptp_net","type":"ptp","ipMasq":true,"ipam":{"type":"fakeipam","ips":[{"ipcidr":"192.168.1.65/26","version":4}]}}}`)},
+  {"deleteptp", []byte(`{"cniexp":{"cnitype":"ptp","env":{"CNI_COMMAND":"DEL","CNI_IFNAME":"eth0"}},"cniconf":{"cniVersion":"0.3.1","name":"ptp_net","type":"ptp","ipMasq":true,"ipam":{"type":"fakeipam","ips":[{"ipcidr":"192.168.1.65/26","version":4}]}}}`)},
   {"deleteflannel", []byte(`{"cniexp":{"cnitype":"flannel","env":{"CNI_COMMAND":"DEL","CNI_IFNAME":"eth0"}},"cniconf":{"cniVersion":"0.3.1","name":"cbr0","type":"flannel","delegate":{"hairpinMode":true,"isDefaultGateway":true}}}`)},
   {"deletemacvlan", []byte(`{"cniexp":{"cnitype":"macvlan","env":{"CNI_COMMAND":"DEL","CNI_IFNAME":"ens1f0"}},"cniconf":{"cniVersion":"0.3.1","name":"full","master":"ens1f0","mode":"bridge","mtu":1500,"ipam": {"type": "fakeipam","ips":[{"ipcidr":"192.168.1.65/26","version":4}]}}}`)},
   {"bridge-l3-ip4", []byte(`{"cniexp":{"cnitype":"macvlan","ip":"192.168.1.65/26","env":{"CNI_COMMAND":"ADD","CNI_IFNAME":"eth0"}},"cniconf":{"cniVersion":"0.3.1","name": "mynet","type": "bridge","bridge": "mynet0","isDefaultGateway": true,"forceAddress": false,"ipMasq": true,"hairpinMode": true,"ipam": {"type": "fakeipam","ips":[{"ipcidr":"192.168.1.65/26","version":4}]}}}`)},
@@ -171,10 +263,18 @@ var testEps = []danmtypes.DanmEp {
   danmtypes.DanmEp{
     ObjectMeta: meta_v1.ObjectMeta {Name: "noIps"}, Spec: danmtypes.DanmEpSpec{Iface: danmtypes.DanmEpIface{Name: "eth0"}},
   },
+  danmtypes.DanmEp{
+    ObjectMeta: meta_v1.ObjectMeta {Name: "dynamicIpv4WithNic"},
+    Spec: danmtypes.DanmEpSpec {Iface: danmtypes.DanmEpIface{Name:"eth0", Address: "192.168.1.65/26", DeviceID: "0000:3b:00.0"},},
+  },
   danmtypes.DanmEp{
     ObjectMeta: meta_v1.ObjectMeta {Name: "dynamicIpv4WithDeviceId"},
     Spec: danmtypes.DanmEpSpec {Iface: danmtypes.DanmEpIface{Name:"eth0", Address: "192.168.1.65/26", DeviceID: "0000:af:06.0"},},
   },
+  danmtypes.DanmEp{
+    ObjectMeta: meta_v1.ObjectMeta {Name: "dynamicIpv4WithDeviceIdAndMac"},
+    Spec: danmtypes.DanmEpSpec {Iface: danmtypes.DanmEpIface{Name:"eth0", Address: "192.168.1.65/26", DeviceID: "0000:af:06.0", MacAddress: "02:00:c0:a8:01:41"},},
+  },
   danmtypes.DanmEp{
     ObjectMeta: meta_v1.ObjectMeta {Name: "noneWithDeviceId"},
     Spec: danmtypes.DanmEpSpec {Iface: danmtypes.DanmEpIface{Name:"eth0", Address: "none", DeviceID: "0000:af:06.0"},},
@@ -216,6 +316,8 @@ var delegationRequiredTcs = []struct {
   {"empty", false},
   {"ipvlan", false},
   {"IPVLAN-UPPER", false},
+  {"bridge", false},
+  {"routed", false},
   {"sriov", true},
   {"flannel", true},
   {"hululululu", true},
@@ -227,7 +329,11 @@ var isDeviceNeededTcs = []struct {
 }{
   {"sriov", true},
   {"macvlan", false},
+  {"vlan", false},
+  {"vfio", true},
+  {"host-device", true},
   {"neverhas", false},
+  {"errortemplate", false},
 }
 
 var delSetupTcs = []struct {
@@ -253,7 +359,9 @@ var delSetupTcs = []struct {
   {"dynamicMacvlanIpv6Type020Result", "macvlan-v6", "dynamicIpv6", "macvlan-ip6-type020", "", "2a00:8a00:a000:1193", false, true},
   {"dynamicSriovNoDeviceId", "sriov-test", "dynamicIpv4", "", "", "", true, true},
   {"dynamicSriovL3", "sriov-test", "dynamicIpv4WithDeviceId", "sriov-l3", "", "", false, true},
+  {"dynamicSriovWithVfOptions", "sriov-vf-options", "dynamicIpv4WithDeviceIdAndMac", "sriov-vf-options", "", "", false, true},
   {"dynamicSriovL2", "sriov-test", "noneWithDeviceId", "sriov-l2", "", "", false, true},
+  {"dynamicHostDevice", "host-device-test", "dynamicIpv4WithNic", "host-device-l3", "192.168.1.65", "", false, true},
   {"bridgeWithV4Overwrite", "bridge-ipam-ipv4", "simpleIpv4", "bridge-l3-ip4", "", "", false, true},
   {"bridgeWithV4Add", "bridge-ipam-l2", "simpleIpv4", "bridge-l2-ip4", "", "", false, true},
   {"bridgeWithInvalidAdd", "bridge-invalid", "simpleIpv4", "", "", "", true, false},
@@ -262,6 +370,14 @@ var delSetupTcs = []struct {
   {"bridgeL2OriginalNoCidr", "bridge-noipam-l2", "simpleIpv4", "bridge-l2-orig", "", "", false, false},
   {"bridgeWithV6Overwrite", "bridge-ipam-ipv6", "simpleIpv6", "bridge-l3-ip6", "", "", false, true},
   {"bridgeWithDsOverwrite", "bridge-ipam-ds", "simpleDs", "bridge-l3-ds", "", "", false, true},
+  {"templateWithIpam", "vlan-template", "dynamicIpv4", "vlan-template", "192.168.1.65", "", false, true},
+  {"templateWithoutIpam", "vlan-template", "noIps", "vlan-template-l2", "", "", false, false},
+  {"templateRenderedToInvalidJson", "invalid-json-template", "dynamicIpv4", "", "", "", true, true},
+  {"templateWithInvalidPlaceholder", "invalid-placeholder-template", "dynamicIpv4", "", "", "", true, true},
+  {"templateCannotBeRead", "error-template", "dynamicIpv4", "", "", "", true, true},
+  {"staticConfigFromConfigMap", "configmap-static", "simpleIpv4", "ptp-configmap", "192.168.1.65", "", false, true},
+  {"staticConfigMapWithoutKey", "configmap-missing-key", "simpleIpv4", "", "", "", true, true},
+  {"staticConfigMapWithoutNamespace", "configmap-without-ns", "simpleIpv4", "", "", "", true, true},
 }
 
 var delDeleteTcs = []struct {
@@ -271,11 +387,17 @@ var delDeleteTcs = []struct {
   cniConfName string
   isErrorExpected bool
   timesUpdateShouldBeCalled int
+  isApiDown bool
 }{
-  {"flannel", "flannel-test", "deleteFlannel", "deleteflannel", false, 0},
-  {"macvlan", "full-macvlan", "withAddress", "deletemacvlan", false, 1},
-  {"bridgeWithDanmIpam", "full-bridge", "withAddressSimple", "deletebridge", false, 1},
-  {"bridgeWithExternalIpam", "full-bridge", "withForeignAddressSimple", "deletebridge-wo-ipam", false, 0},
+  {"flannel", "flannel-test", "deleteFlannel", "deleteflannel", false, 0, false},
+  {"macvlan", "full-macvlan", "withAddress", "deletemacvlan", false, 1, false},
+  {"bridgeWithDanmIpam", "full-bridge", "withAddressSimple", "deletebridge", false, 1, false},
+  {"bridgeWithExternalIpam", "full-bridge", "withForeignAddressSimple", "deletebridge-wo-ipam", false, 0, false},
+  {"templateWithDanmIpam", "vlan-template", "withAddress", "deletevlan", false, 1, false},
+  {"hostDeviceWithDanmIpam", "host-device-test", "dynamicIpv4WithNic", "deletehostdevice", false, 1, false},
+  {"staticConfigFromConfigMap", "configmap-static", "withAddressSimple", "deleteptp", false, 0, false},
+  {"staticConfigFromCache", "configmap-static", "withAddressSimple", "deleteptp", false, 0, true},
+  {"staticConfigNotCached", "configmap-uncached", "withAddressSimple", "deleteptp", true, 0, true},
 }
 
 func TestIsDelegationRequired(t *testing.T) {
@@ -292,13 +414,29 @@ func TestIsDelegationRequired(t *testing.T) {
 
 func TestIsDeviceNeeded(t *testing.T) {
   for _, tc := range isDeviceNeededTcs {
-    isDevNeeded := cnidel.IsDeviceNeeded(tc.BackendName)
+    backend, _ := cnidel.GetBackendConfig(getTestClient(), tc.BackendName)
+    isDevNeeded := cnidel.IsDeviceNeeded(backend)
     if isDevNeeded != tc.deviceNeeded {
       t.Errorf("Received device needed result does not match with expected")
     }
   }
 }
 
+func TestGetBackendConfig(t *testing.T) {
+  backend, err := cnidel.GetBackendConfig(getTestClient(), "vfio")
+  if err != nil || backend == nil {
+    t.Errorf("Backend defined by a CniTemplate was not resolved")
+  }
+  backend, err = cnidel.GetBackendConfig(getTestClient(), "neverhas")
+  if err != nil || backend != nil {
+    t.Errorf("NetworkType without a CniTemplate was not handled as a static backend")
+  }
+  _, err = cnidel.GetBackendConfig(getTestClient(), "errortemplate")
+  if err == nil {
+    t.Errorf("Error of the CniTemplate lookup was not returned")
+  }
+}
+
 func TestGetEnv(t *testing.T) {
   testEnvKey := "HOTEL"
   testEnvVal := "trivago"
@@ -328,7 +466,7 @@ func TestDelegateInterfaceSetup(t *testing.T) {
       testNet := utils.GetTestNet(tc.netName, testNets)
       testEp := getTestEp(tc.epName)
       testEp.Spec.NetworkName = testNet.ObjectMeta.Name
-      cniRes, err := cnidel.DelegateInterfaceSetup(&cniConf,tc.isIpAlreadyAllocatedByDanmIpam,testNet,testEp)
+      cniRes, err := cnidel.DelegateInterfaceSetup(context.Background(), &cniConf,getTestBackend(testNet.Spec.NetworkType),getTestK8sClient(false),tc.isIpAlreadyAllocatedByDanmIpam,testNet,testEp)
       if (err != nil && !tc.isErrorExpected) || (err == nil && tc.isErrorExpected) {
         var detailedErrorMessage string
         if err != nil {
@@ -384,7 +522,7 @@ func TestDelegateInterfaceDelete(t *testing.T) {
           t.Errorf("Delete TC Flannel prereq could not be set-up because:%s", err.Error())
         }
       }
-      err := cnidel.DelegateInterfaceDelete(&cniConf,testNet,testEp)
+      err := cnidel.DelegateInterfaceDelete(context.Background(), &cniConf,getTestClient(),getTestK8sClient(tc.isApiDown),testNet,testEp)
       if (err != nil && !tc.isErrorExpected) || (err == nil && tc.isErrorExpected) {
         var detailedErrorMessage string
         if err != nil {
@@ -405,6 +543,7 @@ func TestDelegateInterfaceDelete(t *testing.T) {
 
 func setupDelTest(opType string) error {
   os.RemoveAll(cniTestConfigDir)
+  os.RemoveAll(cniConfigCacheDir)
   err := os.MkdirAll(cniTestConfigDir, os.ModePerm)
   if err != nil {
     return err
@@ -425,7 +564,7 @@ func setupDelTest(opType string) error {
   if err != nil {
     return err
   }
-  testPlugins := [4]string{"flannel","macvlan","sriov","bridge"}
+  testPlugins := [7]string{"flannel","macvlan","sriov","bridge","vlan","ptp","host-device"}
   for _, plugin := range testPlugins {
     os.RemoveAll(filepath.Join(cniTesterDir, plugin))
     input, err := ioutil.ReadFile(filepath.Join(os.Getenv("GOPATH"),"bin","cnitest"))
@@ -472,6 +611,21 @@ func setupDelTestTc(expectedCniConfig string) error {
   return nil
 }
 
+func getTestClient() *stubs.ClientSetStub {
+  return stubs.NewClientSetStub(utils.TestArtifacts{TestTemplates: testTemplates})
+}
+
+func getTestK8sClient(isApiDown bool) kubernetes.Interface {
+  if isApiDown {
+    return fake.NewSimpleClientset()
+  }
+  k8sClient := fake.NewSimpleClientset()
+  for _, cm := range testConfigMaps {
+    k8sClient.CoreV1().ConfigMaps(cm.ObjectMeta.Namespace).Create(&cm)
+  }
+  return k8sClient
+}
+
 func getTestEp(epId string) *danmtypes.DanmEp {
   for _, ep := range testEps {
     if ep.ObjectMeta.Name == epId {
@@ -483,4 +637,9 @@ func getTestEp(epId string) *danmtypes.DanmEp {
 
 func teardownDelTest() error {
   return sriov_utils.RemoveTmpSysFs()
-}
\ No newline at end of file
+}
+
+func getTestBackend(cniType string) *datastructs.CniBackendConfig {
+  backend, _ := cnidel.GetBackendConfig(getTestClient(), cniType)
+  return backend
+}
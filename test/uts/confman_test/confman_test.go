@@ -1,6 +1,7 @@
 package confman_test
 
 import (
+  "reflect"
   "strconv"
   "testing"
   danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
@@ -311,4 +312,103 @@ func getIfaceFromTconf(ifaceName string, vniType string, tconf *danmtypes.Tenant
 func isVniSet(iface danmtypes.IfaceProfile, vni int) bool {
   allocs := bitarray.NewBitArrayFromBase64(iface.Alloc)
   return allocs.Get(uint32(vni))
-}
\ No newline at end of file
+}
+var reserveRtTableTcs = []struct {
+  tcName string
+  tconfName string
+  rtTableRange string
+  rtTableAlloc string
+  usedTables map[int]string
+  isErrorExpected bool
+  expectedTable int
+  timesUpdateShouldBeCalled int
+}{
+  {"reserveFirstFreeTable", "tconf", "200-202", utils.AllocFor5k, nil, false, 200, 1},
+  {"skipTableUsedByNetwork", "tconf", "200-202", utils.AllocFor5k, map[int]string{200: "DanmNet:default/internal"}, false, 201, 1},
+  {"noFreeTableInRange", "tconf", "200", utils.AllocFor5k, map[int]string{200: "DanmNet:default/internal"}, true, 0, 0},
+  {"invalidRange", "tconf", "200-a", utils.AllocFor5k, nil, true, 0, 0},
+  {"corruptedTableAllocation", "tconf", "200-202", "", nil, true, 0, 0},
+  {"errorUpdating", "error", "200-202", utils.AllocFor5k, nil, true, 0, 1},
+}
+
+func TestReserveRtTable(t *testing.T) {
+  for _, tc := range reserveRtTableTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      tconf := &danmtypes.TenantConfig{ObjectMeta: meta_v1.ObjectMeta {Name: tc.tconfName}, RtTableRange: tc.rtTableRange, RtTableAlloc: tc.rtTableAlloc}
+      testArtifacts := utils.TestArtifacts{TestTconfs: []danmtypes.TenantConfig{*tconf}}
+      tconfClientStub := stubs.NewClientSetStub(testArtifacts)
+      rtTable, err := confman.ReserveRtTable(tconfClientStub, tconf, tc.usedTables)
+      if (err != nil && !tc.isErrorExpected) || (err == nil && tc.isErrorExpected) {
+        t.Errorf("Received error:%v does not match with expectation", err)
+        return
+      }
+      if tc.expectedTable != 0 {
+        if tc.expectedTable != rtTable {
+          t.Errorf("Received routing table:%d does not match with expected:%d", rtTable, tc.expectedTable)
+          return
+        }
+        if !bitarray.NewBitArrayFromBase64(tconf.RtTableAlloc).Get(uint32(rtTable)) {
+          t.Errorf("Routing table:%d was returned, but it is not reserved in TenantConfig", rtTable)
+          return
+        }
+      }
+      var timesUpdateWasCalled int
+      if tconfClientStub.DanmClient.TconfClient != nil {
+        timesUpdateWasCalled = tconfClientStub.DanmClient.TconfClient.TimesUpdateWasCalled
+      }
+      if tc.timesUpdateShouldBeCalled != timesUpdateWasCalled {
+        t.Errorf("Tconf should have been updated:" + strconv.Itoa(tc.timesUpdateShouldBeCalled) + " times, but it happened:" + strconv.Itoa(timesUpdateWasCalled) + " times instead")
+      }
+    })
+  }
+}
+
+func TestFreeRtTable(t *testing.T) {
+  ba := bitarray.NewBitArrayFromBase64(utils.AllocFor5k)
+  ba.Set(200)
+  ba.Set(201)
+  tconf := &danmtypes.TenantConfig{ObjectMeta: meta_v1.ObjectMeta {Name: "tconf"}, RtTableRange: "200-202", RtTableAlloc: ba.Encode()}
+  tconfClientStub := stubs.NewClientSetStub(utils.TestArtifacts{TestTconfs: []danmtypes.TenantConfig{*tconf}})
+  err := confman.FreeRtTable(tconfClientStub, tconf, 200)
+  if err != nil {
+    t.Errorf("Routing table could not be freed because:%v", err)
+    return
+  }
+  allocs := bitarray.NewBitArrayFromBase64(tconf.RtTableAlloc)
+  if allocs.Get(200) || !allocs.Get(201) {
+    t.Errorf("Only routing table 200 should have been freed in TenantConfig")
+  }
+}
+
+var reservedVniTcs = []struct {
+  tcName string
+  iface danmtypes.IfaceProfile
+  reservedVnis []int
+  expectedVnis []int
+  expectedTotal int
+  isErrorExpected bool
+}{
+  {"noVniType", danmtypes.IfaceProfile{Name: "ens3"}, nil, []int{}, 0, false},
+  {"invalidRange", danmtypes.IfaceProfile{Name: "ens4", VniType: "vlan", VniRange: "invalid", Alloc: utils.AllocFor5k}, nil, nil, 0, true},
+  {"noneReserved", danmtypes.IfaceProfile{Name: "ens4", VniType: "vlan", VniRange: "200,500-510", Alloc: utils.AllocFor5k}, nil, []int{}, 12, false},
+  {"reservedOutsideOfRangeIgnored", danmtypes.IfaceProfile{Name: "ens4", VniType: "vxlan", VniRange: "200,500-510", Alloc: utils.AllocFor5k}, []int{199, 201, 509, 511}, []int{200, 509, 510}, 12, false},
+}
+
+func TestGetReservedVnis(t *testing.T) {
+  for _, tc := range reservedVniTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      iface := tc.iface
+      for i := 0; i < len(tc.reservedVnis); i += 2 {
+        utils.ReserveVnis(&iface, tc.reservedVnis[i:i+2])
+      }
+      vnis, total, err := confman.GetReservedVnis(iface)
+      if (err != nil) != tc.isErrorExpected {
+        t.Errorf("Received error:%v does not match with expectation", err)
+        return
+      }
+      if !reflect.DeepEqual(vnis, tc.expectedVnis) || total != tc.expectedTotal {
+        t.Errorf("Reserved VNIs:%v of all the:%d VNIs do not match with the expected:%v of:%d", vnis, total, tc.expectedVnis, tc.expectedTotal)
+      }
+    })
+  }
+}
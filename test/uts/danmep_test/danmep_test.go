@@ -0,0 +1,198 @@
+package danmep_test
+
+import (
+  "testing"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  "github.com/nokia/danm/pkg/danmep"
+  "github.com/nokia/danm/pkg/datastructs"
+)
+
+var ifaceNameTcs = []struct {
+  tcName string
+  namingScheme string
+  prefix string
+  iface datastructs.Interface
+  expectedName string
+}{
+  {"primary", "", "ext", datastructs.Interface{SequenceId: 0, DefaultIfaceName: "eth"}, "eth0"},
+  {"default", "", "", datastructs.Interface{SequenceId: 2, DefaultIfaceName: "eth"}, "eth2"},
+  {"prefix", "", "ext", datastructs.Interface{SequenceId: 1, DefaultIfaceName: "eth"}, "ext1"},
+  {"legacyPrefix", datastructs.LegacyNamingScheme, "ext", datastructs.Interface{SequenceId: 1, DefaultIfaceName: "eth"}, "ext"},
+  {"ifNameOverPrefix", "", "ext", datastructs.Interface{SequenceId: 1, DefaultIfaceName: "eth", IfName: "sig0"}, "sig0"},
+  {"ifNameOverLegacyPrefix", datastructs.LegacyNamingScheme, "ext", datastructs.Interface{SequenceId: 3, DefaultIfaceName: "eth", IfName: "oam0"}, "oam0"},
+  {"ifNameOnPrimary", "", "", datastructs.Interface{SequenceId: 0, DefaultIfaceName: "eth", IfName: "oam0"}, "eth0"},
+}
+
+func TestCalculateIfaceName(t *testing.T) {
+  for _, tc := range ifaceNameTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      netInfo := &danmtypes.DanmNet{Spec: danmtypes.DanmNetSpec{Options: danmtypes.DanmNetOption{Prefix: tc.prefix}}}
+      ifaceName := danmep.CalculateIfaceName(tc.namingScheme, netInfo, tc.iface)
+      if ifaceName != tc.expectedName {
+        t.Errorf("Calculated interface name:%s does not match with the expected:%s", ifaceName, tc.expectedName)
+      }
+    })
+  }
+}
+
+var macFromIpTcs = []struct {
+  tcName string
+  ip4 string
+  ip6 string
+  expectedMac string
+}{
+  {"ipv4", "10.20.30.40/24", "", "02:00:0a:14:1e:28"},
+  {"dualStack", "192.168.1.5/24", "2001:db8::a0b:c0d/64", "02:00:c0:a8:01:05"},
+  {"ipv6Only", "", "2001:db8::a0b:c0d/64", "02:06:0a:0b:0c:0d"},
+  {"ipv6OnlyWithNoneV4", "none", "2001:db8::a0b:c0d/64", "02:06:0a:0b:0c:0d"},
+  {"noAddresses", "", "", ""},
+  {"dynamicNotYetAllocated", "dynamic", "dynamic", ""},
+}
+
+func TestGenerateMacFromIp(t *testing.T) {
+  for _, tc := range macFromIpTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      mac := danmep.GenerateMacFromIp(tc.ip4, tc.ip6)
+      if mac != tc.expectedMac {
+        t.Errorf("Generated MAC:%s does not match with the expected:%s", mac, tc.expectedMac)
+      }
+    })
+  }
+}
+
+var macConfigurableTcs = []struct {
+  neType string
+  isConfigurable bool
+}{
+  {"bridge", true},
+  {"routed", true},
+  {"OVS", true},
+  {"macvlan", true},
+  {"sriov", true},
+  {"ipvlan", false},
+  {"", false},
+  {"flannel", false},
+  {"host-device", false},
+}
+
+func TestIsMacConfigurable(t *testing.T) {
+  for _, tc := range macConfigurableTcs {
+    t.Run(tc.neType, func(t *testing.T) {
+      if danmep.IsMacConfigurable(tc.neType) != tc.isConfigurable {
+        t.Errorf("MAC configurability of NetworkType:%s does not match with the expected:%t", tc.neType, tc.isConfigurable)
+      }
+    })
+  }
+}
+
+var validateSysctlsTcs = []struct {
+  tcName string
+  sysctls map[string]string
+  isErrorExpected bool
+}{
+  {"noSysctls", nil, false},
+  {"multiHomingTuning", map[string]string{"ipv4.rp_filter": "2", "ipv4.arp_ignore": "1", "ipv4.arp_announce": "2"}, false},
+  {"ipv6Params", map[string]string{"ipv6.accept_ra": "2", "ipv6.forwarding": "1"}, false},
+  {"notInterfaceScoped", map[string]string{"ipv4.ip_forward": "1"}, true},
+  {"managedByDanm", map[string]string{"ipv6.disable_ipv6": "0"}, true},
+  {"fullSysctlName", map[string]string{"net.ipv4.conf.eth0.rp_filter": "2"}, true},
+  {"unknownFamily", map[string]string{"ipx.rp_filter": "2"}, true},
+  {"nonIntegerValue", map[string]string{"ipv4.rp_filter": "loose"}, true},
+}
+
+func TestValidateSysctls(t *testing.T) {
+  for _, tc := range validateSysctlsTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      err := danmep.ValidateSysctls(tc.sysctls)
+      if (err != nil) != tc.isErrorExpected {
+        t.Errorf("Received error:%v does not match with expectation", err)
+      }
+    })
+  }
+}
+
+func TestMergeSysctls(t *testing.T) {
+  if danmep.MergeSysctls(nil, map[string]string{}) != nil {
+    t.Errorf("Merging empty sysctls shall not result in a non-nil map")
+  }
+  netSysctls := map[string]string{"ipv4.rp_filter": "1", "ipv4.arp_ignore": "1"}
+  podSysctls := map[string]string{"ipv4.rp_filter": "2", "ipv6.accept_ra": "0"}
+  expectedSysctls := map[string]string{"ipv4.rp_filter": "2", "ipv4.arp_ignore": "1", "ipv6.accept_ra": "0"}
+  sysctls := danmep.MergeSysctls(netSysctls, podSysctls)
+  if len(sysctls) != len(expectedSysctls) {
+    t.Fatalf("Number of merged sysctls:%d does not match with the expected:%d", len(sysctls), len(expectedSysctls))
+  }
+  for key, value := range expectedSysctls {
+    if sysctls[key] != value {
+      t.Errorf("Merged value of sysctl:%s is:%s, but expected:%s", key, sysctls[key], value)
+    }
+  }
+  if netSysctls["ipv4.rp_filter"] != "1" {
+    t.Errorf("Merging modified the sysctls of the network")
+  }
+}
+
+var validateBandwidthTcs = []struct {
+  tcName string
+  limits danmtypes.BandwidthLimits
+  isErrorExpected bool
+}{
+  {"noLimits", danmtypes.BandwidthLimits{}, false},
+  {"bothDirections", danmtypes.BandwidthLimits{IngressRate: 100000000, IngressBurst: 1000000, EgressRate: 50000000, EgressBurst: 500000}, false},
+  {"onlyEgress", danmtypes.BandwidthLimits{EgressRate: 50000000, EgressBurst: 500000}, false},
+  {"rateWithoutBurst", danmtypes.BandwidthLimits{IngressRate: 100000000}, true},
+  {"burstWithoutRate", danmtypes.BandwidthLimits{EgressBurst: 500000}, true},
+  {"subByteRate", danmtypes.BandwidthLimits{EgressRate: 7, EgressBurst: 500000}, true},
+  {"subByteBurst", danmtypes.BandwidthLimits{IngressRate: 100000000, IngressBurst: 4}, true},
+}
+
+func TestValidateBandwidth(t *testing.T) {
+  for _, tc := range validateBandwidthTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      err := danmep.ValidateBandwidth(tc.limits)
+      if (err != nil) != tc.isErrorExpected {
+        t.Errorf("Received error:%v does not match with expectation", err)
+      }
+    })
+  }
+}
+
+var mergeBandwidthTcs = []struct {
+  tcName string
+  netLimits danmtypes.BandwidthLimits
+  podLimits danmtypes.BandwidthLimits
+  expectedLimits danmtypes.BandwidthLimits
+  isErrorExpected bool
+}{
+  {"onlyNetwork", danmtypes.BandwidthLimits{IngressRate: 1000, IngressBurst: 100}, danmtypes.BandwidthLimits{}, danmtypes.BandwidthLimits{IngressRate: 1000, IngressBurst: 100}, false},
+  {"onlyPod", danmtypes.BandwidthLimits{}, danmtypes.BandwidthLimits{EgressRate: 1000, EgressBurst: 100}, danmtypes.BandwidthLimits{EgressRate: 1000, EgressBurst: 100}, false},
+  {"podTightensIngress", danmtypes.BandwidthLimits{IngressRate: 1000, IngressBurst: 100, EgressRate: 2000, EgressBurst: 200}, danmtypes.BandwidthLimits{IngressRate: 500, IngressBurst: 50},
+    danmtypes.BandwidthLimits{IngressRate: 500, IngressBurst: 50, EgressRate: 2000, EgressBurst: 200}, false},
+  {"podLoosensIngress", danmtypes.BandwidthLimits{IngressRate: 1000, IngressBurst: 100}, danmtypes.BandwidthLimits{IngressRate: 5000, IngressBurst: 100}, danmtypes.BandwidthLimits{}, true},
+  {"podLoosensEgress", danmtypes.BandwidthLimits{EgressRate: 1000, EgressBurst: 100}, danmtypes.BandwidthLimits{EgressRate: 1001, EgressBurst: 100}, danmtypes.BandwidthLimits{}, true},
+}
+
+func TestMergeBandwidth(t *testing.T) {
+  for _, tc := range mergeBandwidthTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      limits, err := danmep.MergeBandwidth(tc.netLimits, tc.podLimits)
+      if (err != nil) != tc.isErrorExpected {
+        t.Fatalf("Received error:%v does not match with expectation", err)
+      }
+      if err == nil && limits != tc.expectedLimits {
+        t.Errorf("Merged limits:%+v do not match with the expected:%+v", limits, tc.expectedLimits)
+      }
+    })
+  }
+}
+
+func TestGetIfbName(t *testing.T) {
+  ep := &danmtypes.DanmEp{Spec: danmtypes.DanmEpSpec{EndpointID: "b8eb7ea2-1fa4-4b5b-a3f9-2e1bd1b0d7e1"}}
+  ifbName := danmep.GetIfbName(ep)
+  if ifbName != "ifbb8eb7ea2-1fa" {
+    t.Errorf("IFB device name:%s does not match with the expected", ifbName)
+  }
+  if len(ifbName) > 15 {
+    t.Errorf("IFB device name:%s is longer than the kernel allows", ifbName)
+  }
+}
@@ -0,0 +1,143 @@
+package danmep_test
+
+import (
+  "testing"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  "github.com/nokia/danm/pkg/danmep"
+  "github.com/nokia/danm/test/stubs/ovs"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var testNets = []danmtypes.DanmNet {
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "ovs"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "ovs", NetworkID: "ovs", Options: danmtypes.DanmNetOption{Device: "br-int"}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "ovs-vlan"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "ovs", NetworkID: "ovs", Options: danmtypes.DanmNetOption{Device: "br-int", Vlan: 200}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "ovs-dpdk"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "OVS", NetworkID: "ovs", Options: danmtypes.DanmNetOption{Device: "br-dpdk", DevicePool: "nokia.k8s.io/vhostuser", Vlan: 300}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "bridge"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "bridge"},
+  },
+}
+
+var testEps = []danmtypes.DanmEp {
+  danmtypes.DanmEp {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "veth"},
+    Spec: danmtypes.DanmEpSpec {EndpointID: "b8eb7ea2-1fa4-4b5b-a3f9-2e1bd1b0d7e1", Iface: danmtypes.DanmEpIface{Name: "eth0"}},
+  },
+  danmtypes.DanmEp {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "vhostuser"},
+    Spec: danmtypes.DanmEpSpec {EndpointID: "0a7d1c6e-3e5b-4bd2-9c3f-65c4a1e0f2b9", Iface: danmtypes.DanmEpIface{Name: "eth1", DeviceID: "vhost0"}},
+  },
+}
+
+var addPortTcs = []struct {
+  tcName string
+  netName string
+  epName string
+  errorOnCommand string
+  expectedCommand string
+  isErrorExpected bool
+}{
+  {"vethPortWithoutTag", "ovs", "veth", "", "--may-exist add-port br-int dvb8eb7ea2-1fa4 -- set Interface dvb8eb7ea2-1fa4 external_ids:danm-endpoint-id=b8eb7ea2-1fa4-4b5b-a3f9-2e1bd1b0d7e1", false},
+  {"vethPortWithTag", "ovs-vlan", "veth", "", "--may-exist add-port br-int dvb8eb7ea2-1fa4 tag=200 -- set Interface dvb8eb7ea2-1fa4 external_ids:danm-endpoint-id=b8eb7ea2-1fa4-4b5b-a3f9-2e1bd1b0d7e1", false},
+  {"vhostUserPort", "ovs-dpdk", "vhostuser", "", "--may-exist add-port br-dpdk vhu0a7d1c6e-3e5b tag=300 -- set Interface vhu0a7d1c6e-3e5b external_ids:danm-endpoint-id=0a7d1c6e-3e5b-4bd2-9c3f-65c4a1e0f2b9 type=dpdkvhostuserclient options:vhost-server-path=/var/lib/danm/vhostuser/vhost0", false},
+  {"vsctlError", "ovs", "veth", "add-port", "--may-exist add-port br-int dvb8eb7ea2-1fa4 -- set Interface dvb8eb7ea2-1fa4 external_ids:danm-endpoint-id=b8eb7ea2-1fa4-4b5b-a3f9-2e1bd1b0d7e1", true},
+}
+
+var deletePortTcs = []struct {
+  tcName string
+  epName string
+  errorOnCommand string
+  expectedCommand string
+  isErrorExpected bool
+}{
+  {"vethPort", "veth", "", "--if-exists del-port dvb8eb7ea2-1fa4", false},
+  {"vhostUserPort", "vhostuser", "", "--if-exists del-port vhu0a7d1c6e-3e5b", false},
+  {"vsctlError", "veth", "del-port", "--if-exists del-port dvb8eb7ea2-1fa4", true},
+}
+
+var isVhostUserTcs = []struct {
+  tcName string
+  netName string
+  epName string
+  isVhostUserExpected bool
+}{
+  {"ovsWithoutDevice", "ovs", "veth", false},
+  {"ovsWithDevice", "ovs-dpdk", "vhostuser", true},
+  {"bridgeWithDevice", "bridge", "vhostuser", false},
+}
+
+func TestAddOvsPort(t *testing.T) {
+  for _, tc := range addPortTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      executor := ovs.NewExecutorStub(tc.errorOnCommand)
+      danmep.OvsExec = executor
+      err := danmep.AddOvsPort(getTestNet(tc.netName), getTestEp(tc.epName))
+      if (err != nil && !tc.isErrorExpected) || (err == nil && tc.isErrorExpected) {
+        t.Errorf("Received error:%v does not match with expectation", err)
+      }
+      validateCommands(t, executor, tc.expectedCommand)
+    })
+  }
+}
+
+func TestDeleteOvsPort(t *testing.T) {
+  for _, tc := range deletePortTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      executor := ovs.NewExecutorStub(tc.errorOnCommand)
+      danmep.OvsExec = executor
+      err := danmep.DeleteOvsPort(getTestEp(tc.epName))
+      if (err != nil && !tc.isErrorExpected) || (err == nil && tc.isErrorExpected) {
+        t.Errorf("Received error:%v does not match with expectation", err)
+      }
+      validateCommands(t, executor, tc.expectedCommand)
+    })
+  }
+}
+
+func TestIsVhostUserEp(t *testing.T) {
+  for _, tc := range isVhostUserTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      isVhostUser := danmep.IsVhostUserEp(getTestNet(tc.netName), getTestEp(tc.epName))
+      if isVhostUser != tc.isVhostUserExpected {
+        t.Errorf("Received vhost-user decision:%t does not match with the expected:%t", isVhostUser, tc.isVhostUserExpected)
+      }
+    })
+  }
+}
+
+func validateCommands(t *testing.T, executor *ovs.ExecutorStub, expectedCommand string) {
+  if len(executor.Commands) != 1 {
+    t.Errorf("Exactly one ovs-vsctl command was expected, but received:%v", executor.Commands)
+    return
+  }
+  if executor.Commands[0] != expectedCommand {
+    t.Errorf("Received ovs-vsctl command:%s does not match with the expected:%s", executor.Commands[0], expectedCommand)
+  }
+}
+
+func getTestNet(netName string) *danmtypes.DanmNet {
+  for _, net := range testNets {
+    if net.ObjectMeta.Name == netName {
+      return &net
+    }
+  }
+  return nil
+}
+
+func getTestEp(epName string) *danmtypes.DanmEp {
+  for _, ep := range testEps {
+    if ep.ObjectMeta.Name == epName {
+      return &ep
+    }
+  }
+  return nil
+}
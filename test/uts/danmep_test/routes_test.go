@@ -0,0 +1,201 @@
+package danmep_test
+
+import (
+  "net"
+  "os"
+  "testing"
+  "github.com/vishvananda/netlink"
+  "github.com/containernetworking/plugins/pkg/ns"
+  "github.com/containernetworking/plugins/pkg/testutils"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  "github.com/nokia/danm/pkg/danmep"
+)
+
+const (
+  testTable = 100
+)
+
+var routedNet = danmtypes.DanmNet {
+  Spec: danmtypes.DanmNetSpec{NetworkType: "ipvlan", Options: danmtypes.DanmNetOption{
+    Cidr: "10.0.0.0/24",
+    Routes: map[string]string{"10.10.0.0/24": "10.0.0.1"},
+    IpRoutes: []danmtypes.IpRoute{danmtypes.IpRoute{Destination: "10.20.0.0/24", Metric: 10, Gateways: []danmtypes.RouteNextHop{danmtypes.RouteNextHop{Gateway: "10.0.0.1"}}}},
+    RTables: testTable,
+  }},
+}
+
+var expectedRoutes = []danmtypes.IfaceRoute {
+  danmtypes.IfaceRoute{Destination: "10.10.0.0/24", Gateway: "10.0.0.1", OifName: "eth1"},
+  danmtypes.IfaceRoute{Destination: "10.20.0.0/24", Gateway: "10.0.0.1", OifName: "eth1", Metric: 10},
+  danmtypes.IfaceRoute{Destination: "10.30.0.0/24", Gateway: "10.0.0.1", OifName: "eth1", Table: testTable},
+}
+
+var expectedRules = []danmtypes.IfaceRule {
+  danmtypes.IfaceRule{Table: testTable, Family: netlink.FAMILY_V4, Src: "10.0.0.2/24"},
+  danmtypes.IfaceRule{Table: testTable, Family: netlink.FAMILY_V4, OifName: "eth1"},
+  danmtypes.IfaceRule{Table: testTable, Family: netlink.FAMILY_V4, IifName: "eth1"},
+}
+
+func TestRecordedRoutes(t *testing.T) {
+  testNs, ep := setupTestNs(t)
+  defer testutils.UnmountNS(testNs)
+  defer testNs.Close()
+  //The same destination is also routed through another interface of the Pod, it shall survive the clean-up of eth1
+  err := testNs.Do(func(ns.NetNS) error {
+    return addForeignRoute("10.10.0.0/24", "10.0.1.1", "eth2")
+  })
+  if err != nil {
+    t.Fatalf("Foreign IP route could not be added because:%v", err)
+  }
+  err = danmep.PostProcessInterface(ep, &routedNet)
+  if err != nil {
+    t.Fatalf("Interface could not be post-processed because:%v", err)
+  }
+  if !areRoutesEqual(ep.Spec.Iface.Routes, expectedRoutes) {
+    t.Errorf("Recorded IP routes:%v do not match with the expected:%v", ep.Spec.Iface.Routes, expectedRoutes)
+  }
+  if !areRulesEqual(ep.Spec.Iface.Rules, expectedRules) {
+    t.Errorf("Recorded policy rules:%v do not match with the expected:%v", ep.Spec.Iface.Rules, expectedRules)
+  }
+  err = danmep.DeleteIfaceRoutes(ep)
+  if err != nil {
+    t.Fatalf("IP routes could not be deleted because:%v", err)
+  }
+  if len(ep.Spec.Iface.Routes) != 0 || len(ep.Spec.Iface.Rules) != 0 {
+    t.Errorf("Deleted IP routes:%v, and policy rules:%v are still recorded", ep.Spec.Iface.Routes, ep.Spec.Iface.Rules)
+  }
+  err = testNs.Do(func(ns.NetNS) error {
+    routes, err := netlink.RouteListFiltered(netlink.FAMILY_V4, &netlink.Route{Table: 0}, netlink.RT_FILTER_TABLE)
+    if err != nil {
+      return err
+    }
+    var remainingGateways []string
+    for _, route := range routes {
+      if route.Gw != nil {
+        remainingGateways = append(remainingGateways, route.Dst.String() + " via " + route.Gw.String())
+      }
+    }
+    if len(remainingGateways) != 1 || remainingGateways[0] != "10.10.0.0/24 via 10.0.1.1" {
+      t.Errorf("Only the foreign IP route shall remain in the Pod, but found:%v", remainingGateways)
+    }
+    rules, err := netlink.RuleList(netlink.FAMILY_V4)
+    if err != nil {
+      return err
+    }
+    for _, rule := range rules {
+      if rule.Table == testTable {
+        t.Errorf("Policy rule:%v pointing to table:%d is not deleted", rule, testTable)
+      }
+    }
+    return nil
+  })
+  if err != nil {
+    t.Errorf("Remaining IP routes, and rules could not be listed because:%v", err)
+  }
+}
+
+func TestDeleteIfaceRoutesWithoutNetns(t *testing.T) {
+  host, _ := os.Hostname()
+  ep := &danmtypes.DanmEp{Spec: danmtypes.DanmEpSpec{Host: host, Netns: "/var/run/netns/non-existent", Iface: danmtypes.DanmEpIface{Name: "eth1", Routes: expectedRoutes}}}
+  err := danmep.DeleteIfaceRoutes(ep)
+  if err != nil {
+    t.Errorf("Clean-up shall be skipped when the network namespace is already gone, but failed with:%v", err)
+  }
+}
+
+//Creates a network namespace with two interfaces: eth1 owned by the returned DanmEp, and eth2 owned by someone else
+func setupTestNs(t *testing.T) (ns.NetNS, *danmtypes.DanmEp) {
+  testNs, err := testutils.NewNS()
+  if err != nil {
+    t.Skipf("Network namespace cannot be created in this environment:%v", err)
+  }
+  err = testNs.Do(func(ns.NetNS) error {
+    err := addTestLink("eth1", "10.0.0.2/24")
+    if err != nil {
+      return err
+    }
+    return addTestLink("eth2", "10.0.1.2/24")
+  })
+  if err != nil {
+    testutils.UnmountNS(testNs)
+    testNs.Close()
+    t.Fatalf("Test interfaces could not be created because:%v", err)
+  }
+  host, _ := os.Hostname()
+  ep := &danmtypes.DanmEp {
+    Spec: danmtypes.DanmEpSpec {
+      Host: host,
+      Netns: testNs.Path(),
+      Iface: danmtypes.DanmEpIface{Name: "eth1", Address: "10.0.0.2/24", Proutes: map[string]string{"10.30.0.0/24": "10.0.0.1"}},
+    },
+  }
+  return testNs, ep
+}
+
+//Veth pairs are used, because dummy interfaces are not available in every kernel
+func addTestLink(name, cidr string) error {
+  err := netlink.LinkAdd(&netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: name}, PeerName: name + "-peer"})
+  if err != nil {
+    return err
+  }
+  peer, err := netlink.LinkByName(name + "-peer")
+  if err != nil {
+    return err
+  }
+  err = netlink.LinkSetUp(peer)
+  if err != nil {
+    return err
+  }
+  link, err := netlink.LinkByName(name)
+  if err != nil {
+    return err
+  }
+  addr, err := netlink.ParseAddr(cidr)
+  if err != nil {
+    return err
+  }
+  err = netlink.AddrAdd(link, addr)
+  if err != nil {
+    return err
+  }
+  return netlink.LinkSetUp(link)
+}
+
+func addForeignRoute(dst, gw, dev string) error {
+  link, err := netlink.LinkByName(dev)
+  if err != nil {
+    return err
+  }
+  _, dstNet, _ := net.ParseCIDR(dst)
+  return netlink.RouteAdd(&netlink.Route{LinkIndex: link.Attrs().Index, Dst: dstNet, Gw: net.ParseIP(gw), Priority: 100})
+}
+
+func areRoutesEqual(routes, expectedRoutes []danmtypes.IfaceRoute) bool {
+  if len(routes) != len(expectedRoutes) {
+    return false
+  }
+  for _, expectedRoute := range expectedRoutes {
+    var isFound bool
+    for _, route := range routes {
+      if route == expectedRoute {
+        isFound = true
+      }
+    }
+    if !isFound {
+      return false
+    }
+  }
+  return true
+}
+
+func areRulesEqual(rules, expectedRules []danmtypes.IfaceRule) bool {
+  if len(rules) != len(expectedRules) {
+    return false
+  }
+  for index := range expectedRules {
+    if rules[index] != expectedRules[index] {
+      return false
+    }
+  }
+  return true
+}
@@ -0,0 +1,67 @@
+package danmep_test
+
+import (
+  "errors"
+  "reflect"
+  "testing"
+  "github.com/vishvananda/netlink"
+  "github.com/containernetworking/plugins/pkg/ns"
+  "github.com/containernetworking/plugins/pkg/testutils"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmfake "github.com/nokia/danm/crd/client/clientset/versioned/fake"
+  "github.com/nokia/danm/pkg/danmep"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetIfaceStatus(t *testing.T) {
+  testNs, ep := setupTestNs(t)
+  defer testutils.UnmountNS(testNs)
+  defer testNs.Close()
+  var link netlink.Link
+  err := testNs.Do(func(ns.NetNS) error {
+    var err error
+    link, err = netlink.LinkByName("eth1")
+    if err != nil {
+      return err
+    }
+    return netlink.LinkSetMTU(link, 1400)
+  })
+  if err != nil {
+    t.Fatalf("Test interface could not be configured because:%v", err)
+  }
+  err = danmep.SetIfaceStatus(ep)
+  if err != nil {
+    t.Fatalf("Status of the interface could not be collected because:%v", err)
+  }
+  expectedStatus := danmtypes.DanmEpStatus{MacAddress: link.Attrs().HardwareAddr.String(), OperState: "up", Mtu: 1400, Addresses: []string{"10.0.0.2/24"}}
+  if !reflect.DeepEqual(ep.Status, expectedStatus) {
+    t.Errorf("Collected status:%+v does not match with the expected:%+v", ep.Status, expectedStatus)
+  }
+  ep.Spec.Iface.Name = "eth3"
+  err = danmep.SetIfaceStatus(ep)
+  if err == nil {
+    t.Errorf("Status of a non-existent interface shall not be collected")
+  }
+}
+
+func TestUpdateDanmEpStatus(t *testing.T) {
+  ep := danmtypes.DanmEp{ObjectMeta: meta_v1.ObjectMeta{Name: "failed-ep", Namespace: "default"}}
+  client := danmfake.NewSimpleClientset(&ep)
+  danmep.SetFailedStep(&ep, danmep.LinkCreationStep, errors.New("delegate failed"))
+  err := danmep.UpdateDanmEpStatus(client, &ep)
+  if err != nil {
+    t.Fatalf("Status of the DanmEp could not be updated because:%v", err)
+  }
+  updatedEp, err := client.DanmV1().DanmEps(ep.ObjectMeta.Namespace).Get(ep.ObjectMeta.Name, meta_v1.GetOptions{})
+  if err != nil {
+    t.Fatalf("Updated DanmEp could not be read because:%v", err)
+  }
+  if updatedEp.Status.FailedStep != danmep.LinkCreationStep || updatedEp.Status.Error != "delegate failed" {
+    t.Errorf("Recorded failed step:%s, and error:%s do not match with the expected:%s, and delegate failed", updatedEp.Status.FailedStep, updatedEp.Status.Error, danmep.LinkCreationStep)
+  }
+  missingEp := danmtypes.DanmEp{ObjectMeta: meta_v1.ObjectMeta{Name: "missing-ep", Namespace: "default"}}
+  err = danmep.UpdateDanmEpStatus(client, &missingEp)
+  if err == nil {
+    t.Errorf("Status of a non-existent DanmEp shall not be updated")
+  }
+}
@@ -0,0 +1,285 @@
+package danmep_test
+
+import (
+  "net"
+  "os"
+  "strings"
+  "testing"
+  "github.com/vishvananda/netlink"
+  "github.com/containernetworking/plugins/pkg/ns"
+  "github.com/containernetworking/plugins/pkg/testutils"
+  "github.com/containernetworking/plugins/pkg/utils/sysctl"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  "github.com/nokia/danm/pkg/danmep"
+  "github.com/nokia/danm/pkg/netcontrol"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+  bridgeMtu = 1400
+)
+
+var vethNets = []danmtypes.DanmNet {
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "bridged"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "bridged"},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "hairpin"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "hairpin", Options: danmtypes.DanmNetOption{Hairpin: true}},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "nobridge"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "bridge", NetworkID: "nobridge"},
+  },
+  danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "routed"},
+    Spec: danmtypes.DanmNetSpec{NetworkType: "routed", NetworkID: "routed"},
+  },
+}
+
+var addBridgeInterfaceTcs = []struct {
+  tcName string
+  netName string
+  ifName string
+  isErrorExpected bool
+}{
+  {"bridgedVeth", "bridged", "eth1", false},
+  {"hairpinVeth", "hairpin", "eth1", false},
+  {"missingBridge", "nobridge", "eth1", true},
+  {"ifNameCollision", "bridged", "eth2", true},
+}
+
+var addRoutedInterfaceTcs = []struct {
+  tcName string
+  address string
+  address6 string
+  expectedHostRoutes []string
+  isProxyArpExpected bool
+  isProxyNdpExpected bool
+}{
+  {"ipv4", "10.0.0.5/24", "", []string{"10.0.0.5/32"}, true, false},
+  {"ipv6", "", "2001:db8::5/64", []string{"2001:db8::5/128"}, false, true},
+  {"dualStack", "10.0.0.6/24", "2001:db8::6/64", []string{"10.0.0.6/32", "2001:db8::6/128"}, true, true},
+  {"noIp", "none", "", nil, false, false},
+}
+
+func TestAddBridgeInterface(t *testing.T) {
+  for _, tc := range addBridgeInterfaceTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      hostNs, podNs := setupVethTestNs(t)
+      defer closeTestNs(hostNs)
+      defer closeTestNs(podNs)
+      dnet := getVethNet(tc.netName)
+      ep := getVethEp(podNs, dnet, tc.ifName, "10.0.0.5/24", "")
+      err := hostNs.Do(func(ns.NetNS) error {
+        return danmep.AddBridgeInterface(dnet, ep)
+      })
+      if (err != nil) != tc.isErrorExpected {
+        t.Fatalf("Received error:%v does not match with expectation", err)
+      }
+      hostNs.Do(func(ns.NetNS) error {
+        hostVeth, err := netlink.LinkByName(danmep.GetHostVethName(ep))
+        if tc.isErrorExpected {
+          if err == nil {
+            t.Errorf("Host veth:%s of the failed interface shall be deleted", hostVeth.Attrs().Name)
+          }
+          return nil
+        }
+        if err != nil {
+          t.Errorf("Host veth of the interface cannot be found:%v", err)
+          return nil
+        }
+        bridge, _ := netlink.LinkByName(netcontrol.GetBridgeName(dnet))
+        if hostVeth.Attrs().MasterIndex != bridge.Attrs().Index || hostVeth.Attrs().MTU != bridgeMtu {
+          t.Errorf("Host veth shall be connected to bridge:%s, and inherit its MTU", bridge.Attrs().Name)
+        }
+        if hostVeth.Attrs().Flags & net.FlagUp == 0 {
+          t.Errorf("Host veth shall be UP")
+        }
+        protinfo, err := netlink.LinkGetProtinfo(hostVeth)
+        if err != nil || protinfo.Hairpin != dnet.Spec.Options.Hairpin {
+          t.Errorf("Hairpin mode of the host veth:%v does not match with the expected:%t", protinfo.Hairpin, dnet.Spec.Options.Hairpin)
+        }
+        return nil
+      })
+      if tc.isErrorExpected {
+        return
+      }
+      checkPodIface(t, podNs, ep)
+      err = danmep.DeleteBridgeInterface(ep)
+      if err != nil {
+        t.Fatalf("Interface could not be deleted:%v", err)
+      }
+      checkVethIsDeleted(t, hostNs, podNs, ep)
+    })
+  }
+}
+
+func TestAddRoutedInterface(t *testing.T) {
+  for _, tc := range addRoutedInterfaceTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      hostNs, podNs := setupVethTestNs(t)
+      defer closeTestNs(hostNs)
+      defer closeTestNs(podNs)
+      dnet := getVethNet("routed")
+      ep := getVethEp(podNs, dnet, "eth1", tc.address, tc.address6)
+      err := hostNs.Do(func(ns.NetNS) error {
+        return danmep.AddRoutedInterface(dnet, ep)
+      })
+      if err != nil {
+        t.Fatalf("Interface could not be created:%v", err)
+      }
+      hostNs.Do(func(ns.NetNS) error {
+        hostVethName := danmep.GetHostVethName(ep)
+        hostVeth, err := netlink.LinkByName(hostVethName)
+        if err != nil {
+          t.Errorf("Host veth of the interface cannot be found:%v", err)
+          return nil
+        }
+        routes, _ := netlink.RouteList(hostVeth, netlink.FAMILY_ALL)
+        var hostRoutes []string
+        for _, route := range routes {
+          if route.Dst != nil && !route.Dst.IP.IsLinkLocalUnicast() {
+            hostRoutes = append(hostRoutes, route.Dst.String())
+          }
+        }
+        if strings.Join(hostRoutes, ",") != strings.Join(tc.expectedHostRoutes, ",") {
+          t.Errorf("Host routes:%v pointing to the host veth do not match with the expected:%v", hostRoutes, tc.expectedHostRoutes)
+        }
+        if isSysctlSet("net.ipv4.conf." + hostVethName + ".proxy_arp") != tc.isProxyArpExpected {
+          t.Errorf("Proxy ARP of the host veth shall be:%t", tc.isProxyArpExpected)
+        }
+        if isSysctlSet("net.ipv6.conf." + hostVethName + ".proxy_ndp") != tc.isProxyNdpExpected {
+          t.Errorf("Proxy NDP of the host veth shall be:%t", tc.isProxyNdpExpected)
+        }
+        if tc.isProxyNdpExpected && !hasAddress(hostVeth, danmep.RoutedV6Gateway) {
+          t.Errorf("IPv6 gateway address:%s of the Pod shall be added to the host veth", danmep.RoutedV6Gateway)
+        }
+        return nil
+      })
+      checkPodIface(t, podNs, ep)
+      err = danmep.DeleteRoutedInterface(ep)
+      if err != nil {
+        t.Fatalf("Interface could not be deleted:%v", err)
+      }
+      checkVethIsDeleted(t, hostNs, podNs, ep)
+    })
+  }
+}
+
+//The host namespace contains the bridges of the networks, and the Pod namespace already has an interface named eth2
+func setupVethTestNs(t *testing.T) (ns.NetNS, ns.NetNS) {
+  hostNs, err := testutils.NewNS()
+  if err != nil {
+    t.Skipf("Network namespace cannot be created in this environment:%v", err)
+  }
+  podNs, err := testutils.NewNS()
+  if err != nil {
+    closeTestNs(hostNs)
+    t.Skipf("Network namespace cannot be created in this environment:%v", err)
+  }
+  err = hostNs.Do(func(ns.NetNS) error {
+    for _, netName := range []string{"bridged", "hairpin"} {
+      bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: netcontrol.GetBridgeName(getVethNet(netName)), MTU: bridgeMtu}}
+      err := netlink.LinkAdd(bridge)
+      if err != nil {
+        return err
+      }
+      err = netlink.LinkSetUp(bridge)
+      if err != nil {
+        return err
+      }
+    }
+    return nil
+  })
+  if err == nil {
+    err = podNs.Do(func(ns.NetNS) error {
+      return netlink.LinkAdd(&netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "eth2"}, PeerName: "eth2-peer"})
+    })
+  }
+  if err != nil {
+    closeTestNs(hostNs)
+    closeTestNs(podNs)
+    t.Fatalf("Test interfaces could not be created because:%v", err)
+  }
+  return hostNs, podNs
+}
+
+func closeTestNs(testNs ns.NetNS) {
+  testutils.UnmountNS(testNs)
+  testNs.Close()
+}
+
+func getVethNet(netName string) *danmtypes.DanmNet {
+  for index, dnet := range vethNets {
+    if dnet.ObjectMeta.Name == netName {
+      return &vethNets[index]
+    }
+  }
+  return nil
+}
+
+func getVethEp(podNs ns.NetNS, dnet *danmtypes.DanmNet, ifName, address, address6 string) *danmtypes.DanmEp {
+  host, _ := os.Hostname()
+  return &danmtypes.DanmEp {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "veth"},
+    Spec: danmtypes.DanmEpSpec {
+      EndpointID: "6f3b1c2e-8d4a-4e5f-9a7b-0c1d2e3f4a5b",
+      NetworkType: dnet.Spec.NetworkType,
+      NetworkName: dnet.ObjectMeta.Name,
+      Host: host,
+      Netns: podNs.Path(),
+      Iface: danmtypes.DanmEpIface{Name: ifName, Address: address, AddressIPv6: address6},
+    },
+  }
+}
+
+func checkPodIface(t *testing.T, podNs ns.NetNS, ep *danmtypes.DanmEp) {
+  podNs.Do(func(ns.NetNS) error {
+    iface, err := netlink.LinkByName(ep.Spec.Iface.Name)
+    if err != nil {
+      t.Errorf("Interface:%s cannot be found in the Pod:%v", ep.Spec.Iface.Name, err)
+      return nil
+    }
+    if iface.Attrs().Flags & net.FlagUp == 0 {
+      t.Errorf("Interface:%s of the Pod shall be UP", ep.Spec.Iface.Name)
+    }
+    for _, cidr := range []string{ep.Spec.Iface.Address, ep.Spec.Iface.AddressIPv6} {
+      if ip, _, err := net.ParseCIDR(cidr); err == nil && !hasAddress(iface, ip.String()) {
+        t.Errorf("Interface:%s of the Pod does not have IP:%s", ep.Spec.Iface.Name, cidr)
+      }
+    }
+    return nil
+  })
+}
+
+func checkVethIsDeleted(t *testing.T, hostNs, podNs ns.NetNS, ep *danmtypes.DanmEp) {
+  podNs.Do(func(ns.NetNS) error {
+    if _, err := netlink.LinkByName(ep.Spec.Iface.Name); err == nil {
+      t.Errorf("Deleted interface:%s is still present in the Pod", ep.Spec.Iface.Name)
+    }
+    return nil
+  })
+  hostNs.Do(func(ns.NetNS) error {
+    if _, err := netlink.LinkByName(danmep.GetHostVethName(ep)); err == nil {
+      t.Errorf("Host veth of the deleted interface is still present")
+    }
+    return nil
+  })
+}
+
+func hasAddress(iface netlink.Link, ip string) bool {
+  addrs, _ := netlink.AddrList(iface, netlink.FAMILY_ALL)
+  for _, addr := range addrs {
+    if addr.IP.Equal(net.ParseIP(ip)) {
+      return true
+    }
+  }
+  return false
+}
+
+func isSysctlSet(name string) bool {
+  value, err := sysctl.Sysctl(name)
+  return err == nil && strings.TrimSpace(value) == "1"
+}
@@ -0,0 +1,114 @@
+package hotplug_test
+
+import (
+  "errors"
+  "testing"
+  corev1 "k8s.io/api/core/v1"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  "k8s.io/client-go/tools/cache"
+  "github.com/nokia/danm/pkg/hotplug"
+)
+
+const (
+  testPodKey = "default/test-pod"
+)
+
+type hotplugCall struct {
+  oldAnnotation string
+  newAnnotation string
+}
+
+type hotplugRecorder struct {
+  calls []hotplugCall
+  numOfFailures int
+}
+
+func (recorder *hotplugRecorder) hotplug(oldPod, newPod *corev1.Pod) error {
+  recorder.calls = append(recorder.calls, hotplugCall{oldAnnotation: getAnnotation(oldPod), newAnnotation: getAnnotation(newPod)})
+  if len(recorder.calls) <= recorder.numOfFailures {
+    return errors.New("hot-plug failed")
+  }
+  return nil
+}
+
+func TestFailedHotplugIsRetried(t *testing.T) {
+  recorder := &hotplugRecorder{numOfFailures: 2}
+  indexer, podWatcher := setupPodWatcher(recorder)
+  oldPod, newPod := getTestPod("a"), getTestPod("b")
+  indexer.Add(newPod)
+  podWatcher.UpdatePod(oldPod, newPod)
+  for len(recorder.calls) < 3 {
+    podWatcher.ProcessNextPod()
+  }
+  for index, call := range recorder.calls {
+    if call.oldAnnotation != "a" || call.newAnnotation != "b" {
+      t.Errorf("Hot-plug no.:%d reconciled annotation:%s to:%s, but expected a to b", index, call.oldAnnotation, call.newAnnotation)
+    }
+  }
+  if podWatcher.Queue.Len() != 0 || podWatcher.Queue.NumRequeues(testPodKey) != 0 {
+    t.Errorf("Successfully hot-plugged Pod shall not be queued anymore")
+  }
+}
+
+func TestHotplugIsGivenUp(t *testing.T) {
+  recorder := &hotplugRecorder{numOfFailures: hotplug.MaxHotplugRetries + 1}
+  indexer, podWatcher := setupPodWatcher(recorder)
+  oldPod, newPod := getTestPod("a"), getTestPod("b")
+  indexer.Add(newPod)
+  podWatcher.UpdatePod(oldPod, newPod)
+  for len(recorder.calls) < hotplug.MaxHotplugRetries + 1 {
+    podWatcher.ProcessNextPod()
+  }
+  if podWatcher.Queue.Len() != 0 || podWatcher.Queue.NumRequeues(testPodKey) != 0 {
+    t.Errorf("Pod shall not be re-queued after:%d failed retries", hotplug.MaxHotplugRetries)
+  }
+}
+
+func TestRetryReconcilesLatestAnnotation(t *testing.T) {
+  recorder := &hotplugRecorder{numOfFailures: 1}
+  indexer, podWatcher := setupPodWatcher(recorder)
+  podA, podB, podC := getTestPod("a"), getTestPod("b"), getTestPod("c")
+  indexer.Add(podB)
+  podWatcher.UpdatePod(podA, podB)
+  podWatcher.ProcessNextPod()
+  indexer.Update(podC)
+  podWatcher.UpdatePod(podB, podC)
+  for len(recorder.calls) < 2 {
+    podWatcher.ProcessNextPod()
+  }
+  lastCall := recorder.calls[len(recorder.calls)-1]
+  if lastCall.oldAnnotation != "a" || lastCall.newAnnotation != "c" {
+    t.Errorf("Retry reconciled annotation:%s to:%s, but expected a to c", lastCall.oldAnnotation, lastCall.newAnnotation)
+  }
+}
+
+func TestDeletedPodIsNotRetried(t *testing.T) {
+  recorder := &hotplugRecorder{numOfFailures: 1}
+  indexer, podWatcher := setupPodWatcher(recorder)
+  oldPod, newPod := getTestPod("a"), getTestPod("b")
+  indexer.Add(newPod)
+  podWatcher.UpdatePod(oldPod, newPod)
+  podWatcher.ProcessNextPod()
+  indexer.Delete(newPod)
+  podWatcher.DeletePod(newPod)
+  podWatcher.ProcessNextPod()
+  if len(recorder.calls) != 1 {
+    t.Errorf("Interfaces of a deleted Pod were hot-plugged:%d times, but expected once", len(recorder.calls))
+  }
+}
+
+func setupPodWatcher(recorder *hotplugRecorder) (cache.Indexer, *hotplug.PodWatcher) {
+  indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+  return indexer, hotplug.NewPodQueue(indexer, recorder.hotplug)
+}
+
+func getTestPod(annotation string) *corev1.Pod {
+  return &corev1.Pod {
+    ObjectMeta: meta_v1.ObjectMeta{Name: "test-pod", Namespace: "default", UID: "1234", Annotations: map[string]string{"danm.k8s.io/interfaces": annotation}},
+    Status: corev1.PodStatus{Phase: corev1.PodRunning},
+  }
+}
+
+func getAnnotation(pod *corev1.Pod) string {
+  return pod.ObjectMeta.Annotations["danm.k8s.io/interfaces"]
+}
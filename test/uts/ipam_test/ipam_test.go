@@ -1,14 +1,23 @@
 package ipam_test
 
 import (
+  "errors"
+  "net"
   "os"
+  "reflect"
   "strconv"
   "testing"
   danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmfake "github.com/nokia/danm/crd/client/clientset/versioned/fake"
+  "github.com/nokia/danm/pkg/bitarray"
+  "github.com/nokia/danm/pkg/datastructs"
   "github.com/nokia/danm/pkg/ipam"
   stubs "github.com/nokia/danm/test/stubs/danm"
   "github.com/nokia/danm/test/utils"
+  k8serrors "k8s.io/apimachinery/pkg/api/errors"
   meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  "k8s.io/apimachinery/pkg/runtime"
+  k8stesting "k8s.io/client-go/testing"
 )
 
 var testNets = []danmtypes.DanmNet {
@@ -103,6 +112,23 @@ var gcTcs = []struct {
   {"dualStackGc", 12, "192.168.1.115", "2a00:8a00:a000:1193::5"},
 }
 
+var inspectTcs = []struct {
+  tcName string
+  allocatedIps []string
+  epIps []string
+  freeIpLimit int
+  expectedUsage ipam.PoolUsage
+  expectedFreeIps []string
+  expectedLeakedIps []string
+  expectedMissingIps []string
+}{
+  {"emptyPool", nil, nil, 3, ipam.PoolUsage{Used: 0, Total: 11}, []string{"192.168.1.70", "192.168.1.71", "192.168.1.72"}, nil, nil},
+  {"consistentPool", []string{"192.168.1.70", "192.168.1.72"}, []string{"192.168.1.70/26", "192.168.1.72/26"}, 2, ipam.PoolUsage{Used: 2, Total: 11}, []string{"192.168.1.71", "192.168.1.73"}, nil, nil},
+  {"leakedIp", []string{"192.168.1.70", "192.168.1.75"}, []string{"192.168.1.70/26"}, 1, ipam.PoolUsage{Used: 2, Total: 11}, []string{"192.168.1.71"}, []string{"192.168.1.75"}, nil},
+  {"missingIp", nil, []string{"192.168.1.80/26", "none", ""}, 100, ipam.PoolUsage{Used: 0, Total: 11}, []string{"192.168.1.70", "192.168.1.71", "192.168.1.72", "192.168.1.73", "192.168.1.74", "192.168.1.75", "192.168.1.76", "192.168.1.77", "192.168.1.78", "192.168.1.79", "192.168.1.80"}, nil, []string{"192.168.1.80"}},
+  {"leakedAndMissingIps", []string{"192.168.1.71"}, []string{"192.168.1.72/26", "10.0.0.1/24"}, 0, ipam.PoolUsage{Used: 1, Total: 11}, []string{}, []string{"192.168.1.71"}, []string{"192.168.1.72"}},
+}
+
 func TestReserve(t *testing.T) {
   err := utils.SetupAllocationPools(testNets)
   if err != nil {
@@ -181,6 +207,110 @@ func TestGarbageCollectIps(t *testing.T) {
   }
 }
 
+func TestInspectAllocations(t *testing.T) {
+  for _, tc := range inspectTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      dnet := createInspectedNet(tc.allocatedIps)
+      eps := make([]danmtypes.DanmEp, 0)
+      for _, ip := range tc.epIps {
+        eps = append(eps, danmtypes.DanmEp{Spec: danmtypes.DanmEpSpec{NetworkName: dnet.ObjectMeta.Name, Iface: danmtypes.DanmEpIface{Address: ip}}})
+      }
+      usage, usage6 := ipam.GetPoolUsage(&dnet)
+      if usage != tc.expectedUsage || usage6.Total != 0 {
+        t.Errorf("Pool usage:%v, IPv6 pool usage:%v does not match with the expected:%v", usage, usage6, tc.expectedUsage)
+      }
+      freeIps := ipam.GetFreeIps(&dnet, tc.freeIpLimit)
+      if !reflect.DeepEqual(freeIps, tc.expectedFreeIps) {
+        t.Errorf("Free IPs:%v do not match with the expected:%v", freeIps, tc.expectedFreeIps)
+      }
+      rebuiltAlloc, rebuiltAlloc6 := ipam.RebuildAllocations(&dnet, eps)
+      if rebuiltAlloc6 != "" {
+        t.Errorf("IPv6 allocation:%s was rebuilt for an IPv4 only network", rebuiltAlloc6)
+      }
+      leaked, missing := ipam.DiffAllocations(dnet.Spec.Options.Alloc, rebuiltAlloc, dnet.Spec.Options.Cidr)
+      if !reflect.DeepEqual(leaked, tc.expectedLeakedIps) || !reflect.DeepEqual(missing, tc.expectedMissingIps) {
+        t.Errorf("Leaked IPs:%v, and missing IPs:%v do not match with the expected:%v, and %v", leaked, missing, tc.expectedLeakedIps, tc.expectedMissingIps)
+      }
+    })
+  }
+}
+
+func TestRepairAllocations(t *testing.T) {
+  dnet := createInspectedNet([]string{"192.168.1.71", "192.168.1.75"})
+  eps := []danmtypes.DanmEp{
+    danmtypes.DanmEp{Spec: danmtypes.DanmEpSpec{NetworkName: dnet.ObjectMeta.Name, Iface: danmtypes.DanmEpIface{Address: "192.168.1.71/26"}}},
+    danmtypes.DanmEp{Spec: danmtypes.DanmEpSpec{NetworkName: dnet.ObjectMeta.Name, Iface: danmtypes.DanmEpIface{Address: "192.168.1.72/26"}}},
+  }
+  //Another CNI call reserves an IP after the network was read, it shall survive the repair
+  concurrentNet := createInspectedNet([]string{"192.168.1.71", "192.168.1.75", "192.168.1.78"})
+  concurrentNet.ObjectMeta.ResourceVersion = "2"
+  client := danmfake.NewSimpleClientset(&concurrentNet)
+  //The fake client does not implement optimistic locking on its own
+  client.PrependReactor("update", "danmnets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+    updatedNet := action.(k8stesting.UpdateAction).GetObject().(*danmtypes.DanmNet)
+    currentNet, err := client.Tracker().Get(action.GetResource(), action.GetNamespace(), updatedNet.ObjectMeta.Name)
+    if err != nil {
+      return true, nil, err
+    }
+    if currentNet.(*danmtypes.DanmNet).ObjectMeta.ResourceVersion != updatedNet.ObjectMeta.ResourceVersion {
+      return true, nil, k8serrors.NewConflict(action.GetResource().GroupResource(), updatedNet.ObjectMeta.Name, errors.New(datastructs.OptimisticLockErrorMsg))
+    }
+    return false, nil, nil
+  })
+  err := ipam.RepairAllocations(client, dnet, eps, []string{"192.168.1.75"})
+  if err != nil {
+    t.Fatalf("Allocations could not be repaired because:%v", err)
+  }
+  repairedNet, err := client.DanmV1().DanmNets(dnet.ObjectMeta.Namespace).Get(dnet.ObjectMeta.Name, meta_v1.GetOptions{})
+  if err != nil {
+    t.Fatalf("Repaired network could not be read because:%v", err)
+  }
+  expectedNet := createInspectedNet([]string{"192.168.1.71", "192.168.1.72", "192.168.1.78"})
+  if repairedNet.Spec.Options.Alloc != expectedNet.Spec.Options.Alloc {
+    t.Errorf("Repaired allocation:%v does not match with the expected:%v", ipam.GetFreeIps(repairedNet, 11), ipam.GetFreeIps(&expectedNet, 11))
+  }
+}
+
+func TestRepairAllocationsKeepsUnconfirmedLeaks(t *testing.T) {
+  dnet := createInspectedNet([]string{"192.168.1.71", "192.168.1.75", "192.168.1.76"})
+  eps := []danmtypes.DanmEp{
+    danmtypes.DanmEp{Spec: danmtypes.DanmEpSpec{NetworkName: dnet.ObjectMeta.Name, Iface: danmtypes.DanmEpIface{Address: "192.168.1.71/26"}}},
+  }
+  client := danmfake.NewSimpleClientset(&dnet)
+  //The DanmEp owning 192.168.1.76 might still be under creation, only the confirmed leak shall be freed
+  err := ipam.RepairAllocations(client, dnet, eps, []string{"192.168.1.75"})
+  if err != nil {
+    t.Fatalf("Allocations could not be repaired because:%v", err)
+  }
+  repairedNet, err := client.DanmV1().DanmNets(dnet.ObjectMeta.Namespace).Get(dnet.ObjectMeta.Name, meta_v1.GetOptions{})
+  if err != nil {
+    t.Fatalf("Repaired network could not be read because:%v", err)
+  }
+  expectedNet := createInspectedNet([]string{"192.168.1.71", "192.168.1.76"})
+  if repairedNet.Spec.Options.Alloc != expectedNet.Spec.Options.Alloc {
+    t.Errorf("Repaired allocation:%v does not match with the expected:%v", ipam.GetFreeIps(repairedNet, 11), ipam.GetFreeIps(&expectedNet, 11))
+  }
+}
+
+func createInspectedNet(allocatedIps []string) danmtypes.DanmNet {
+  dnet := danmtypes.DanmNet {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "inspected", Namespace: "default", ResourceVersion: "1"},
+    Spec: danmtypes.DanmNetSpec{NetworkID: "inspected", Options: danmtypes.DanmNetOption{Cidr: "192.168.1.64/26", Pool: danmtypes.IpPool{Start: "192.168.1.70", End: "192.168.1.80"}}},
+  }
+  utils.InitAllocPool(&dnet)
+  alloc := bitarray.NewBitArrayFromBase64(dnet.Spec.Options.Alloc)
+  for _, ip := range allocatedIps {
+    alloc.Set(ipam.GetIndexOfIp(net.ParseIP(ip), getInspectedSubnet()))
+  }
+  dnet.Spec.Options.Alloc = alloc.Encode()
+  return dnet
+}
+
+func getInspectedSubnet() *net.IPNet {
+  _, subnet, _ := net.ParseCIDR("192.168.1.64/26")
+  return subnet
+}
+
 func TestMain(m *testing.M) {
   code := m.Run()
   os.Exit(code)
@@ -0,0 +1,163 @@
+package logger_test
+
+import (
+  "encoding/json"
+  "io/ioutil"
+  "log"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+  "github.com/nokia/danm/pkg/logger"
+)
+
+var configureTcs = []struct {
+  tcName string
+  config logger.Config
+  isErrorExpected bool
+}{
+  {"defaults", logger.Config{}, false},
+  {"json", logger.Config{Format: logger.JsonFormat, Level: logger.DebugLevel}, false},
+  {"invalidFormat", logger.Config{Format: "xml"}, true},
+  {"invalidLevel", logger.Config{Level: "verbose"}, true},
+  {"invalidFile", logger.Config{File: "/nonexistent/dir/danm.log"}, true},
+}
+
+var formatTcs = []struct {
+  tcName string
+  format string
+  level string
+  logFunc func()
+  expectedEntries []map[string]string
+}{
+  {"logfmtWithFields", logger.LogfmtFormat, logger.InfoLevel, func() {
+      logger.WithFields(logger.Fields{logger.NetworkField: "internal", logger.EndpointIdField: "1234"}).Error("interface could not be created: no free IP")
+    }, []map[string]string{{"level": "error", "msg": "interface could not be created: no free IP", "network": "internal", "endpointid": "1234"}},
+  },
+  {"jsonWithFields", logger.JsonFormat, logger.InfoLevel, func() {
+      logger.WithFields(logger.Fields{logger.NetworkField: "internal"}).WithFields(logger.Fields{logger.IfaceField: "eth1"}).Warning("routes are missing")
+    }, []map[string]string{{"level": "warning", "msg": "routes are missing", "network": "internal", "iface": "eth1"}},
+  },
+  {"levelFiltering", logger.LogfmtFormat, logger.WarningLevel, func() {
+      logger.Debug("debug")
+      logger.Info("info")
+      logger.Warning("warning")
+    }, []map[string]string{{"level": "warning", "msg": "warning"}},
+  },
+  {"stdLogPrefixes", logger.JsonFormat, logger.DebugLevel, func() {
+      log.Println("ERROR: ADD: something failed")
+      log.Println("WARNING:something is odd")
+      log.Println("no prefix")
+    }, []map[string]string{{"level": "error", "msg": "ADD: something failed"}, {"level": "warning", "msg": "something is odd"}, {"level": "info", "msg": "no prefix"}},
+  },
+}
+
+func TestConfigure(t *testing.T) {
+  for _, tc := range configureTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      err := logger.Configure(tc.config)
+      if (err != nil) != tc.isErrorExpected {
+        t.Errorf("Received error:%v does not match with expectation", err)
+      }
+    })
+  }
+}
+
+func TestFormat(t *testing.T) {
+  tmpDir, err := ioutil.TempDir("", "logger_test")
+  if err != nil {
+    t.Fatalf("Temporary directory could not be created:%v", err)
+  }
+  defer os.RemoveAll(tmpDir)
+  for _, tc := range formatTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      logFile := filepath.Join(tmpDir, tc.tcName + ".log")
+      err := logger.Configure(logger.Config{File: logFile, Format: tc.format, Level: tc.level})
+      if err != nil {
+        t.Fatalf("Logger could not be configured:%v", err)
+      }
+      logger.SetDefaultFields(logger.Fields{logger.PodField: "test-pod"})
+      tc.logFunc()
+      content, err := ioutil.ReadFile(logFile)
+      if err != nil {
+        t.Fatalf("Log file could not be read:%v", err)
+      }
+      lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+      if len(lines) != len(tc.expectedEntries) {
+        t.Fatalf("Number of logged entries:%d does not match with the expected:%d, log:%s", len(lines), len(tc.expectedEntries), string(content))
+      }
+      for index, line := range lines {
+        entry := parseEntry(t, tc.format, line)
+        if entry["time"] == "" || entry[logger.PodField] != "test-pod" {
+          t.Errorf("Entry:%s lacks the time, or the default fields", line)
+        }
+        for key, value := range tc.expectedEntries[index] {
+          if entry[key] != value {
+            t.Errorf("Field:%s of entry:%s is:%s, but expected:%s", key, line, entry[key], value)
+          }
+        }
+      }
+    })
+  }
+}
+
+func TestRotation(t *testing.T) {
+  tmpDir, err := ioutil.TempDir("", "logger_test")
+  if err != nil {
+    t.Fatalf("Temporary directory could not be created:%v", err)
+  }
+  defer os.RemoveAll(tmpDir)
+  logFile := filepath.Join(tmpDir, "danm.log")
+  err = logger.Configure(logger.Config{File: logFile, MaxSize: 1, MaxBackups: 2})
+  if err != nil {
+    t.Fatalf("Logger could not be configured:%v", err)
+  }
+  msg := strings.Repeat("a", 1024)
+  for i := 0; i < 3 * 1024; i++ {
+    logger.Info(msg)
+  }
+  for _, fileName := range []string{logFile, logFile + ".1", logFile + ".2"} {
+    info, err := os.Stat(fileName)
+    if err != nil {
+      t.Fatalf("Log file:%s does not exist:%v", fileName, err)
+    }
+    if info.Size() > 1024 * 1024 {
+      t.Errorf("Log file:%s is bigger than the maximum size:%d", fileName, info.Size())
+    }
+  }
+  if _, err = os.Stat(logFile + ".3"); err == nil {
+    t.Errorf("More backups are kept than configured")
+  }
+}
+
+func parseEntry(t *testing.T, format, line string) map[string]string {
+  entry := map[string]string{}
+  if format == logger.JsonFormat {
+    err := json.Unmarshal([]byte(line), &entry)
+    if err != nil {
+      t.Fatalf("Entry:%s is not valid JSON:%v", line, err)
+    }
+    return entry
+  }
+  //Good enough for the tests: the quoted values of the test entries do not contain quotes, or equal signs
+  for len(line) > 0 {
+    keyEnd := strings.Index(line, "=")
+    if keyEnd < 0 {
+      t.Fatalf("Entry:%s is not valid logfmt", line)
+    }
+    key := line[:keyEnd]
+    line = line[keyEnd+1:]
+    var value string
+    if strings.HasPrefix(line, "\"") {
+      valueEnd := strings.Index(line[1:], "\"") + 1
+      value, line = line[1:valueEnd], line[valueEnd+1:]
+    } else if valueEnd := strings.Index(line, " "); valueEnd >= 0 {
+      value, line = line[:valueEnd], line[valueEnd:]
+    } else {
+      value, line = line, ""
+    }
+    entry[key] = value
+    line = strings.TrimPrefix(line, " ")
+  }
+  return entry
+}
@@ -0,0 +1,479 @@
+package metacni_test
+
+import (
+  "encoding/json"
+  "io/ioutil"
+  "net"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+  "github.com/containernetworking/cni/pkg/skel"
+  "github.com/containernetworking/plugins/pkg/ns"
+  "github.com/containernetworking/plugins/pkg/testutils"
+  "github.com/vishvananda/netlink"
+  corev1 "k8s.io/api/core/v1"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  "k8s.io/apimachinery/pkg/types"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  "github.com/nokia/danm/pkg/danmep"
+  "github.com/nokia/danm/pkg/metacni"
+  httpstub "github.com/nokia/danm/test/stubs/http"
+  "github.com/nokia/danm/test/utils"
+)
+
+const (
+  testNamespace = "default"
+  testPodName = "test-pod"
+  testCid = "1234"
+  fakeCniType = "fakecni"
+  podPath = "/api/v1/namespaces/" + testNamespace + "/pods/"
+  danmNetPath = "/apis/danm.k8s.io/v1/namespaces/" + testNamespace + "/danmnets/"
+  danmEpPath = "/apis/danm.k8s.io/v1/namespaces/" + testNamespace + "/danmeps/"
+  eventPath = "/api/v1/namespaces/" + testNamespace + "/events/"
+)
+
+//Hot-plug does not configure logging on its own, so every test logs into the same file
+var logFile = filepath.Join(os.TempDir(), "danm_metacni_test.log")
+
+var extractConnectionsTcs = []struct {
+  tcName string
+  annotation string
+  expectedNetworks []string
+  isErrorExpected bool
+}{
+  {"noPrimary", `[{"network":"management"},{"network":"external"}]`, []string{"management", "external"}, false},
+  {"firstIsPrimary", `[{"network":"management","primary":true},{"network":"external"}]`, []string{"management", "external"}, false},
+  {"lastIsPrimary", `[{"network":"external"},{"network":"internal"},{"clusterNetwork":"management","primary":true}]`, []string{"management", "external", "internal"}, false},
+  {"middleIsPrimary", `[{"network":"external"},{"tenantNetwork":"management","primary":true},{"network":"internal"}]`, []string{"management", "external", "internal"}, false},
+  {"multiplePrimaries", `[{"network":"external","primary":true},{"network":"management","primary":true}]`, nil, true},
+  {"customIfNames", `[{"network":"management"},{"network":"signalling","ifName":"sig0"},{"network":"oam","ifName":"oam0"}]`, []string{"management", "signalling", "oam"}, false},
+  {"customIfNameOnMovedPrimary", `[{"network":"signalling","ifName":"sig0"},{"network":"management","primary":true,"ifName":"eth0"}]`, []string{"management", "signalling"}, false},
+  {"tooLongIfName", `[{"network":"management"},{"network":"signalling","ifName":"signalling-iface0"}]`, nil, true},
+  {"ifNameWithSlash", `[{"network":"management"},{"network":"signalling","ifName":"sig/0"}]`, nil, true},
+  {"dotIfName", `[{"network":"management"},{"network":"signalling","ifName":".."}]`, nil, true},
+  {"collidingIfNames", `[{"network":"management"},{"network":"signalling","ifName":"sig0"},{"network":"oam","ifName":"sig0"}]`, nil, true},
+  {"eth0OnSecondary", `[{"network":"management"},{"network":"signalling","ifName":"eth0"}]`, nil, true},
+  {"renamedPrimary", `[{"network":"management","ifName":"oam0"},{"network":"signalling"}]`, nil, true},
+  {"sysctls", `[{"network":"management","sysctls":{"ipv4.rp_filter":"2","ipv4.arp_ignore":"1"}}]`, []string{"management"}, false},
+  {"disallowedSysctl", `[{"network":"management","sysctls":{"ipv4.ip_forward":"1"}}]`, nil, true},
+  {"bandwidth", `[{"network":"management","bandwidth":{"ingressRate":100000000,"ingressBurst":1000000}}]`, []string{"management"}, false},
+  {"bandwidthWithoutBurst", `[{"network":"management","bandwidth":{"egressRate":100000000}}]`, nil, true},
+  {"renamedExplicitPrimary", `[{"network":"signalling"},{"network":"management","primary":true,"ifName":"oam0"}]`, nil, true},
+}
+
+var hotplugTcs = []struct {
+  tcName string
+  oldAnnotation string
+  newAnnotation string
+  expectedDeletedEps []string
+  expectedErrors []string
+}{
+  {"noChange", `[{"network":"management"},{"network":"external"}]`, `[{"network":"management"},{"network":"external"}]`, nil, nil},
+  {"appendedConnection", `[{"network":"management"}]`, `[{"network":"management"},{"network":"vlan100","ifName":"vlan100"}]`, nil, []string{"connection no.:1 due to"}},
+  {"removedConnection", `[{"network":"management"},{"network":"external"},{"network":"internal"}]`, `[{"network":"management"},{"network":"internal"}]`, []string{"ep-eth1"}, nil},
+  {"changedConnection", `[{"network":"management"},{"network":"external","ip":"dynamic"}]`, `[{"network":"management"},{"network":"external","ip":"none"}]`, []string{"ep-eth1"}, []string{"Post-processing failed for interface:eth1"}},
+  {"duplicatedConnection", `[{"network":"management"},{"network":"external"}]`, `[{"network":"management"},{"network":"external"},{"network":"external"}]`, nil, []string{"connection no.:2 collides"}},
+  {"reorderedConnections", `[{"network":"management"},{"network":"external"}]`, `[{"network":"external"},{"network":"management"}]`, nil, nil},
+  {"movedPrimary", `[{"network":"management"},{"network":"external"}]`, `[{"network":"external"},{"network":"management","primary":true}]`, nil, []string{"primary network connection"}},
+  {"defaultNetworkOnly", ``, `[{"network":"management"}]`, nil, []string{"default network only"}},
+  {"invalidAnnotation", `[{"network":"management"}]`, `[{"network":"management"},{"network":"external","ifName":"eth0"}]`, nil, []string{"DANM annotation is invalid"}},
+}
+
+var removedConnectionTcs = []struct {
+  tcName string
+  oldAnnotation string
+  expectedDeletedEps []string
+  isErrorExpected bool
+}{
+  {"onlyInterfaceOfNetwork", `[{"network":"management"},{"network":"external"}]`, []string{"ep-ext0"}, false},
+  {"explicitIfName", `[{"network":"management"},{"network":"internal","ifName":"int1"}]`, []string{"ep-int1"}, false},
+  {"defaultIfName", `[{"network":"management"},{"network":"storage"},{"network":"internal"}]`, []string{"ep-eth2"}, false},
+  {"ambiguousInterfaces", `[{"network":"management"},{"network":"internal"}]`, nil, true},
+  {"otherApiType", `[{"network":"management"},{"tenantNetwork":"external"}]`, nil, false},
+  {"primaryIsNeverRemoved", `[{"network":"management"},{"network":"management"}]`, nil, false},
+}
+
+var routeNet = danmtypes.DanmNet {
+  ObjectMeta: meta_v1.ObjectMeta{Name: "routes"},
+  Spec: danmtypes.DanmNetSpec{NetworkID: "routes", NetworkType: fakeCniType, Options: danmtypes.DanmNetOption{
+    Cidr: "10.0.0.0/24",
+    Routes: map[string]string{"10.10.0.0/24": "10.0.0.1", "10.20.0.0/24": "10.99.0.1"},
+  }},
+}
+
+type testEnv struct {
+  stub *httpstub.ApiServerStub
+  dir string
+  netConf []byte
+}
+
+func TestCreateInterfacesParsesAnnotation(t *testing.T) {
+  env := setupTestEnv(t)
+  defer env.close()
+  podNs := setupPodNs(t)
+  defer closeNs(podNs)
+  for _, tc := range extractConnectionsTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      env.stub.AddObject(podPath + testPodName, getTestPod(testPodName, "1", tc.annotation))
+      env.stub.ResetRequests()
+      err := metacni.CreateInterfaces(env.getCmdArgs(testPodName, podNs))
+      if err == nil {
+        t.Fatalf("CNI ADD shall fail, because none of the requested networks exist")
+      }
+      if strings.Contains(err.Error(), "DANM annotation cannot be parsed") != tc.isErrorExpected {
+        t.Fatalf("Received error:%v does not match with expectation", err)
+      }
+      requestedNetworks := env.stub.GetRequestedNames("GET", "danmnets", "tenantnetworks", "clusternetworks")
+      if len(requestedNetworks) != len(tc.expectedNetworks) {
+        t.Fatalf("Requested networks:%v do not match with the expected:%v", requestedNetworks, tc.expectedNetworks)
+      }
+      for index, networkName := range requestedNetworks {
+        if networkName != tc.expectedNetworks[index] {
+          t.Errorf("Connection no.:%d references network:%s, but expected:%s", index, networkName, tc.expectedNetworks[index])
+        }
+      }
+    })
+  }
+}
+
+func TestLenientRouteFailureKeepsInterface(t *testing.T) {
+  env := setupTestEnv(t)
+  defer env.close()
+  podNs := setupPodNs(t)
+  defer closeNs(podNs)
+  lenientNet := routeNet.DeepCopy()
+  lenientNet.Spec.Options.LenientRoutes = true
+  env.addNetwork(t, lenientNet)
+  env.stub.AddObject(podPath + testPodName, getTestPod(testPodName, "2", `[{"network":"routes","ip":"10.0.0.2/24"}]`))
+  err := metacni.CreateInterfaces(env.getCmdArgs(testPodName, podNs))
+  if err != nil {
+    t.Fatalf("CNI ADD shall tolerate the failed IP route of a lenient network, but failed with:%v", err)
+  }
+  epNames := env.stub.GetObjectNames("danmeps")
+  if len(epNames) != 1 {
+    t.Fatalf("Exactly one DanmEp shall be kept, but found:%v", epNames)
+  }
+  var ep danmtypes.DanmEp
+  env.stub.GetObject(danmEpPath + epNames[0], &ep)
+  if ep.Status.FailedStep != danmep.PostProcessingStep {
+    t.Errorf("Failed step:%s recorded into the status of the DanmEp does not match with the expected:%s", ep.Status.FailedStep, danmep.PostProcessingStep)
+  }
+  if len(ep.Spec.Iface.Routes) != 1 || ep.Spec.Iface.Routes[0].Destination != "10.10.0.0/24" {
+    t.Errorf("Only the installed IP route shall be recorded, but found:%v", ep.Spec.Iface.Routes)
+  }
+  if !env.isEventRecorded("RouteInstallationFailed") {
+    t.Errorf("Failed IP route shall be reported in a RouteInstallationFailed Event of the Pod")
+  }
+  if !isRouteInstalled(t, podNs, "10.10.0.0/24") {
+    t.Errorf("Successfully installed IP route shall be kept in the Pod")
+  }
+}
+
+func TestStrictRouteFailureRollsBackInterface(t *testing.T) {
+  env := setupTestEnv(t)
+  defer env.close()
+  podNs := setupPodNs(t)
+  defer closeNs(podNs)
+  strictNet := routeNet.DeepCopy()
+  env.addNetwork(t, strictNet)
+  env.stub.AddObject(podPath + testPodName, getTestPod(testPodName, "3", `[{"network":"routes","ip":"10.0.0.2/24"}]`))
+  err := metacni.CreateInterfaces(env.getCmdArgs(testPodName, podNs))
+  if err == nil || !strings.Contains(err.Error(), "Post-processing failed") {
+    t.Fatalf("CNI ADD shall fail on the failed IP route of a strict network, but returned:%v", err)
+  }
+  if epNames := env.stub.GetObjectNames("danmeps"); len(epNames) != 0 {
+    t.Errorf("DanmEps:%v of the failed interface shall be deleted", epNames)
+  }
+  var dnet danmtypes.DanmNet
+  env.stub.GetObject(danmNetPath + strictNet.ObjectMeta.Name, &dnet)
+  if dnet.Spec.Options.Alloc != strictNet.Spec.Options.Alloc {
+    t.Errorf("IP of the failed interface shall be freed in network:%s", dnet.ObjectMeta.Name)
+  }
+  if !env.isEventRecorded("InterfaceSetupFailed") {
+    t.Errorf("Failed post-processing shall be reported in an InterfaceSetupFailed Event of the Pod")
+  }
+  if isRouteInstalled(t, podNs, "10.10.0.0/24") {
+    t.Errorf("Already installed IP route of the failed interface shall be removed from the Pod")
+  }
+}
+
+func TestHotplugInterfaces(t *testing.T) {
+  env := setupTestEnv(t)
+  defer env.close()
+  for _, netName := range []string{"management", "external", "internal"} {
+    env.addNetwork(t, &danmtypes.DanmNet{ObjectMeta: meta_v1.ObjectMeta{Name: netName}, Spec: danmtypes.DanmNetSpec{NetworkID: netName, NetworkType: fakeCniType}})
+  }
+  for _, tc := range hotplugTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      oldPod, newPod := getTestPod(testPodName, "4", tc.oldAnnotation), getTestPod(testPodName, "4", tc.newAnnotation)
+      env.addEps(t, newPod, []danmtypes.DanmEpSpec {
+        danmtypes.DanmEpSpec{NetworkName: "management", ApiType: "DanmNet", Iface: danmtypes.DanmEpIface{Name: "eth0"}},
+        danmtypes.DanmEpSpec{NetworkName: "external", ApiType: "DanmNet", Iface: danmtypes.DanmEpIface{Name: "eth1"}},
+        danmtypes.DanmEpSpec{NetworkName: "internal", ApiType: "DanmNet", Iface: danmtypes.DanmEpIface{Name: "eth2"}},
+      })
+      err := metacni.HotplugInterfaces(oldPod, newPod)
+      if (err != nil) != (len(tc.expectedErrors) > 0) {
+        t.Fatalf("Received error:%v does not match with expectation", err)
+      }
+      for _, expectedError := range tc.expectedErrors {
+        if !strings.Contains(err.Error(), expectedError) {
+          t.Errorf("Received error:%v does not mention:%s", err, expectedError)
+        }
+      }
+      deletedEps := env.getDeletedEps()
+      if !areNamesEqual(deletedEps, tc.expectedDeletedEps) {
+        t.Errorf("Deleted DanmEps:%v do not match with the expected:%v", deletedEps, tc.expectedDeletedEps)
+      }
+    })
+  }
+}
+
+func TestHotplugRemovesEpOfConnection(t *testing.T) {
+  env := setupTestEnv(t)
+  defer env.close()
+  for _, netName := range []string{"management", "external", "internal"} {
+    env.addNetwork(t, &danmtypes.DanmNet{ObjectMeta: meta_v1.ObjectMeta{Name: netName}, Spec: danmtypes.DanmNetSpec{NetworkID: netName, NetworkType: fakeCniType}})
+  }
+  for _, tc := range removedConnectionTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      oldPod, newPod := getTestPod(testPodName, "5", tc.oldAnnotation), getTestPod(testPodName, "5", `[{"network":"management"}]`)
+      env.addEps(t, newPod, []danmtypes.DanmEpSpec {
+        danmtypes.DanmEpSpec{NetworkName: "management", ApiType: "DanmNet", Iface: danmtypes.DanmEpIface{Name: "eth0"}},
+        danmtypes.DanmEpSpec{NetworkName: "external", Iface: danmtypes.DanmEpIface{Name: "ext0"}},
+        danmtypes.DanmEpSpec{NetworkName: "internal", ApiType: "DanmNet", Iface: danmtypes.DanmEpIface{Name: "int1"}},
+        danmtypes.DanmEpSpec{NetworkName: "internal", ApiType: "DanmNet", Iface: danmtypes.DanmEpIface{Name: "eth2"}},
+      })
+      err := metacni.HotplugInterfaces(oldPod, newPod)
+      if (err != nil) != tc.isErrorExpected {
+        t.Fatalf("Received error:%v does not match with expectation", err)
+      }
+      deletedEps := env.getDeletedEps()
+      if !areNamesEqual(deletedEps, tc.expectedDeletedEps) {
+        t.Errorf("Deleted DanmEps:%v do not match with the expected:%v", deletedEps, tc.expectedDeletedEps)
+      }
+    })
+  }
+}
+
+func TestRepeatedFailureIsRecordedOnce(t *testing.T) {
+  env := setupTestEnv(t)
+  defer env.close()
+  podNs := setupPodNs(t)
+  defer closeNs(podNs)
+  pod := getTestPod(testPodName, "6", `[{"network":"external","primary":true},{"network":"management","primary":true}]`)
+  env.stub.AddObject(podPath + testPodName, pod)
+  for i := 0; i < 2; i++ {
+    metacni.CreateInterfaces(env.getCmdArgs(testPodName, podNs))
+  }
+  pod = getTestPod(testPodName, "6", `[{"network":"external"}]`)
+  env.stub.AddObject(podPath + testPodName, pod)
+  metacni.CreateInterfaces(env.getCmdArgs(testPodName, podNs))
+  eventNames := env.stub.GetObjectNames("events")
+  if len(eventNames) != 2 {
+    t.Fatalf("Number of recorded Events:%d does not match with the expected:2", len(eventNames))
+  }
+  for _, eventName := range eventNames {
+    var event corev1.Event
+    env.stub.GetObject(eventPath + eventName, &event)
+    if event.Count != 1 || event.InvolvedObject.UID != pod.ObjectMeta.UID || event.Type != corev1.EventTypeWarning {
+      t.Errorf("Recorded Event:%v is not a Warning about the Pod seen once", event)
+    }
+  }
+}
+
+func TestEventNamesOfLongPodName(t *testing.T) {
+  env := setupTestEnv(t)
+  defer env.close()
+  podNs := setupPodNs(t)
+  defer closeNs(podNs)
+  podName := strings.Repeat("a", 253)
+  for _, annotation := range []string{`[{"network":"external","ifName":"eth0/"}]`, `[{"network":"external","sysctls":{"ipv4.ip_forward":"1"}}]`} {
+    env.stub.AddObject(podPath + podName, getTestPod(podName, "7", annotation))
+    metacni.CreateInterfaces(env.getCmdArgs(podName, podNs))
+  }
+  eventNames := env.stub.GetObjectNames("events")
+  if len(eventNames) != 2 {
+    t.Fatalf("Different Events of the same Pod shall not have the same name, but recorded Events are:%v", eventNames)
+  }
+  for _, eventName := range eventNames {
+    if len(eventName) > 253 {
+      t.Errorf("Event name length:%d is over the limit", len(eventName))
+    }
+  }
+}
+
+//The environment contains an API server stub, a kubeconfig pointing to it, and a static CNI plugin which always succeeds
+func setupTestEnv(t *testing.T) *testEnv {
+  dir, err := ioutil.TempDir("", "metacni")
+  if err != nil {
+    t.Fatalf("Test directory could not be created:%v", err)
+  }
+  env := &testEnv{stub: httpstub.NewApiServerStub(), dir: dir}
+  kubeconfig := filepath.Join(dir, "kubeconfig")
+  err = env.stub.WriteKubeconfig(kubeconfig)
+  if err != nil {
+    env.close()
+    t.Fatalf("Kubeconfig could not be written:%v", err)
+  }
+  err = ioutil.WriteFile(filepath.Join(dir, fakeCniType), []byte("#!/bin/sh\nexit 0\n"), 0700)
+  if err != nil {
+    env.close()
+    t.Fatalf("Fake CNI plugin could not be written:%v", err)
+  }
+  os.Setenv("CNI_PATH", dir)
+  netConf := map[string]interface{} {
+    "cniVersion": "0.3.1",
+    "name": "meta_cni",
+    "type": "danm",
+    "kubeconfig": kubeconfig,
+    "cniDir": dir,
+    "logging": map[string]string{"file": logFile},
+  }
+  env.netConf, _ = json.Marshal(netConf)
+  err = metacni.LoadNetConf(env.netConf)
+  if err != nil {
+    env.close()
+    t.Fatalf("DANM CNI config could not be loaded:%v", err)
+  }
+  return env
+}
+
+func (env *testEnv) close() {
+  env.stub.Close()
+  os.RemoveAll(env.dir)
+}
+
+func (env *testEnv) getCmdArgs(podName string, podNs ns.NetNS) *skel.CmdArgs {
+  return &skel.CmdArgs {
+    ContainerID: testCid,
+    Netns: podNs.Path(),
+    IfName: "eth0",
+    Args: "IgnoreUnknown=1;K8S_POD_NAMESPACE=" + testNamespace + ";K8S_POD_NAME=" + podName + ";K8S_POD_INFRA_CONTAINER_ID=" + testCid,
+    StdinData: env.netConf,
+  }
+}
+
+//Every network is handled by the fake CNI plugin with a static CNI config
+func (env *testEnv) addNetwork(t *testing.T, dnet *danmtypes.DanmNet) {
+  if dnet.Spec.Options.Cidr != "" {
+    utils.InitAllocPool(dnet)
+  }
+  rawConfig := `{"cniVersion":"0.3.1","name":"` + dnet.Spec.NetworkID + `","type":"` + fakeCniType + `"}`
+  err := ioutil.WriteFile(filepath.Join(env.dir, dnet.Spec.NetworkID + ".conf"), []byte(rawConfig), 0600)
+  if err != nil {
+    t.Fatalf("CNI config of network:%s could not be written:%v", dnet.ObjectMeta.Name, err)
+  }
+  env.stub.AddObject(danmNetPath + dnet.ObjectMeta.Name, dnet)
+}
+
+//DanmEps are named after their interface, and replace the ones left behind by the previous test case
+func (env *testEnv) addEps(t *testing.T, pod *corev1.Pod, epSpecs []danmtypes.DanmEpSpec) {
+  host, _ := os.Hostname()
+  for _, epSpec := range epSpecs {
+    epSpec.Pod, epSpec.PodUID, epSpec.CID, epSpec.Host = pod.ObjectMeta.Name, pod.ObjectMeta.UID, testCid, host
+    epSpec.Netns, epSpec.NetworkType = "/var/run/netns/non-existent", fakeCniType
+    ep := danmtypes.DanmEp{ObjectMeta: meta_v1.ObjectMeta{Name: "ep-" + epSpec.Iface.Name, Namespace: testNamespace}, Spec: epSpec}
+    err := env.stub.AddObject(danmEpPath + ep.ObjectMeta.Name, ep)
+    if err != nil {
+      t.Fatalf("DanmEp:%s could not be added:%v", ep.ObjectMeta.Name, err)
+    }
+  }
+  env.stub.ResetRequests()
+}
+
+//DanmEps of re-created connections are rolled back, because their network namespace does not exist, so only the pre-existing ones are considered
+func (env *testEnv) getDeletedEps() []string {
+  var deletedEps []string
+  for _, epName := range env.stub.GetRequestedNames("DELETE", "danmeps") {
+    if strings.HasPrefix(epName, "ep-") {
+      deletedEps = append(deletedEps, epName)
+    }
+  }
+  return deletedEps
+}
+
+func (env *testEnv) isEventRecorded(reason string) bool {
+  for _, eventName := range env.stub.GetObjectNames("events") {
+    var event corev1.Event
+    env.stub.GetObject(eventPath + eventName, &event)
+    if event.Reason == reason {
+      return true
+    }
+  }
+  return false
+}
+
+func getTestPod(name, uid, annotation string) *corev1.Pod {
+  pod := &corev1.Pod {
+    ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: testNamespace, UID: types.UID(uid)},
+    Status: corev1.PodStatus{Phase: corev1.PodRunning},
+  }
+  if annotation != "" {
+    pod.ObjectMeta.Annotations = map[string]string{"danm.k8s.io/interfaces": annotation}
+  }
+  return pod
+}
+
+//The fake CNI plugin does not create anything, so the interface of the Pod is created in advance
+func setupPodNs(t *testing.T) ns.NetNS {
+  podNs, err := testutils.NewNS()
+  if err != nil {
+    t.Skipf("Network namespace cannot be created in this environment:%v", err)
+  }
+  err = podNs.Do(func(ns.NetNS) error {
+    err := netlink.LinkAdd(&netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "eth0"}, PeerName: "eth0-peer"})
+    if err != nil {
+      return err
+    }
+    for _, linkName := range []string{"eth0-peer", "eth0"} {
+      link, err := netlink.LinkByName(linkName)
+      if err != nil {
+        return err
+      }
+      err = netlink.LinkSetUp(link)
+      if err != nil {
+        return err
+      }
+    }
+    link, _ := netlink.LinkByName("eth0")
+    addr, _ := netlink.ParseAddr("10.0.0.2/24")
+    return netlink.AddrAdd(link, addr)
+  })
+  if err != nil {
+    closeNs(podNs)
+    t.Fatalf("Interface of the Pod could not be created:%v", err)
+  }
+  return podNs
+}
+
+func closeNs(testNs ns.NetNS) {
+  testutils.UnmountNS(testNs)
+  testNs.Close()
+}
+
+func isRouteInstalled(t *testing.T, podNs ns.NetNS, destination string) bool {
+  var isInstalled bool
+  err := podNs.Do(func(ns.NetNS) error {
+    _, dst, _ := net.ParseCIDR(destination)
+    routes, err := netlink.RouteListFiltered(netlink.FAMILY_V4, &netlink.Route{Dst: dst}, netlink.RT_FILTER_DST)
+    isInstalled = len(routes) > 0
+    return err
+  })
+  if err != nil {
+    t.Errorf("IP routes of the Pod could not be listed:%v", err)
+  }
+  return isInstalled
+}
+
+func areNamesEqual(names, expectedNames []string) bool {
+  if len(names) != len(expectedNames) {
+    return false
+  }
+  for index := range expectedNames {
+    if names[index] != expectedNames[index] {
+      return false
+    }
+  }
+  return true
+}
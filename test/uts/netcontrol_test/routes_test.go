@@ -0,0 +1,172 @@
+package netcontrol_test
+
+import (
+  "net"
+  "os"
+  "sort"
+  "strings"
+  "syscall"
+  "testing"
+  "github.com/vishvananda/netlink"
+  "github.com/containernetworking/plugins/pkg/ns"
+  "github.com/containernetworking/plugins/pkg/testutils"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  "github.com/nokia/danm/pkg/netcontrol"
+  httpstub "github.com/nokia/danm/test/stubs/http"
+  meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  "k8s.io/client-go/rest"
+  "k8s.io/client-go/tools/cache"
+)
+
+const (
+  exportTable = 100
+)
+
+var exportEpRoutesTcs = []struct {
+  tcName string
+  ep danmtypes.DanmEp
+  exportTable int
+  expectedRoutes []string
+}{
+  {"ipv4", getTestEp("routed", "", "10.0.0.5/24", ""), exportTable, []string{"10.0.0.5/32"}},
+  {"dualStack", getTestEp("routed", "", "10.0.0.5/24", "2001:db8::5/64"), exportTable, []string{"10.0.0.5/32", "2001:db8::5/128"}},
+  {"noIp", getTestEp("routed", "", "none", ""), exportTable, nil},
+  {"otherNetworkType", getTestEp("bridge", "", "10.0.0.5/24", ""), exportTable, nil},
+  {"otherHost", getTestEp("routed", "other-host", "10.0.0.5/24", ""), exportTable, nil},
+  {"exportDisabled", getTestEp("routed", "", "10.0.0.5/24", ""), 0, nil},
+}
+
+var updateEpRoutesTcs = []struct {
+  tcName string
+  oldEp danmtypes.DanmEp
+  newEp danmtypes.DanmEp
+  expectedRoutes []string
+}{
+  {"changedIp", getTestEp("routed", "", "10.0.0.5/24", ""), getTestEp("routed", "", "10.0.0.6/24", ""), []string{"10.0.0.6/32"}},
+  {"addedIpv6", getTestEp("routed", "", "10.0.0.5/24", ""), getTestEp("routed", "", "10.0.0.5/24", "2001:db8::5/64"), []string{"10.0.0.5/32", "2001:db8::5/128"}},
+  {"unchangedIp", getTestEp("routed", "", "10.0.0.5/24", ""), getTestEp("routed", "", "10.0.0.5/24", ""), []string{"10.0.0.5/32"}},
+}
+
+func TestAddDanmEp(t *testing.T) {
+  defer func() { netcontrol.RouteExportTable = netcontrol.DefaultRouteExportTable }()
+  for _, tc := range exportEpRoutesTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      hostNs := setupHostNs(t)
+      defer closeHostNs(hostNs)
+      netcontrol.RouteExportTable = tc.exportTable
+      hostNs.Do(func(ns.NetNS) error {
+        ep := tc.ep
+        netcontrol.AddDanmEp(&ep)
+        return nil
+      })
+      checkExportedRoutes(t, hostNs, tc.expectedRoutes)
+    })
+  }
+}
+
+func TestUpdateDanmEp(t *testing.T) {
+  defer func() { netcontrol.RouteExportTable = netcontrol.DefaultRouteExportTable }()
+  netcontrol.RouteExportTable = exportTable
+  for _, tc := range updateEpRoutesTcs {
+    t.Run(tc.tcName, func(t *testing.T) {
+      hostNs := setupHostNs(t)
+      defer closeHostNs(hostNs)
+      hostNs.Do(func(ns.NetNS) error {
+        oldEp, newEp := tc.oldEp, tc.newEp
+        netcontrol.AddDanmEp(&oldEp)
+        netcontrol.UpdateDanmEp(&oldEp, &newEp)
+        return nil
+      })
+      checkExportedRoutes(t, hostNs, tc.expectedRoutes)
+    })
+  }
+}
+
+func TestDeleteDanmEp(t *testing.T) {
+  defer func() { netcontrol.RouteExportTable = netcontrol.DefaultRouteExportTable }()
+  netcontrol.RouteExportTable = exportTable
+  hostNs := setupHostNs(t)
+  defer closeHostNs(hostNs)
+  deletedEp, tombstonedEp, remainingEp := getTestEp("routed", "", "10.0.0.5/24", "2001:db8::5/64"), getTestEp("routed", "", "10.0.0.6/24", ""), getTestEp("routed", "", "10.0.0.7/24", "")
+  hostNs.Do(func(ns.NetNS) error {
+    for _, ep := range []*danmtypes.DanmEp{&deletedEp, &tombstonedEp, &remainingEp} {
+      netcontrol.AddDanmEp(ep)
+    }
+    netcontrol.DeleteDanmEp(&deletedEp)
+    netcontrol.DeleteDanmEp(cache.DeletedFinalStateUnknown{Key: "default/tombstoned", Obj: &tombstonedEp})
+    //Withdrawing already withdrawn routes is not an error
+    netcontrol.DeleteDanmEp(&deletedEp)
+    return nil
+  })
+  checkExportedRoutes(t, hostNs, []string{"10.0.0.7/32"})
+}
+
+func TestStaleRoutesAreFlushed(t *testing.T) {
+  defer func() { netcontrol.RouteExportTable = netcontrol.DefaultRouteExportTable }()
+  netcontrol.RouteExportTable = exportTable
+  hostNs := setupHostNs(t)
+  defer closeHostNs(hostNs)
+  stub := httpstub.NewApiServerStub()
+  defer stub.Close()
+  var watcher *netcontrol.NetWatcher
+  err := hostNs.Do(func(ns.NetNS) error {
+    staleEp := getTestEp("routed", "", "10.0.0.5/24", "2001:db8::5/64")
+    netcontrol.AddDanmEp(&staleEp)
+    //Routes of the export table published by someone else shall survive the restart of netwatcher
+    _, foreignDst, _ := net.ParseCIDR("10.1.0.0/16")
+    err := netlink.RouteAdd(&netlink.Route{Dst: foreignDst, Table: exportTable, Type: syscall.RTN_BLACKHOLE, Protocol: syscall.RTPROT_STATIC})
+    if err != nil {
+      return err
+    }
+    watcher, err = netcontrol.NewWatcher(&rest.Config{Host: stub.Server.URL})
+    return err
+  })
+  if err != nil {
+    t.Fatalf("Netwatcher could not be started:%v", err)
+  }
+  if _, ok := watcher.Controllers[netcontrol.DanmEpKind]; !ok {
+    t.Errorf("DanmEps shall be watched when route export is enabled")
+  }
+  checkExportedRoutes(t, hostNs, []string{"10.1.0.0/16"})
+}
+
+func getTestEp(networkType, host, address, address6 string) danmtypes.DanmEp {
+  if host == "" {
+    host, _ = os.Hostname()
+  }
+  return danmtypes.DanmEp {
+    ObjectMeta: meta_v1.ObjectMeta {Name: "ep-" + strings.Replace(address, "/", "-", -1), Namespace: "default"},
+    Spec: danmtypes.DanmEpSpec{NetworkType: networkType, NetworkName: networkType, Host: host, Iface: danmtypes.DanmEpIface{Name: "eth1", Address: address, AddressIPv6: address6}},
+  }
+}
+
+func setupHostNs(t *testing.T) ns.NetNS {
+  hostNs, err := testutils.NewNS()
+  if err != nil {
+    t.Skipf("Network namespace cannot be created in this environment:%v", err)
+  }
+  return hostNs
+}
+
+func closeHostNs(hostNs ns.NetNS) {
+  testutils.UnmountNS(hostNs)
+  hostNs.Close()
+}
+
+func checkExportedRoutes(t *testing.T, hostNs ns.NetNS, expectedRoutes []string) {
+  var exportedRoutes []string
+  err := hostNs.Do(func(ns.NetNS) error {
+    routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: exportTable}, netlink.RT_FILTER_TABLE)
+    for _, route := range routes {
+      exportedRoutes = append(exportedRoutes, route.Dst.String())
+    }
+    return err
+  })
+  if err != nil {
+    t.Fatalf("Routes of the export table could not be listed:%v", err)
+  }
+  sort.Strings(exportedRoutes)
+  if strings.Join(exportedRoutes, ",") != strings.Join(expectedRoutes, ",") {
+    t.Errorf("Routes:%v of the export table do not match with the expected:%v", exportedRoutes, expectedRoutes)
+  }
+}
@@ -1,7 +1,10 @@
 package syncher_test
 
 import (
+  "context"
   "errors"
+  "net"
+  "strings"
   "testing"
   "time"
   "github.com/containernetworking/cni/pkg/types/current"
@@ -9,11 +12,12 @@ import (
 )
 
 const (
-  timeout = syncher.MaximumAllowedTime/100
+  timeout = 5 * time.Second
 )
 
 type result struct {
   cniName string
+  seqId int
   opRes error
   cniRes *current.Result
 }
@@ -25,22 +29,36 @@ var resultInterfaces = []*current.Interface {
     }
 
 var failingTestConsts = []result {
-  {"sriov", nil, &current.Result{CNIVersion: "0.3.1", Interfaces: resultInterfaces },},
-  {"flannel", errors.New("this did not go well"), nil},
-  {"ipvlan", errors.New("neither did this"), &current.Result{CNIVersion: "0.3.1", Interfaces: resultInterfaces },},
+  {"sriov", 0, nil, &current.Result{CNIVersion: "0.3.1", Interfaces: resultInterfaces },},
+  {"flannel", 1, errors.New("this did not go well"), nil},
+  {"ipvlan", 2, errors.New("neither did this"), &current.Result{CNIVersion: "0.3.1", Interfaces: resultInterfaces },},
 }
 
 var totalSuccessTestConsts = []result {
-  {"sriov", nil, &current.Result{CNIVersion: "0.3.1", Interfaces: resultInterfaces },},
-  {"flannel", nil, nil},
-  {"ipvlan", nil, &current.Result{CNIVersion: "0.4.0", Interfaces: resultInterfaces },},
+  {"sriov", 0, nil, &current.Result{CNIVersion: "0.3.1", Interfaces: resultInterfaces },},
+  {"flannel", 1, nil, nil},
+  {"ipvlan", 2, nil, &current.Result{CNIVersion: "0.4.0", Interfaces: resultInterfaces },},
+}
+
+//Results arrive in a different order than they were requested, and not all of them reference their interfaces
+var unorderedTestConsts = []result {
+  {"fastpath", 2, nil, &current.Result{CNIVersion: "0.3.1",
+    Interfaces: []*current.Interface{&current.Interface{Name: "host-veth",}, &current.Interface{Name: "fastpath",},},
+    IPs: []*current.IPConfig{&current.IPConfig{Version: "4", Address: parseCidr("10.0.2.2/24"), Interface: current.Int(1),},},},},
+  {"external", 1, nil, &current.Result{CNIVersion: "0.3.1",
+    Interfaces: []*current.Interface{&current.Interface{Name: "ext1",},},
+    IPs: []*current.IPConfig{&current.IPConfig{Version: "4", Address: parseCidr("10.0.1.2/24"),},},},},
+  {"management", 0, nil, &current.Result{CNIVersion: "0.3.1",
+    Interfaces: []*current.Interface{&current.Interface{Name: "eth0",},},
+    IPs: []*current.IPConfig{&current.IPConfig{Version: "4", Address: parseCidr("10.0.0.2/24"), Interface: current.Int(0),},
+                             &current.IPConfig{Version: "6", Address: parseCidr("fd00::2/64"), Interface: current.Int(0),},},},},
 }
 
 func setupTest(expectedNumber int, results []result) *syncher.Syncher {
   numberOfResults := expectedNumber
-  syncher := syncher.NewSyncher(numberOfResults)
+  syncher := syncher.NewSyncher(numberOfResults, timeout)
   for _, result := range results {
-    syncher.PushResult(result.cniName, result.opRes, result.cniRes)
+    syncher.PushResult(result.cniName, result.seqId, result.opRes, result.cniRes)
   }
   return syncher
 }
@@ -70,7 +88,7 @@ func TestPushResult(t *testing.T) {
 
 func TestGetAggregatedResultSuccess(t *testing.T) {
   syncher := setupTest(len(totalSuccessTestConsts)+1, totalSuccessTestConsts)
-  go addResultToSyncher(syncher,result{"ipvlan", nil, nil})
+  go addResultToSyncher(syncher,result{"ipvlan", 3, nil, nil})
   err := syncher.GetAggregatedResult()
   if err != nil {
     t.Errorf("Results could not be successfully aggregated against our expectation, because: %v", err) 
@@ -80,15 +98,13 @@ func TestGetAggregatedResultSuccess(t *testing.T) {
 func TestGetAggregatedResultFail(t *testing.T) {
   syncher := setupTest(len(totalSuccessTestConsts)+1, totalSuccessTestConsts)
   startTime := time.Now()
-  go addResultToSyncher(syncher,result{"ipvlan", errors.New("not this time"), nil})
+  go addResultToSyncher(syncher,result{"ipvlan", 3, errors.New("not this time"), nil})
   err := syncher.GetAggregatedResult()
   endTime := time.Now()
   if err == nil {
     t.Errorf("Somehow results were successfully aggregated against our expectation. Magic.") 
   }
-  timeDifference := endTime.Sub(startTime)
-  diffInSeconds := int(timeDifference.Seconds())
-  if diffInSeconds >= timeout - 1 {
+  if endTime.Sub(startTime) >= timeout {
     t.Errorf("We have failed with an unexpected timeout!") 
   }
 }
@@ -101,11 +117,66 @@ func TestGetAggregatedResultTimeout(t *testing.T) {
   if err == nil {
     t.Errorf("Somehow results were successfully aggregated against our expectation. Magic.") 
   }
-  timeDifference := endTime.Sub(startTime)
-  diffInSeconds := int(timeDifference.Seconds())
-  if diffInSeconds <= timeout - 1 {
+  if endTime.Sub(startTime) < timeout {
     t.Errorf("We have failed earlier than the timeout value!") 
   }
+  if !strings.Contains(err.Error(), timeout.String()) {
+    t.Errorf("Time-out error:%s does not contain the configured timeout:%s", err.Error(), timeout.String())
+  }
+  if syncher.Context().Err() != context.DeadlineExceeded {
+    t.Errorf("Context of the Syncher shall be cancelled with DeadlineExceeded after a time-out, but its error is:%v", syncher.Context().Err())
+  }
+}
+
+func TestGetAggregatedResultTimeoutNamesPendingOperations(t *testing.T) {
+  syncher := setupTest(len(totalSuccessTestConsts)+2, nil)
+  for _, result := range totalSuccessTestConsts {
+    syncher.StartOperation(result.cniName)
+  }
+  syncher.StartOperation("slownet")
+  syncher.StartOperation("anotherslownet")
+  for _, result := range totalSuccessTestConsts {
+    syncher.PushResult(result.cniName, result.seqId, result.opRes, result.cniRes)
+  }
+  err := syncher.GetAggregatedResult()
+  if err == nil {
+    t.Fatalf("Somehow results were successfully aggregated against our expectation. Magic.")
+  }
+  if !strings.Contains(err.Error(), "anotherslownet,slownet") {
+    t.Errorf("Time-out error:%s does not name the pending networks", err.Error())
+  }
+  for _, result := range totalSuccessTestConsts {
+    if strings.Contains(err.Error(), result.cniName) {
+      t.Errorf("Time-out error:%s names network:%s, which already finished", err.Error(), result.cniName)
+    }
+  }
+}
+
+func TestPushResultAfterTimeout(t *testing.T) {
+  syncher := setupTest(len(totalSuccessTestConsts)+1, totalSuccessTestConsts)
+  syncher.GetAggregatedResult()
+  if syncher.PushResult("latenet", 3, nil, &current.Result{CNIVersion: "0.3.1", Interfaces: resultInterfaces }) {
+    t.Errorf("Result pushed after the deadline was accepted, so nobody will clean it up")
+  }
+  if len(syncher.CniResults) != len(totalSuccessTestConsts) {
+    t.Errorf("Number of stored results:%d changed after the deadline, expected:%d", len(syncher.CniResults), len(totalSuccessTestConsts))
+  }
+}
+
+func TestWaitForOperations(t *testing.T) {
+  syncher := setupTest(1, nil)
+  syncher.StartOperation("ipvlan")
+  go func() {
+    defer syncher.OperationFinished()
+    addResultToSyncher(syncher, result{"ipvlan", 3, nil, nil})
+  }()
+  err := syncher.GetAggregatedResult()
+  if err != nil {
+    t.Errorf("Results could not be successfully aggregated against our expectation, because: %v", err)
+  }
+  if !syncher.WaitForOperations() {
+    t.Errorf("Finished operations were not waited for")
+  }
 }
 
 func TestMergeCniResults(t *testing.T) {
@@ -122,6 +193,44 @@ func TestMergeCniResults(t *testing.T) {
   }
 }
 
+func TestMergeCniResultsOrdering(t *testing.T) {
+  syncher := setupTest(len(unorderedTestConsts), unorderedTestConsts)
+  cniResult := syncher.MergeCniResults()
+  expectedIfaces := []string{"eth0", "ext1", "host-veth", "fastpath"}
+  if len(cniResult.Interfaces) != len(expectedIfaces) {
+    t.Fatalf("Number of interfaces inside the aggregated CNI result:%d does not match with the expected:%d", len(cniResult.Interfaces), len(expectedIfaces))
+  }
+  for index, ifaceName := range expectedIfaces {
+    if cniResult.Interfaces[index].Name != ifaceName {
+      t.Errorf("Interface no.:%d of the aggregated CNI result is:%s, but expected:%s", index, cniResult.Interfaces[index].Name, ifaceName)
+    }
+  }
+  expectedIps := []struct {
+    address string
+    iface int
+  } {
+    {"10.0.0.2/24", 0},
+    {"fd00::2/64", 0},
+    {"10.0.1.2/24", 1},
+    {"10.0.2.2/24", 3},
+  }
+  if len(cniResult.IPs) != len(expectedIps) {
+    t.Fatalf("Number of IPs inside the aggregated CNI result:%d does not match with the expected:%d", len(cniResult.IPs), len(expectedIps))
+  }
+  for index, expectedIp := range expectedIps {
+    ip := cniResult.IPs[index]
+    if ip.Address.String() != expectedIp.address {
+      t.Errorf("IP no.:%d of the aggregated CNI result is:%s, but expected:%s", index, ip.Address.String(), expectedIp.address)
+    }
+    if ip.Interface == nil || *ip.Interface != expectedIp.iface {
+      t.Errorf("IP:%s of the aggregated CNI result does not reference the expected interface index:%d", ip.Address.String(), expectedIp.iface)
+    }
+  }
+  if *unorderedTestConsts[0].cniRes.IPs[0].Interface != 1 {
+    t.Errorf("Merging modified the original CNI result of an operation")
+  }
+}
+
 func TestWasAnyOperationErroneous(t *testing.T) {
   emptySyncher := setupTest(0, nil)
   if emptySyncher.WasAnyOperationErroneous() {
@@ -139,6 +248,11 @@ func TestWasAnyOperationErroneous(t *testing.T) {
 
 func addResultToSyncher(syncher *syncher.Syncher, res result) {
   time.Sleep(2 * time.Second)
-  syncher.PushResult(res.cniName, res.opRes, res.cniRes)
+  syncher.PushResult(res.cniName, res.seqId, res.opRes, res.cniRes)
 }
 
+func parseCidr(cidr string) net.IPNet {
+  ip, ipNet, _ := net.ParseCIDR(cidr)
+  ipNet.IP = ip
+  return *ipNet
+}